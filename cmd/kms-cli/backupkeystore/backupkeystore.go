@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package backupkeystore
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+
+	"github.com/trustbloc/kms/cmd/kms-cli/common"
+)
+
+const (
+	keystoreFlagName  = "keystore"
+	keystoreFlagUsage = "Keystore ID. " +
+		" Alternatively, this can be set with the following environment variable: " + keystoreEnvKey
+	keystoreEnvKey = "KMS_CLI_KEYSTORE_ID"
+
+	fileFlagName  = "file"
+	fileFlagUsage = "Path to the file to write the backup to. " +
+		" Alternatively, this can be set with the following environment variable: " + fileEnvKey
+	fileEnvKey = "KMS_CLI_BACKUP_FILE"
+
+	backupFileMode = 0o600
+)
+
+// GetCmd returns the Cobra backup keystore command.
+func GetCmd() *cobra.Command {
+	backupCmd := backupCmd()
+
+	createFlags(backupCmd)
+
+	return backupCmd
+}
+
+func backupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "backup",
+		Short:        "backup keystore",
+		Long:         "backup keystore with its keys, aliases and root zcap into a signed archive",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			httpClient, err := common.NewHTTPClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			keystoreID, err := cmdutils.GetUserSetVarFromString(cmd, keystoreFlagName,
+				keystoreEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			file, err := cmdutils.GetUserSetVarFromString(cmd, fileFlagName,
+				fileEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			backupKeystorePath, err := common.GetBackupKeystorePath(cmd, keystoreID)
+			if err != nil {
+				return err
+			}
+
+			responseBytes, err := common.SendRequest(httpClient, nil, common.NewAuthTokenHeader(cmd), http.MethodGet,
+				backupKeystorePath)
+			if err != nil {
+				return err
+			}
+
+			if err = os.WriteFile(file, responseBytes, backupFileMode); err != nil {
+				return fmt.Errorf("failed to write backup: %w", err)
+			}
+
+			fmt.Printf("backup=%s", file)
+
+			return nil
+		},
+	}
+}
+
+func createFlags(startCmd *cobra.Command) {
+	common.AddCommonFlags(startCmd)
+
+	startCmd.Flags().StringP(keystoreFlagName, "", "", keystoreFlagUsage)
+	startCmd.Flags().StringP(fileFlagName, "", "", fileFlagUsage)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package backupkeystore //nolint:testpackage
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing keystore arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither keystore (command line flag) nor KMS_CLI_KEYSTORE_ID (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing file arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		startCmd.SetArgs([]string{
+			"--keystore", "some_id",
+		})
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither file (command line flag) nor KMS_CLI_BACKUP_FILE (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		startCmd.SetArgs([]string{
+			"--keystore", "some_id",
+			"--file", "backup.json",
+		})
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor KMS_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+}
+
+func TestBackupKeystore(t *testing.T) {
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/keystores/some_id/backup", r.URL.Path)
+
+		_, err := fmt.Fprint(w, `{"payload":"e30=","signer":"did:key:z6Mk","signature":"c2ln"}`)
+		require.NoError(t, err)
+	}))
+
+	file := filepath.Join(t.TempDir(), "backup.json")
+
+	t.Run("test failed to backup", func(t *testing.T) {
+		os.Clearenv()
+		cmd := GetCmd()
+
+		cmd.SetArgs([]string{
+			"--url", "https://localhost:8080",
+			"--keystore", "some_id",
+			"--file", file,
+		})
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send request")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cmd := GetCmd()
+
+		cmd.SetArgs([]string{
+			"--url", serv.URL,
+			"--keystore", "some_id",
+			"--file", file,
+		})
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		b, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"payload":"e30=","signer":"did:key:z6Mk","signature":"c2ln"}`, string(b))
+	})
+}
@@ -202,6 +202,36 @@ func GetCreateKeyPath(cmd *cobra.Command, keystoreID string) (string, error) {
 	return keystoreURL + "/" + keystoreID + "/" + keyVarName, nil
 }
 
+// GetExportKeyPath returns path for export key endpoint.
+func GetExportKeyPath(cmd *cobra.Command, keystoreID, keyID string) (string, error) {
+	keyPath, err := GetCreateKeyPath(cmd, keystoreID)
+	if err != nil {
+		return "", err
+	}
+
+	return keyPath + "/" + keyID + "/export", nil
+}
+
+// GetBackupKeystorePath returns path for backup keystore endpoint.
+func GetBackupKeystorePath(cmd *cobra.Command, keystoreID string) (string, error) {
+	keystoreURL, err := GetCreateKeystorePath(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return keystoreURL + "/" + keystoreID + "/backup", nil
+}
+
+// GetRestoreKeystorePath returns path for restore keystore endpoint.
+func GetRestoreKeystorePath(cmd *cobra.Command) (string, error) {
+	keystoreURL, err := GetCreateKeystorePath(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return keystoreURL + "/restore", nil
+}
+
 // AddCommonFlags adds common flags to the given command.
 func AddCommonFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP(TLSSystemCertPoolFlagName, "", "", TLSSystemCertPoolFlagUsage)
@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package exportkey
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+
+	"github.com/trustbloc/kms/cmd/kms-cli/common"
+)
+
+const (
+	keystoreFlagName  = "keystore"
+	keystoreFlagUsage = "Keystore ID. " +
+		" Alternatively, this can be set with the following environment variable: " + keystoreEnvKey
+	keystoreEnvKey = "KMS_CLI_KEYSTORE_ID"
+
+	keyFlagName  = "key"
+	keyFlagUsage = "Key ID. " +
+		" Alternatively, this can be set with the following environment variable: " + keyEnvKey
+	keyEnvKey = "KMS_CLI_KEY_ID"
+
+	formatFlagName  = "format"
+	formatFlagUsage = "Format of the exported public key: raw, jwk, pem, multibase or didkey. Defaults to raw." +
+		" Alternatively, this can be set with the following environment variable: " + formatEnvKey
+	formatEnvKey = "KMS_CLI_EXPORT_FORMAT"
+)
+
+type exportKeyResp struct {
+	PublicKey []byte          `json:"public_key"`
+	KeyType   string          `json:"key_type"`
+	JWK       json.RawMessage `json:"jwk,omitempty"`
+	PEM       string          `json:"pem,omitempty"`
+	Multibase string          `json:"multibase,omitempty"`
+	DIDKey    string          `json:"did_key,omitempty"`
+}
+
+// GetCmd returns the Cobra export key command.
+func GetCmd() *cobra.Command {
+	exportCmd := exportCmd()
+
+	createFlags(exportCmd)
+
+	return exportCmd
+}
+
+func exportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "export",
+		Short:        "export public key",
+		Long:         "export public key in raw, jwk, pem, multibase or didkey format",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			httpClient, err := common.NewHTTPClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			keystoreID, err := cmdutils.GetUserSetVarFromString(cmd, keystoreFlagName,
+				keystoreEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			keyID, err := cmdutils.GetUserSetVarFromString(cmd, keyFlagName,
+				keyEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			format := cmdutils.GetUserSetOptionalVarFromString(cmd, formatFlagName, formatEnvKey)
+
+			exportKeyPath, err := common.GetExportKeyPath(cmd, keystoreID, keyID)
+			if err != nil {
+				return err
+			}
+
+			if format != "" {
+				exportKeyPath += "?format=" + url.QueryEscape(format)
+			}
+
+			responseBytes, err := common.SendRequest(httpClient, nil, common.NewAuthTokenHeader(cmd), http.MethodGet,
+				exportKeyPath)
+			if err != nil {
+				return err
+			}
+
+			response := &exportKeyResp{}
+
+			if err = json.Unmarshal(responseBytes, response); err != nil {
+				return fmt.Errorf("failed to unmarshal response: %w", err)
+			}
+
+			fmt.Println(formatResponse(response))
+
+			return nil
+		},
+	}
+}
+
+func formatResponse(resp *exportKeyResp) string {
+	switch {
+	case len(resp.JWK) > 0:
+		return string(resp.JWK)
+	case resp.PEM != "":
+		return resp.PEM
+	case resp.Multibase != "":
+		return resp.Multibase
+	case resp.DIDKey != "":
+		return resp.DIDKey
+	default:
+		return base64.StdEncoding.EncodeToString(resp.PublicKey)
+	}
+}
+
+func createFlags(startCmd *cobra.Command) {
+	common.AddCommonFlags(startCmd)
+
+	startCmd.Flags().StringP(keystoreFlagName, "", "", keystoreFlagUsage)
+	startCmd.Flags().StringP(keyFlagName, "", "", keyFlagUsage)
+	startCmd.Flags().StringP(formatFlagName, "", "", formatFlagUsage)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package exportkey //nolint:testpackage
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing keystore arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither keystore (command line flag) nor KMS_CLI_KEYSTORE_ID (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing key arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		startCmd.SetArgs([]string{
+			"--keystore", "some_id",
+		})
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither key (command line flag) nor KMS_CLI_KEY_ID (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		startCmd.SetArgs([]string{
+			"--keystore", "some_id",
+			"--key", "key_id",
+		})
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor KMS_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+}
+
+func TestExportKey(t *testing.T) {
+	var format string
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/keystores/some_id/keys/key_id/export", r.URL.Path)
+
+		format = r.URL.Query().Get("format")
+
+		_, err := fmt.Fprint(w, `{"public_key":"cHVibGljIGtleQ==","key_type":"ED25519","did_key":"did:key:z6Mk"}`)
+		require.NoError(t, err)
+	}))
+
+	t.Run("test failed to export", func(t *testing.T) {
+		os.Clearenv()
+		cmd := GetCmd()
+
+		cmd.SetArgs([]string{
+			"--url", "https://localhost:8080",
+			"--keystore", "some_id",
+			"--key", "key_id",
+		})
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send request")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cmd := GetCmd()
+
+		cmd.SetArgs([]string{
+			"--url", serv.URL,
+			"--keystore", "some_id",
+			"--key", "key_id",
+			"--format", "didkey",
+		})
+
+		err := cmd.Execute()
+
+		require.NoError(t, err)
+		require.Equal(t, "didkey", format)
+	})
+}
+
+func TestFormatResponse(t *testing.T) {
+	require.Equal(t, "cHVibGljIGtleQ==", formatResponse(&exportKeyResp{PublicKey: []byte("public key")}))
+	require.Equal(t, `{"kty":"OKP"}`, formatResponse(&exportKeyResp{JWK: []byte(`{"kty":"OKP"}`)}))
+	require.Equal(t, "pem", formatResponse(&exportKeyResp{PEM: "pem"}))
+	require.Equal(t, "z6Mk", formatResponse(&exportKeyResp{Multibase: "z6Mk"}))
+	require.Equal(t, "did:key:z6Mk", formatResponse(&exportKeyResp{DIDKey: "did:key:z6Mk"}))
+}
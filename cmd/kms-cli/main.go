@@ -9,8 +9,11 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/trustbloc/edge-core/pkg/log"
 
+	"github.com/trustbloc/kms/cmd/kms-cli/backupkeystore"
 	"github.com/trustbloc/kms/cmd/kms-cli/createkey"
 	"github.com/trustbloc/kms/cmd/kms-cli/createkeystore"
+	"github.com/trustbloc/kms/cmd/kms-cli/exportkey"
+	"github.com/trustbloc/kms/cmd/kms-cli/restorekeystore"
 )
 
 var logger = log.New("kms-cli")
@@ -30,6 +33,8 @@ func main() {
 		},
 	}
 	keystore.AddCommand(createkeystore.GetCmd())
+	keystore.AddCommand(backupkeystore.GetCmd())
+	keystore.AddCommand(restorekeystore.GetCmd())
 
 	key := &cobra.Command{
 		Use: "key",
@@ -39,6 +44,7 @@ func main() {
 	}
 
 	key.AddCommand(createkey.GetCmd())
+	key.AddCommand(exportkey.GetCmd())
 
 	rootCmd.AddCommand(keystore)
 	rootCmd.AddCommand(key)
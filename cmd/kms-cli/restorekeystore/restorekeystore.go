@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package restorekeystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+
+	"github.com/trustbloc/kms/cmd/kms-cli/common"
+)
+
+const (
+	fileFlagName  = "file"
+	fileFlagUsage = "Path to the backup file created by keystore backup command. " +
+		" Alternatively, this can be set with the following environment variable: " + fileEnvKey
+	fileEnvKey = "KMS_CLI_BACKUP_FILE"
+)
+
+type restoreKeystoreReq struct {
+	Backup json.RawMessage `json:"backup"`
+}
+
+type restoreKeystoreResp struct {
+	KeyStoreURL string `json:"key_store_url"`
+	Capability  []byte `json:"capability"`
+}
+
+// GetCmd returns the Cobra restore keystore command.
+func GetCmd() *cobra.Command {
+	restoreCmd := restoreCmd()
+
+	createFlags(restoreCmd)
+
+	return restoreCmd
+}
+
+func restoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "restore",
+		Short:        "restore keystore",
+		Long:         "restore keystore from the backup file",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			httpClient, err := common.NewHTTPClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			file, err := cmdutils.GetUserSetVarFromString(cmd, fileFlagName,
+				fileEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			backup, err := os.ReadFile(file) //nolint:gosec
+			if err != nil {
+				return fmt.Errorf("failed to read backup: %w", err)
+			}
+
+			if !json.Valid(backup) {
+				return fmt.Errorf("invalid backup file %s", file)
+			}
+
+			restoreKeystorePath, err := common.GetRestoreKeystorePath(cmd)
+			if err != nil {
+				return err
+			}
+
+			response := &restoreKeystoreResp{}
+
+			err = common.SendHTTPRequest(httpClient, &restoreKeystoreReq{Backup: backup},
+				common.NewAuthTokenHeader(cmd), http.MethodPost, restoreKeystorePath, response)
+			if err != nil {
+				return err
+			}
+
+			parts := strings.Split(response.KeyStoreURL, "/")
+			fmt.Printf("keystore=%s", parts[len(parts)-1])
+
+			return nil
+		},
+	}
+}
+
+func createFlags(startCmd *cobra.Command) {
+	common.AddCommonFlags(startCmd)
+
+	startCmd.Flags().StringP(fileFlagName, "", "", fileFlagUsage)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package restorekeystore //nolint:testpackage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing file arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither file (command line flag) nor KMS_CLI_BACKUP_FILE (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test backup file not found", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		startCmd.SetArgs([]string{
+			"--file", filepath.Join(t.TempDir(), "backup.json"),
+		})
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read backup")
+	})
+
+	t.Run("test invalid backup file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "backup.json")
+		require.NoError(t, os.WriteFile(file, []byte("not json"), 0o600))
+
+		startCmd := GetCmd()
+
+		startCmd.SetArgs([]string{
+			"--file", file,
+		})
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid backup file")
+	})
+}
+
+func TestRestoreKeystore(t *testing.T) {
+	var req restoreKeystoreReq
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/keystores/restore", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		_, err := fmt.Fprint(w, `{"key_store_url":"https://kms.example.com/v1/keystores/some_id"}`)
+		require.NoError(t, err)
+	}))
+
+	file := filepath.Join(t.TempDir(), "backup.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"payload":"e30=","signer":"did:key:z6Mk","signature":"c2ln"}`),
+		0o600))
+
+	t.Run("test failed to restore", func(t *testing.T) {
+		os.Clearenv()
+		cmd := GetCmd()
+
+		cmd.SetArgs([]string{
+			"--url", "https://localhost:8080",
+			"--file", file,
+		})
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send request")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cmd := GetCmd()
+
+		cmd.SetArgs([]string{
+			"--url", serv.URL,
+			"--file", file,
+		})
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"payload":"e30=","signer":"did:key:z6Mk","signature":"c2ln"}`, string(req.Backup))
+	})
+}
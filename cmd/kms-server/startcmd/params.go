@@ -102,11 +102,50 @@ const (
 	kmsCacheTTLFlagUsage = "An optional value cache TTL (time to live) for keys in server kms. Defaults to 10m if " +
 		"caching is enabled. If set to 0, keys are never cached. " + commonEnvVarUsageText + kmsCacheTTLEnvKey
 
+	keyManagerCacheTTLEnvKey    = "KMS_KEY_MANAGER_CACHE_TTL"
+	keyManagerCacheTTLFlagName  = "key-manager-cache-ttl"
+	keyManagerCacheTTLFlagUsage = "An optional value for cache TTL (time to live) of resolved user key managers. " +
+		"Defaults to 10m if caching is enabled. If set to 0, key managers are never cached. " + commonEnvVarUsageText +
+		keyManagerCacheTTLEnvKey
+
+	keyManagerCacheSizeEnvKey    = "KMS_KEY_MANAGER_CACHE_SIZE"
+	keyManagerCacheSizeFlagName  = "key-manager-cache-size"
+	keyManagerCacheSizeFlagUsage = "An optional maximum number of resolved user key managers kept in the cache. " +
+		"Defaults to 1000. " + commonEnvVarUsageText + keyManagerCacheSizeEnvKey
+
 	shamirSecretCacheTTLEnvKey    = "KMS_SHAMIR_SECRET_CACHE_TTL"
 	shamirSecretCacheTTLFlagName  = "shamir-secret-cache-ttl"
 	shamirSecretCacheTTLFlagUsage = "An optional value cache TTL (time to live) for keys in server kms. Defaults to 10m if " +
 		"caching is enabled. If set to 0, keys are never cached. " + commonEnvVarUsageText + shamirSecretCacheTTLEnvKey
 
+	keyDeletionSweepIntervalEnvKey    = "KMS_KEY_DELETION_SWEEP_INTERVAL"
+	keyDeletionSweepIntervalFlagName  = "key-deletion-sweep-interval"
+	keyDeletionSweepIntervalFlagUsage = "An optional interval for purging keys whose pending deletion window has " +
+		"ended. Defaults to 1h. If set to 0, keys are never purged. " + commonEnvVarUsageText +
+		keyDeletionSweepIntervalEnvKey
+
+	keyRotationCheckIntervalEnvKey    = "KMS_KEY_ROTATION_CHECK_INTERVAL"
+	keyRotationCheckIntervalFlagName  = "key-rotation-check-interval"
+	keyRotationCheckIntervalFlagUsage = "An optional interval for rotating keys whose rotation policy is due. " +
+		"Defaults to 1h. If set to 0, keys are never rotated automatically. " + commonEnvVarUsageText +
+		keyRotationCheckIntervalEnvKey
+
+	keyExpiryGracePeriodEnvKey    = "KMS_KEY_EXPIRY_GRACE_PERIOD"
+	keyExpiryGracePeriodFlagName  = "key-expiry-grace-period"
+	keyExpiryGracePeriodFlagUsage = "An optional period after key expiry during which the key can still be used " +
+		"to verify, decrypt and unwrap. Defaults to 0 (no grace period). " + commonEnvVarUsageText +
+		keyExpiryGracePeriodEnvKey
+
+	jwtLifetimeEnvKey    = "KMS_JWT_LIFETIME"
+	jwtLifetimeFlagName  = "jwt-lifetime"
+	jwtLifetimeFlagUsage = "An optional lifetime of issued JWTs if not set in the request. Defaults to 1h. " +
+		commonEnvVarUsageText + jwtLifetimeEnvKey
+
+	jwtMaxLifetimeEnvKey    = "KMS_JWT_MAX_LIFETIME"
+	jwtMaxLifetimeFlagName  = "jwt-max-lifetime"
+	jwtMaxLifetimeFlagUsage = "An optional max lifetime of issued JWTs. Defaults to 24h. " + commonEnvVarUsageText +
+		jwtMaxLifetimeEnvKey
+
 	disableAuthEnvKey    = "KMS_AUTH_DISABLE"
 	disableAuthFlagName  = "disable-auth"
 	disableAuthFlagUsage = "Disables authorization. Possible values: [true] [false]. Defaults to false. " +
@@ -161,6 +200,23 @@ const (
 	gnapSigningKeyPathFlagName  = "gnap-signing-key"
 	gnapSigningKeyPathFlagUsage = "The path to the private key to use when signing GNAP introspection requests. " +
 		commonEnvVarUsageText + gnapSigningKeyPathEnvKey
+
+	adminTokenEnvKey    = "KMS_ADMIN_TOKEN" //nolint:gosec // not hard-coded credentials
+	adminTokenFlagName  = "admin-token"
+	adminTokenFlagUsage = "An optional token for administrative endpoints (key store restore), sent in Admin-Token " +
+		"header. If not set, administrative endpoints are disabled. " + commonEnvVarUsageText + adminTokenEnvKey
+
+	backupSigningKeyPathEnvKey    = "KMS_BACKUP_SIGNING_KEY"
+	backupSigningKeyPathFlagName  = "backup-signing-key"
+	backupSigningKeyPathFlagUsage = "An optional path to the PEM-encoded PKCS#8 Ed25519 private key to sign key " +
+		"store backups with. If not set, key store backup is disabled. " + commonEnvVarUsageText +
+		backupSigningKeyPathEnvKey
+
+	backupTrustedSignersEnvKey    = "KMS_BACKUP_TRUSTED_SIGNERS"
+	backupTrustedSignersFlagName  = "backup-trusted-signers"
+	backupTrustedSignersFlagUsage = "An optional comma-separated list of did:key of Ed25519 keys whose key store " +
+		"backups can be restored. Backups signed with the backup signing key of the server are always trusted. " +
+		commonEnvVarUsageText + backupTrustedSignersEnvKey
 )
 
 const (
@@ -169,27 +225,37 @@ const (
 )
 
 type serverParameters struct {
-	host                 string
-	metricsHost          string
-	baseURL              string
-	tlsParams            *tlsParameters
-	databaseType         string
-	databaseURL          string
-	databasePrefix       string
-	databaseTimeout      time.Duration
-	didDomain            string
-	authServerURL        string
-	authServerToken      string
-	keyStoreCacheTTL     time.Duration
-	kmsCacheTTL          time.Duration
-	shamirSecretCacheTTL time.Duration
-	enableCache          bool
-	disableAuth          bool
-	disableHTTPSIG       bool
-	enableCORS           bool
-	logLevel             string
-	secretLockParams     *secretLockParameters
-	gnapSigningKeyPath   string
+	host                     string
+	metricsHost              string
+	baseURL                  string
+	tlsParams                *tlsParameters
+	databaseType             string
+	databaseURL              string
+	databasePrefix           string
+	databaseTimeout          time.Duration
+	didDomain                string
+	authServerURL            string
+	authServerToken          string
+	keyStoreCacheTTL         time.Duration
+	kmsCacheTTL              time.Duration
+	keyManagerCacheTTL       time.Duration
+	keyManagerCacheSize      int64
+	shamirSecretCacheTTL     time.Duration
+	keyDeletionSweepInterval time.Duration
+	keyRotationCheckInterval time.Duration
+	keyExpiryGracePeriod     time.Duration
+	jwtLifetime              time.Duration
+	jwtMaxLifetime           time.Duration
+	enableCache              bool
+	disableAuth              bool
+	disableHTTPSIG           bool
+	enableCORS               bool
+	logLevel                 string
+	secretLockParams         *secretLockParameters
+	gnapSigningKeyPath       string
+	adminToken               string
+	backupSigningKeyPath     string
+	backupTrustedSigners     []string
 }
 
 type tlsParameters struct {
@@ -224,7 +290,16 @@ func getParameters(cmd *cobra.Command) (*serverParameters, error) { //nolint:fun
 	authServerToken := getUserSetVarOptional(cmd, authServerTokenFlagName, authServerTokenEnvKey)
 	keyStoreCacheTTLStr := getUserSetVarOptional(cmd, keyStoreCacheTTLFlagName, keyStoreCacheTTLEnvKey)
 	kmsCacheTTLStr := getUserSetVarOptional(cmd, kmsCacheTTLFlagName, kmsCacheTTLEnvKey)
+	keyManagerCacheTTLStr := getUserSetVarOptional(cmd, keyManagerCacheTTLFlagName, keyManagerCacheTTLEnvKey)
+	keyManagerCacheSizeStr := getUserSetVarOptional(cmd, keyManagerCacheSizeFlagName, keyManagerCacheSizeEnvKey)
 	shamirSecretCacheTTLStr := getUserSetVarOptional(cmd, shamirSecretCacheTTLFlagName, shamirSecretCacheTTLEnvKey)
+	keyDeletionSweepIntervalStr := getUserSetVarOptional(cmd, keyDeletionSweepIntervalFlagName,
+		keyDeletionSweepIntervalEnvKey)
+	keyRotationCheckIntervalStr := getUserSetVarOptional(cmd, keyRotationCheckIntervalFlagName,
+		keyRotationCheckIntervalEnvKey)
+	keyExpiryGracePeriodStr := getUserSetVarOptional(cmd, keyExpiryGracePeriodFlagName, keyExpiryGracePeriodEnvKey)
+	jwtLifetimeStr := getUserSetVarOptional(cmd, jwtLifetimeFlagName, jwtLifetimeEnvKey)
+	jwtMaxLifetimeStr := getUserSetVarOptional(cmd, jwtMaxLifetimeFlagName, jwtMaxLifetimeEnvKey)
 	enableCacheStr := getUserSetVarOptional(cmd, enableCacheFlagName, enableCacheEnvKey)
 	disableAuthStr := getUserSetVarOptional(cmd, disableAuthFlagName, disableAuthEnvKey)
 	disableHTTPSIGStr := getUserSetVarOptional(cmd, disableHTTPSIGFlagName, disableHTTPSIGEnvKey)
@@ -258,6 +333,26 @@ func getParameters(cmd *cobra.Command) (*serverParameters, error) { //nolint:fun
 		}
 	}
 
+	var keyManagerCacheTTL time.Duration
+	if keyManagerCacheTTLStr != "" {
+		keyManagerCacheTTL, err = time.ParseDuration(keyManagerCacheTTLStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse key manager cache ttl: %w", err)
+		}
+	}
+
+	var keyManagerCacheSize int64
+	if keyManagerCacheSizeStr != "" {
+		keyManagerCacheSize, err = strconv.ParseInt(keyManagerCacheSizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse key manager cache size: %w", err)
+		}
+
+		if keyManagerCacheSize <= 0 {
+			return nil, fmt.Errorf("key manager cache size must be positive: %d", keyManagerCacheSize)
+		}
+	}
+
 	var shamirSecretCacheTTL time.Duration
 	if shamirSecretCacheTTLStr != "" {
 		shamirSecretCacheTTL, err = time.ParseDuration(shamirSecretCacheTTLStr)
@@ -266,6 +361,46 @@ func getParameters(cmd *cobra.Command) (*serverParameters, error) { //nolint:fun
 		}
 	}
 
+	var keyDeletionSweepInterval time.Duration
+	if keyDeletionSweepIntervalStr != "" {
+		keyDeletionSweepInterval, err = time.ParseDuration(keyDeletionSweepIntervalStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse key deletion sweep interval: %w", err)
+		}
+	}
+
+	var keyRotationCheckInterval time.Duration
+	if keyRotationCheckIntervalStr != "" {
+		keyRotationCheckInterval, err = time.ParseDuration(keyRotationCheckIntervalStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse key rotation check interval: %w", err)
+		}
+	}
+
+	var keyExpiryGracePeriod time.Duration
+	if keyExpiryGracePeriodStr != "" {
+		keyExpiryGracePeriod, err = time.ParseDuration(keyExpiryGracePeriodStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse key expiry grace period: %w", err)
+		}
+	}
+
+	var jwtLifetime time.Duration
+	if jwtLifetimeStr != "" {
+		jwtLifetime, err = time.ParseDuration(jwtLifetimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwt lifetime: %w", err)
+		}
+	}
+
+	var jwtMaxLifetime time.Duration
+	if jwtMaxLifetimeStr != "" {
+		jwtMaxLifetime, err = time.ParseDuration(jwtMaxLifetimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwt max lifetime: %w", err)
+		}
+	}
+
 	enableCache, err := strconv.ParseBool(enableCacheStr)
 	if err != nil {
 		return nil, fmt.Errorf("parse enableCache: %w", err)
@@ -296,28 +431,47 @@ func getParameters(cmd *cobra.Command) (*serverParameters, error) { //nolint:fun
 		return nil, fmt.Errorf("get GNAP signing key path: %w", err)
 	}
 
+	adminToken := getUserSetVarOptional(cmd, adminTokenFlagName, adminTokenEnvKey)
+	backupSigningKeyPath := getUserSetVarOptional(cmd, backupSigningKeyPathFlagName, backupSigningKeyPathEnvKey)
+	backupTrustedSignersStr := getUserSetVarOptional(cmd, backupTrustedSignersFlagName, backupTrustedSignersEnvKey)
+
+	var backupTrustedSigners []string
+	if backupTrustedSignersStr != "" {
+		backupTrustedSigners = strings.Split(backupTrustedSignersStr, ",")
+	}
+
 	return &serverParameters{
-		host:                 host,
-		metricsHost:          metricsHost,
-		baseURL:              baseURL,
-		tlsParams:            tlsParams,
-		databaseType:         databaseType,
-		databaseURL:          databaseURL,
-		databasePrefix:       databasePrefix,
-		databaseTimeout:      databaseTimeout,
-		didDomain:            didDomain,
-		authServerURL:        authServerURL,
-		authServerToken:      authServerToken,
-		keyStoreCacheTTL:     keyStoreCacheTTL,
-		kmsCacheTTL:          kmsCacheTTL,
-		shamirSecretCacheTTL: shamirSecretCacheTTL,
-		enableCache:          enableCache,
-		disableAuth:          disableAuth,
-		disableHTTPSIG:       disableHTTPSIG,
-		enableCORS:           enableCORS,
-		logLevel:             logLevel,
-		secretLockParams:     secretLockParams,
-		gnapSigningKeyPath:   gnapSigningKeyPath,
+		host:                     host,
+		metricsHost:              metricsHost,
+		baseURL:                  baseURL,
+		tlsParams:                tlsParams,
+		databaseType:             databaseType,
+		databaseURL:              databaseURL,
+		databasePrefix:           databasePrefix,
+		databaseTimeout:          databaseTimeout,
+		didDomain:                didDomain,
+		authServerURL:            authServerURL,
+		authServerToken:          authServerToken,
+		keyStoreCacheTTL:         keyStoreCacheTTL,
+		kmsCacheTTL:              kmsCacheTTL,
+		keyManagerCacheTTL:       keyManagerCacheTTL,
+		keyManagerCacheSize:      keyManagerCacheSize,
+		shamirSecretCacheTTL:     shamirSecretCacheTTL,
+		keyDeletionSweepInterval: keyDeletionSweepInterval,
+		keyRotationCheckInterval: keyRotationCheckInterval,
+		keyExpiryGracePeriod:     keyExpiryGracePeriod,
+		jwtLifetime:              jwtLifetime,
+		jwtMaxLifetime:           jwtMaxLifetime,
+		enableCache:              enableCache,
+		disableAuth:              disableAuth,
+		disableHTTPSIG:           disableHTTPSIG,
+		enableCORS:               enableCORS,
+		logLevel:                 logLevel,
+		secretLockParams:         secretLockParams,
+		gnapSigningKeyPath:       gnapSigningKeyPath,
+		adminToken:               adminToken,
+		backupSigningKeyPath:     backupSigningKeyPath,
+		backupTrustedSigners:     backupTrustedSigners,
 	}, nil
 }
 
@@ -418,7 +572,14 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().String(authServerTokenFlagName, "", authServerTokenFlagUsage)
 	startCmd.Flags().String(keyStoreCacheTTLFlagName, "10m", keyStoreCacheTTLFlagUsage)
 	startCmd.Flags().String(kmsCacheTTLFlagName, "10m", kmsCacheTTLFlagUsage)
+	startCmd.Flags().String(keyManagerCacheTTLFlagName, "10m", keyManagerCacheTTLFlagUsage)
+	startCmd.Flags().String(keyManagerCacheSizeFlagName, "1000", keyManagerCacheSizeFlagUsage)
 	startCmd.Flags().String(shamirSecretCacheTTLFlagName, "10m", shamirSecretCacheTTLFlagUsage)
+	startCmd.Flags().String(keyDeletionSweepIntervalFlagName, "1h", keyDeletionSweepIntervalFlagUsage)
+	startCmd.Flags().String(keyRotationCheckIntervalFlagName, "1h", keyRotationCheckIntervalFlagUsage)
+	startCmd.Flags().String(keyExpiryGracePeriodFlagName, "0s", keyExpiryGracePeriodFlagUsage)
+	startCmd.Flags().String(jwtLifetimeFlagName, "1h", jwtLifetimeFlagUsage)
+	startCmd.Flags().String(jwtMaxLifetimeFlagName, "24h", jwtMaxLifetimeFlagUsage)
 	startCmd.Flags().String(enableCacheFlagName, "true", enableCacheFlagUsage)
 	startCmd.Flags().String(disableAuthFlagName, "false", disableAuthFlagUsage)
 	startCmd.Flags().String(disableHTTPSIGFlagName, "false", disableHTTPSIGFlagUsage)
@@ -431,4 +592,7 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().String(secretLockAWSSecretKeyFlagName, "", secretLockAWSSecretKeyFlagUsage)
 	startCmd.Flags().String(secretLockAWSEndpointFlagName, "", secretLockAWSEndpointFlagUsage)
 	startCmd.Flags().String(gnapSigningKeyPathFlagName, "", gnapSigningKeyPathFlagUsage)
+	startCmd.Flags().String(adminTokenFlagName, "", adminTokenFlagUsage)
+	startCmd.Flags().String(backupSigningKeyPathFlagName, "", backupSigningKeyPathFlagUsage)
+	startCmd.Flags().String(backupTrustedSignersFlagName, "", backupTrustedSignersFlagUsage)
 }
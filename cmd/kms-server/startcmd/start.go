@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package startcmd
 
 import (
+	"context"
 	"crypto"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
@@ -17,6 +19,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -50,6 +53,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
+	"github.com/rs/xid"
 	"github.com/spf13/cobra"
 	"github.com/square/go-jose/v3"
 	"github.com/trustbloc/auth/component/gnap/rs"
@@ -60,11 +64,13 @@ import (
 	"github.com/trustbloc/kms/pkg/controller/command"
 	"github.com/trustbloc/kms/pkg/controller/mw"
 	"github.com/trustbloc/kms/pkg/controller/mw/authmw"
+	"github.com/trustbloc/kms/pkg/controller/mw/authmw/adminmw"
 	"github.com/trustbloc/kms/pkg/controller/mw/authmw/gnapmw"
 	"github.com/trustbloc/kms/pkg/controller/mw/authmw/oauthmw"
 	"github.com/trustbloc/kms/pkg/controller/mw/authmw/zcapmw"
 	"github.com/trustbloc/kms/pkg/controller/rest"
 	kmscache "github.com/trustbloc/kms/pkg/kms/cache"
+	"github.com/trustbloc/kms/pkg/lease"
 	"github.com/trustbloc/kms/pkg/metrics"
 	awssecretlock "github.com/trustbloc/kms/pkg/secretlock/aws"
 	shamirprovider "github.com/trustbloc/kms/pkg/shamir"
@@ -76,6 +82,9 @@ import (
 
 const (
 	keystoreLocalPrimaryKeyURI = "local-lock://keystorekms"
+	keyDeletionJobName         = "keyDeletion"
+	keyRotationJobName         = "keyRotation"
+	jobLeaseIntervals          = 2 // job's lease lasts for two intervals, so the holder renews it before it expires
 )
 
 var logger = log.New("kms-server")
@@ -156,6 +165,7 @@ func startServer(srv server, params *serverParameters) error { //nolint:funlen
 		cacheProvider       *cache.Provider
 		kmsCacheProvider    *kmscache.Provider
 		shamirCacheProvider *shamircache.Provider
+		keyManagerCache     *ristretto.Cache
 	)
 
 	if params.enableCache {
@@ -173,6 +183,15 @@ func startServer(srv server, params *serverParameters) error { //nolint:funlen
 		kmsCacheProvider = &kmscache.Provider{Cache: c}
 		shamirCacheProvider = &shamircache.Provider{Cache: c}
 
+		// key managers are kept in a separate cache bounded by the number of items
+		keyManagerCache, err = ristretto.NewCache(&ristretto.Config{
+			NumCounters: params.keyManagerCacheSize * 10, //nolint:gomnd // 10x of max items as ristretto recommends
+			MaxCost:     params.keyManagerCacheSize,
+			BufferItems: 64,
+		})
+		if err != nil {
+			return fmt.Errorf("create key manager cache: %w", err)
+		}
 	} else {
 		storageProvider = store
 	}
@@ -245,18 +264,66 @@ func startServer(srv server, params *serverParameters) error { //nolint:funlen
 		EDVRecipientKeyType:     kms.NISTP256ECDHKW,
 		EDVMACKeyType:           kms.HMACSHA256Tag256,
 		KeyStoreCacheTTL:        params.keyStoreCacheTTL,
+		KeyExpiryGracePeriod:    params.keyExpiryGracePeriod,
+		JWTLifetime:             params.jwtLifetime,
+		JWTMaxLifetime:          params.jwtMaxLifetime,
 		MetricsProvider:         metrics.Get(),
 	}
 
+	if params.backupSigningKeyPath != "" {
+		config.BackupSigningKey, err = readBackupSigningKey(params.backupSigningKeyPath)
+		if err != nil {
+			return fmt.Errorf("read backup signing key: %w", err)
+		}
+	}
+
+	config.BackupTrustedSigners = params.backupTrustedSigners
+
 	if cacheProvider != nil {
 		config.CacheProvider = &cacheProviderWithTTL{Provider: cacheProvider}
 	}
 
+	if keyManagerCache != nil {
+		config.KeyManagerCache = keyManagerCache
+		config.KeyManagerCacheTTL = params.keyManagerCacheTTL
+	}
+
 	cmd, err := command.New(config)
 	if err != nil {
 		return fmt.Errorf("create command: %w", err)
 	}
 
+	leases, err := lease.New(store, xid.New().String())
+	if err != nil {
+		return fmt.Errorf("create lease manager: %w", err)
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+
+	var jobs sync.WaitGroup
+
+	// periodic jobs are stopped when the server stops, running jobs are awaited
+	defer func() {
+		stopJobs()
+		jobs.Wait()
+	}()
+
+	if params.keyDeletionSweepInterval > 0 {
+		startJob(jobsCtx, &jobs, leases, keyDeletionJobName, params.keyDeletionSweepInterval, func() {
+			purgeExpiredKeys(cmd)
+		})
+	}
+
+	if params.keyRotationCheckInterval > 0 {
+		if shamirProvider != nil {
+			logger.Warnf("Scheduled key rotation is not supported with shamir secret lock")
+		} else {
+			startJob(jobsCtx, &jobs, leases, keyRotationJobName, params.keyRotationCheckInterval, func() {
+				rotateDueKeys(cmd)
+			})
+		}
+	}
+
 	router := mux.NewRouter()
 
 	zcapConfig := &zcapmw.ZCAPConfig{
@@ -307,6 +374,10 @@ func startServer(srv server, params *serverParameters) error { //nolint:funlen
 				middlewares = append(middlewares, &zcapmw.Middleware{Config: zcapConfig, Action: h.Action()})
 			}
 
+			if h.Auth().HasFlag(rest.AuthAdmin) {
+				middlewares = append(middlewares, &adminmw.Middleware{Token: params.adminToken})
+			}
+
 			if h.Auth().HasFlag(rest.AuthGNAP) {
 				gmw, err := gnapmw.NewMiddleware(
 					gnapRSClient,
@@ -333,7 +404,9 @@ func startServer(srv server, params *serverParameters) error { //nolint:funlen
 	if params.enableCORS {
 		handler = cors.New(
 			cors.Options{
-				AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodOptions},
+				AllowedMethods: []string{
+					http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+				},
 				AllowedHeaders: []string{"*"},
 				MaxAge:         60,
 			},
@@ -593,6 +666,30 @@ func createGNAPSigningJWK(keyFilePath string) (*jwk.JWK, *jwk.JWK, error) {
 	return privateJWK, publicJWK, nil
 }
 
+func readBackupSigningKey(keyFilePath string) (ed25519.PrivateKey, error) {
+	b, err := ioutil.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("invalid pem")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an Ed25519 private key")
+	}
+
+	return edKey, nil
+}
+
 type keyStoreCreator struct{}
 
 func (c *keyStoreCreator) Create(keyURI string, provider kms.Provider) (kms.KeyManager, error) {
@@ -637,6 +734,67 @@ func startMetrics(srv server, metricsHost string) {
 	}
 }
 
+// startJob runs the job periodically until the context is done. Server instances sharing the storage compete for the
+// job's lease, the job runs on the instance holding it.
+func startJob(ctx context.Context, wg *sync.WaitGroup, leases *lease.Manager, name string, interval time.Duration,
+	job func()) {
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			acquired, err := leases.Acquire(name, jobLeaseIntervals*interval)
+			if err != nil {
+				logger.Errorf("acquire %s lease: %v", name, err)
+
+				continue
+			}
+
+			if acquired {
+				job()
+			}
+		}
+	}()
+}
+
+// purgeExpiredKeys destroys keys whose pending deletion window has ended.
+func purgeExpiredKeys(cmd *command.Command) {
+	purged, failed, err := cmd.PurgeExpiredKeys()
+	if err != nil {
+		logger.Errorf("purge expired keys: %v", err)
+	}
+
+	if purged > 0 {
+		logger.Infof("Purged %d expired keys", purged)
+	}
+
+	if failed > 0 {
+		logger.Warnf("Failed to purge %d expired keys", failed)
+	}
+}
+
+// rotateDueKeys rotates keys whose rotation policy is due.
+func rotateDueKeys(cmd *command.Command) {
+	rotated, err := cmd.RotateDueKeys()
+	if err != nil {
+		logger.Errorf("rotate due keys: %v", err)
+	}
+
+	if rotated > 0 {
+		logger.Infof("Rotated %d keys", rotated)
+	}
+}
+
 type cryptoBoxCreator struct{}
 
 func (c *cryptoBoxCreator) Create(km kms.KeyManager) (command.CryptoBox, error) {
@@ -470,6 +470,144 @@ func TestStartCmdWithShamirCacheTTLParam(t *testing.T) {
 	})
 }
 
+func TestStartCmdWithKeyDeletionSweepIntervalParam(t *testing.T) {
+	t.Run("Success with key-deletion-sweep-interval set", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyDeletionSweepIntervalFlagName, "30m")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail with invalid key-deletion-sweep-interval duration string", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyDeletionSweepIntervalFlagName, "invalid")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+}
+
+func TestStartCmdWithKeyRotationCheckIntervalParam(t *testing.T) {
+	t.Run("Success with key-rotation-check-interval set", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyRotationCheckIntervalFlagName, "30m")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail with invalid key-rotation-check-interval duration string", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyRotationCheckIntervalFlagName, "invalid")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+}
+
+func TestStartCmdWithKeyExpiryGracePeriodParam(t *testing.T) {
+	t.Run("Success with key-expiry-grace-period set", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyExpiryGracePeriodFlagName, "24h")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail with invalid key-expiry-grace-period duration string", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyExpiryGracePeriodFlagName, "invalid")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+}
+
+func TestStartCmdWithJWTLifetimeParams(t *testing.T) {
+	t.Run("Success with jwt-lifetime and jwt-max-lifetime set", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+jwtLifetimeFlagName, "10m", "--"+jwtMaxLifetimeFlagName, "1h")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail with invalid jwt-lifetime duration string", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+jwtLifetimeFlagName, "invalid")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+
+	t.Run("Fail with invalid jwt-max-lifetime duration string", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+jwtMaxLifetimeFlagName, "invalid")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+
+	t.Run("Fail with jwt-lifetime exceeding jwt-max-lifetime", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+jwtLifetimeFlagName, "2h", "--"+jwtMaxLifetimeFlagName, "1h")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+}
+
 func TestStartCmdWithKMSCacheTTLParam(t *testing.T) {
 	t.Run("Success with kms-cache-ttl set", func(t *testing.T) {
 		startCmd, err := Cmd(&mockServer{})
@@ -512,6 +650,61 @@ func TestStartCmdWithKMSCacheTTLParam(t *testing.T) {
 	})
 }
 
+func TestStartCmdWithKeyManagerCacheParams(t *testing.T) {
+	t.Run("Success with key manager cache params set", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyManagerCacheTTLFlagName, "5m")
+		args = append(args, "--"+keyManagerCacheSizeFlagName, "100")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail with invalid key-manager-cache-ttl duration string", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyManagerCacheTTLFlagName, "invalid")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+
+	t.Run("Fail with invalid key-manager-cache-size", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyManagerCacheSizeFlagName, "invalid")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+
+	t.Run("Fail with zero key-manager-cache-size", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := requiredArgs(storageTypeMemOption)
+		args = append(args, "--"+keyManagerCacheSizeFlagName, "0")
+
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+	})
+}
+
 func TestStartKMSService(t *testing.T) {
 	const invalidStorageOption = "invalid"
 
@@ -32,14 +32,76 @@ const (
 	ActionStoreCapability = "updateEDVCapability"
 )
 
+// Key store management actions.
+const (
+	ActionGetKeyStore        = "getKeyStore"
+	ActionUpdateKeyStore     = "updateKeyStore"
+	ActionDeleteKeyStore     = "deleteKeyStore"
+	ActionDeactivateKeyStore = "deactivateKeyStore"
+	ActionActivateKeyStore   = "activateKeyStore"
+	ActionBackupKeyStore     = "backupKeyStore"
+	ActionRestoreKeyStore    = "restoreKeyStore"
+	ActionMigrateKeyStore    = "migrateKeyStore"
+)
+
+// Key management actions.
+const (
+	ActionListKeys         = "listKeys"
+	ActionExportPrivateKey = "exportPrivateKey"
+	ActionUpdateKey        = "updateKey"
+	ActionListKeyVersions  = "listKeyVersions"
+	ActionEnableKey        = "enableKey"
+	ActionDisableKey       = "disableKey"
+	ActionDeleteKey        = "deleteKey"
+	ActionSetAlias         = "setAlias"
+	ActionGetAlias         = "getAlias"
+	ActionListAliases      = "listAliases"
+	ActionDeleteAlias      = "deleteAlias"
+)
+
+// Token, linked data proof and batch actions.
+const (
+	ActionSignJWS     = "signJWS"
+	ActionVerifyJWS   = "verifyJWS"
+	ActionSignJWT     = "signJWT"
+	ActionVerifyJWT   = "verifyJWT"
+	ActionSignLDProof = "signLDProof"
+	ActionEncryptJWE  = "encryptJWE"
+	ActionDecryptJWE  = "decryptJWE"
+	ActionBatch       = "batch"
+)
+
 func allActions() []string {
 	return []string{
+		ActionGetKeyStore,
+		ActionUpdateKeyStore,
+		ActionDeleteKeyStore,
+		ActionDeactivateKeyStore,
+		ActionActivateKeyStore,
+		ActionBackupKeyStore,
+		ActionMigrateKeyStore,
 		ActionCreateKey,
+		ActionListKeys,
 		ActionExportKey,
+		ActionExportPrivateKey,
 		ActionImportKey,
 		ActionRotateKey,
+		ActionUpdateKey,
+		ActionListKeyVersions,
+		ActionEnableKey,
+		ActionDisableKey,
+		ActionSetAlias,
+		ActionGetAlias,
+		ActionListAliases,
+		ActionDeleteAlias,
+		ActionDeleteKey,
 		ActionSign,
 		ActionVerify,
+		ActionSignJWS,
+		ActionVerifyJWS,
+		ActionSignJWT,
+		ActionVerifyJWT,
+		ActionSignLDProof,
 		ActionComputeMac,
 		ActionVerifyMAC,
 		ActionEncrypt,
@@ -51,8 +113,11 @@ func allActions() []string {
 		ActionVerifyMulti,
 		ActionDeriveProof,
 		ActionVerifyProof,
+		ActionBatch,
 		ActionWrap,
 		ActionUnwrap,
+		ActionEncryptJWE,
+		ActionDecryptJWE,
 		ActionStoreCapability,
 	}
 }
@@ -11,13 +11,15 @@ package command
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/tink/go/keyset"
@@ -41,6 +43,8 @@ type zcapService interface {
 	KMS() kms.KeyManager
 	Crypto() crypto.Crypto
 	Resolve(string) (*zcapld.Capability, error)
+	Save(*zcapld.Capability) error
+	Revoke(string) error
 }
 
 // headerSigner computes a signature on the request and returns a header with the signature.
@@ -75,12 +79,26 @@ type metricsProvider interface {
 	CryptoSignTime(value time.Duration)
 	KeyStoreResolveTime(value time.Duration)
 	KeyStoreGetKeyTime(value time.Duration)
+	KeysRotated(count int)
+	KeyRotationFailures(count int)
+	LastKeyRotationTime(value time.Time)
+	NextKeyRotationTime(value time.Time)
+	KeyManagerCacheHit()
+	KeyManagerCacheMiss()
 }
 
 type cacheProvider interface {
 	Wrap(storageProvider storage.Provider, ttl time.Duration) storage.Provider
 }
 
+// keyManagerCache is a bounded cache for user's key managers. Concrete implementation is expected to be thread-safe,
+// and clean up items when ttl is expired.
+type keyManagerCache interface {
+	Get(key interface{}) (interface{}, bool)
+	SetWithTTL(key, value interface{}, cost int64, ttl time.Duration) bool
+	Del(key interface{})
+}
+
 // Config is a configuration for Command.
 type Config struct {
 	StorageProvider         storage.Provider
@@ -104,31 +122,51 @@ type Config struct {
 	MetricsProvider         metricsProvider
 	CacheProvider           cacheProvider
 	KeyStoreCacheTTL        time.Duration
+	KeyManagerCache         keyManagerCache
+	KeyManagerCacheTTL      time.Duration      // if not positive, user's key managers are not cached
+	KeyExpiryGracePeriod    time.Duration      // how long verification operations are allowed after a key expires
+	BackupSigningKey        ed25519.PrivateKey // signs key store backups, backups are disabled if not set
+	BackupTrustedSigners    []string           // did:key of Ed25519 keys whose backups can be restored
+	JWTLifetime             time.Duration      // lifetime of issued JWTs if not requested, defaults to 1h
+	JWTMaxLifetime          time.Duration      // max lifetime of issued JWTs, defaults to 24h
 }
 
 // Command is a controller for commands.
 type Command struct {
-	store               storage.Store
-	keyStorageProvider  storage.Provider
-	kms                 kms.KeyManager // server's key manager
-	crypto              crypto.Crypto
-	zcap                zcapService
-	enableZCAPs         bool
-	vdr                 zcapld.VDRResolver
-	documentLoader      ld.DocumentLoader
-	keyStoreCreator     keyStoreCreator // user's key manager creator
-	cryptoBox           cryptoBoxCreator
-	shamirLock          shamirSecretLockCreator
-	headerSigner        headerSigner
-	tlsConfig           *tls.Config
-	baseKeyStoreURL     string
-	shamirProvider      shamirProvider
-	mainKeyType         kms.KeyType
-	edvRecipientKeyType kms.KeyType
-	edvMACKeyType       kms.KeyType
-	cacheProvider       cacheProvider
-	keyStoreCacheTTL    time.Duration
-	metrics             metricsProvider
+	store                storage.Store
+	keyMetaStore         storage.Store
+	aliasStore           storage.Store
+	keyStorageProvider   storage.Provider
+	storageProvider      storage.Provider
+	kms                  kms.KeyManager // server's key manager
+	crypto               crypto.Crypto
+	zcap                 zcapService
+	enableZCAPs          bool
+	vdr                  zcapld.VDRResolver
+	documentLoader       ld.DocumentLoader
+	keyStoreCreator      keyStoreCreator // user's key manager creator
+	cryptoBox            cryptoBoxCreator
+	shamirLock           shamirSecretLockCreator
+	headerSigner         headerSigner
+	tlsConfig            *tls.Config
+	baseKeyStoreURL      string
+	shamirProvider       shamirProvider
+	mainKeyType          kms.KeyType
+	edvRecipientKeyType  kms.KeyType
+	edvMACKeyType        kms.KeyType
+	cacheProvider        cacheProvider
+	keyStoreCacheTTL     time.Duration
+	keyManagerCache      keyManagerCache
+	keyManagerCacheTTL   time.Duration
+	keyExpiryGrace       time.Duration
+	backupSigningKey     ed25519.PrivateKey
+	backupSigner         string                       // did:key of the backup signing key
+	backupTrustedSigners map[string]ed25519.PublicKey // trusted backup signers by did:key
+	jwtLifetime          time.Duration
+	jwtMaxLifetime       time.Duration
+	metrics              metricsProvider
+	keyTagNamesMutex     sync.Mutex
+	keyTagNames          map[string]struct{} // storage tag names of user's key tags registered in keys db config
 }
 
 // New returns a new instance of Command.
@@ -138,28 +176,74 @@ func New(c *Config) (*Command, error) {
 		return nil, fmt.Errorf("open key store db: %w", err)
 	}
 
+	keyMetaStore, err := c.StorageProvider.OpenStore(keysStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open keys db: %w", err)
+	}
+
+	err = c.StorageProvider.SetStoreConfig(keysStoreName, storage.StoreConfiguration{
+		TagNames: []string{keyStoreIDTagName, pendingDeletionTagName, rotationPolicyTagName, publishedTagName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("set keys db config: %w", err)
+	}
+
+	aliasStore, err := c.StorageProvider.OpenStore(aliasesStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open aliases db: %w", err)
+	}
+
+	err = c.StorageProvider.SetStoreConfig(aliasesStoreName, storage.StoreConfiguration{
+		TagNames: []string{keyStoreIDTagName, aliasKeyIDTagName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("set aliases db config: %w", err)
+	}
+
+	backupSigner, backupTrustedSigners, err := backupSigners(c.BackupSigningKey, c.BackupTrustedSigners)
+	if err != nil {
+		return nil, fmt.Errorf("parse backup signers: %w", err)
+	}
+
+	jwtLifetime, jwtMaxLifetime, err := jwtLifetimes(c.JWTLifetime, c.JWTMaxLifetime)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Command{
-		store:               store,
-		keyStorageProvider:  c.KeyStorageProvider,
-		kms:                 c.KMS,
-		crypto:              c.Crypto,
-		zcap:                c.ZCAPService,
-		enableZCAPs:         c.EnableZCAPs,
-		vdr:                 c.VDRResolver,
-		documentLoader:      c.DocumentLoader,
-		keyStoreCreator:     c.KeyStoreCreator,
-		shamirLock:          c.ShamirSecretLockCreator,
-		cryptoBox:           c.CryptBoxCreator,
-		headerSigner:        c.HeaderSigner,
-		tlsConfig:           c.TLSConfig,
-		baseKeyStoreURL:     c.BaseKeyStoreURL,
-		shamirProvider:      c.ShamirProvider,
-		mainKeyType:         c.MainKeyType,
-		edvRecipientKeyType: c.EDVRecipientKeyType,
-		edvMACKeyType:       c.EDVMACKeyType,
-		cacheProvider:       c.CacheProvider,
-		keyStoreCacheTTL:    c.KeyStoreCacheTTL,
-		metrics:             c.MetricsProvider,
+		store:                store,
+		keyMetaStore:         keyMetaStore,
+		keyTagNames:          make(map[string]struct{}),
+		aliasStore:           aliasStore,
+		keyStorageProvider:   c.KeyStorageProvider,
+		storageProvider:      c.StorageProvider,
+		kms:                  c.KMS,
+		crypto:               c.Crypto,
+		zcap:                 c.ZCAPService,
+		enableZCAPs:          c.EnableZCAPs,
+		vdr:                  c.VDRResolver,
+		documentLoader:       c.DocumentLoader,
+		keyStoreCreator:      c.KeyStoreCreator,
+		shamirLock:           c.ShamirSecretLockCreator,
+		cryptoBox:            c.CryptBoxCreator,
+		headerSigner:         c.HeaderSigner,
+		tlsConfig:            c.TLSConfig,
+		baseKeyStoreURL:      c.BaseKeyStoreURL,
+		shamirProvider:       c.ShamirProvider,
+		mainKeyType:          c.MainKeyType,
+		edvRecipientKeyType:  c.EDVRecipientKeyType,
+		edvMACKeyType:        c.EDVMACKeyType,
+		cacheProvider:        c.CacheProvider,
+		keyStoreCacheTTL:     c.KeyStoreCacheTTL,
+		keyManagerCache:      c.KeyManagerCache,
+		keyManagerCacheTTL:   c.KeyManagerCacheTTL,
+		keyExpiryGrace:       c.KeyExpiryGracePeriod,
+		backupSigningKey:     c.BackupSigningKey,
+		backupSigner:         backupSigner,
+		backupTrustedSigners: backupTrustedSigners,
+		jwtLifetime:          jwtLifetime,
+		jwtMaxLifetime:       jwtMaxLifetime,
+		metrics:              c.MetricsProvider,
 	}, nil
 }
 
@@ -182,12 +266,20 @@ func (c *Command) CreateKey(w io.Writer, r io.Reader) error {
 		return fmt.Errorf("unwrap request: %w", err)
 	}
 
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if err = c.checkRotationPolicy(req.RotationPolicy); err != nil {
+		return err
+	}
+
 	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
 	if err != nil {
 		return fmt.Errorf("resolve key store: %w", err)
 	}
 
-	kid, _, err := ks.Create(req.KeyType)
+	kid, kh, err := ks.Create(req.KeyType)
 	if err != nil {
 		return fmt.Errorf("create key: %w", err)
 	}
@@ -199,30 +291,107 @@ func (c *Command) CreateKey(w io.Writer, r io.Reader) error {
 		}
 	}
 
+	createdAt := time.Now().UTC()
+
+	meta := &keyMeta{
+		ID:                kid,
+		KeyStoreID:        wr.KeyStoreID,
+		KeyType:           req.KeyType,
+		Status:            keyStatusEnabled,
+		Label:             req.Label,
+		Description:       req.Description,
+		Tags:              req.Tags,
+		Versions:          newKeyVersions(kid, kh, createdAt),
+		AllowedOperations: req.AllowedOperations,
+		NotBefore:         req.NotBefore,
+		NotAfter:          req.NotAfter,
+		Exportable:        req.Exportable,
+		CreatedAt:         createdAt,
+	}
+
+	meta.setRotationPolicy(req.RotationPolicy, createdAt)
+
+	if req.Publish {
+		if err = publishKey(ks, meta); err != nil {
+			return fmt.Errorf("publish key: %w", err)
+		}
+	}
+
+	if err = c.saveKeyMeta(meta); err != nil {
+		return fmt.Errorf("save key metadata: %w", err)
+	}
+
 	return json.NewEncoder(w).Encode(CreateKeyResponse{
-		KeyURL:    fmt.Sprintf("%s/%s/keys/%s", c.baseKeyStoreURL, wr.KeyStoreID, kid),
+		KeyURL:    c.keyURL(wr.KeyStoreID, kid),
 		PublicKey: pub,
 	})
 }
 
 // ExportKey exports a key.
 func (c *Command) ExportKey(w io.Writer, r io.Reader) error {
+	var req ExportKeyRequest
+
 	wr, err := unwrapRequest(nil, r)
 	if err != nil {
 		return fmt.Errorf("unwrap request: %w", err)
 	}
 
+	if len(wr.Request) > 0 { // request is optional, public key bytes are exported as is by default
+		if err = json.Unmarshal(wr.Request, &req); err != nil {
+			return fmt.Errorf("unwrap request: %w: decode request", errors.ErrInternal)
+		}
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
 	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
 	if err != nil {
 		return fmt.Errorf("resolve key store: %w", err)
 	}
 
-	b, kt, err := ks.ExportPubKeyBytes(wr.KeyID)
-	if err != nil {
-		return fmt.Errorf("export public key bytes: %w", err)
+	meta, err := c.getKeyMeta(wr.KeyStoreID, wr.KeyID)
+	if err != nil && !goerrors.Is(err, storage.ErrDataNotFound) { // keys created before key metadata was introduced
+		return fmt.Errorf("get key metadata: %w", err)
+	}
+
+	var resp ExportKeyResponse
+
+	if meta != nil && meta.PublicKey != nil { // imported public key
+		resp.PublicKey, resp.KeyType = meta.PublicKey, string(meta.KeyType)
+	} else {
+		b, kt, exportErr := ks.ExportPubKeyBytes(wr.KeyID)
+		if exportErr != nil {
+			return fmt.Errorf("export public key bytes: %w", exportErr)
+		}
+
+		resp.PublicKey, resp.KeyType = b, string(kt)
+	}
+
+	if err = formatPublicKey(&resp, wr.KeyID, resp.PublicKey, kms.KeyType(resp.KeyType), req.Format); err != nil {
+		return fmt.Errorf("format public key: %w", err)
 	}
 
-	return json.NewEncoder(w).Encode(ExportKeyResponse{PublicKey: b, KeyType: string(kt)})
+	if meta != nil {
+		resp.Label = meta.Label
+		resp.Description = meta.Description
+		resp.Tags = meta.Tags
+		resp.AllowedOperations = meta.allowedOperations()
+		resp.NotBefore = meta.NotBefore
+		resp.NotAfter = meta.NotAfter
+		resp.RotationPolicy = meta.RotationPolicy
+		resp.LastRotatedAt = meta.LastRotatedAt
+		resp.NextRotationAt = meta.NextRotationAt
+		resp.Published = meta.Published
+		resp.Exportable = meta.Exportable
+	}
+
+	return json.NewEncoder(w).Encode(resp)
 }
 
 // ImportKey imports a key.
@@ -234,43 +403,74 @@ func (c *Command) ImportKey(w io.Writer, r io.Reader) error {
 		return fmt.Errorf("unwrap request: %w", err)
 	}
 
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if err = c.checkRotationPolicy(req.RotationPolicy); err != nil {
+		return err
+	}
+
 	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
 	if err != nil {
 		return fmt.Errorf("resolve key store: %w", err)
 	}
 
-	var privateKey interface{}
+	var (
+		kid        string
+		kh         interface{}
+		publicKey  []byte
+		operations = req.AllowedOperations
+	)
 
-	switch req.KeyType { //nolint:exhaustive
-	case
-		kms.ED25519Type,
-		kms.ECDSAP256TypeDER,
-		kms.ECDSAP384TypeDER,
-		kms.ECDSAP521TypeDER,
-		kms.ECDSAP256TypeIEEEP1363,
-		kms.ECDSAP384TypeIEEEP1363,
-		kms.ECDSAP521TypeIEEEP1363:
-		privateKey, err = x509.ParsePKCS8PrivateKey(req.Key)
+	if req.Public {
+		kid, publicKey, err = c.importPublicKey(wr.KeyStoreID, &req)
 		if err != nil {
-			return fmt.Errorf("parse private key: %w", err)
+			return err
+		}
+
+		if len(operations) == 0 {
+			operations = publicKeyOperations[req.KeyType]
+		}
+	} else {
+		kid, kh, err = c.importPrivateKey(ks, wr, &req)
+		if err != nil {
+			return err
 		}
-	default:
-		return fmt.Errorf("not supported key type: %s", req.KeyType)
 	}
 
-	var opts []kms.PrivateKeyOpts
+	createdAt := time.Now().UTC()
 
-	if req.KeyID != "" {
-		opts = append(opts, kms.WithKeyID(req.KeyID))
+	meta := &keyMeta{
+		ID:                kid,
+		KeyStoreID:        wr.KeyStoreID,
+		KeyType:           req.KeyType,
+		Status:            keyStatusEnabled,
+		Label:             req.Label,
+		Description:       req.Description,
+		Tags:              req.Tags,
+		Versions:          newKeyVersions(kid, kh, createdAt),
+		AllowedOperations: operations,
+		NotBefore:         req.NotBefore,
+		NotAfter:          req.NotAfter,
+		PublicKey:         publicKey,
+		CreatedAt:         createdAt,
 	}
 
-	kid, _, err := ks.ImportPrivateKey(privateKey, req.KeyType, opts...)
-	if err != nil {
-		return fmt.Errorf("import private key: %w", err)
+	meta.setRotationPolicy(req.RotationPolicy, createdAt)
+
+	if req.Publish {
+		if err = publishKey(ks, meta); err != nil {
+			return fmt.Errorf("publish key: %w", err)
+		}
+	}
+
+	if err = c.saveKeyMeta(meta); err != nil {
+		return fmt.Errorf("save key metadata: %w", err)
 	}
 
 	return json.NewEncoder(w).Encode(ImportKeyResponse{
-		KeyURL: fmt.Sprintf("%s/%s/keys/%s", c.baseKeyStoreURL, wr.KeyStoreID, kid),
+		KeyURL: c.keyURL(wr.KeyStoreID, kid),
 	})
 }
 
@@ -283,18 +483,26 @@ func (c *Command) RotateKey(w io.Writer, r io.Reader) error {
 		return fmt.Errorf("unwrap request: %w", err)
 	}
 
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
 	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
 	if err != nil {
 		return fmt.Errorf("resolve key store: %w", err)
 	}
 
-	kid, _, err := ks.Rotate(req.KeyType, wr.KeyID)
+	if err = c.checkKeyUsable(wr.KeyStoreID, wr.KeyID, ""); err != nil {
+		return err
+	}
+
+	meta, err := c.rotateKey(ks, wr.KeyStoreID, wr.KeyID, req.KeyType)
 	if err != nil {
-		return fmt.Errorf("rotate key: %w", err)
+		return err
 	}
 
 	return json.NewEncoder(w).Encode(RotateKeyResponse{
-		KeyURL: fmt.Sprintf("%s/%s/keys/%s", c.baseKeyStoreURL, wr.KeyStoreID, kid),
+		KeyURL: c.keyURL(wr.KeyStoreID, meta.ID),
 	})
 }
 
@@ -302,33 +510,46 @@ func (c *Command) RotateKey(w io.Writer, r io.Reader) error {
 func (c *Command) Sign(w io.Writer, r io.Reader) error {
 	var req SignRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionSign)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.sign(kh, &req)
 	if err != nil {
 		return err
 	}
 
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func (c *Command) sign(kh interface{}, req *SignRequest) (*SignResponse, error) {
 	signStartTime := time.Now()
 
 	signature, err := c.crypto.Sign(req.Message, kh)
 	if err != nil {
-		return fmt.Errorf("sign: %w", err)
+		return nil, fmt.Errorf("sign: %w", err)
 	}
 
 	c.metrics.CryptoSignTime(time.Since(signStartTime))
 
-	return json.NewEncoder(w).Encode(SignResponse{Signature: signature})
+	return &SignResponse{Signature: signature}, nil
 }
 
 // Verify verifies a signature.
 func (c *Command) Verify(_ io.Writer, r io.Reader) error {
 	var req VerifyRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionVerify)
 	if err != nil {
 		return err
 	}
 
-	pub, err := kh.(*keyset.Handle).Public()
+	return c.verify(kh, &req)
+}
+
+func (c *Command) verify(kh interface{}, req *VerifyRequest) error {
+	pub, err := publicKeyHandle(kh.(*keyset.Handle))
 	if err != nil {
 		return fmt.Errorf("verify: %w", err)
 	}
@@ -344,61 +565,88 @@ func (c *Command) Verify(_ io.Writer, r io.Reader) error {
 func (c *Command) Encrypt(w io.Writer, r io.Reader) error {
 	var req EncryptRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionEncrypt)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.encrypt(kh, &req)
 	if err != nil {
 		return err
 	}
 
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func (c *Command) encrypt(kh interface{}, req *EncryptRequest) (*EncryptResponse, error) {
 	cipher, nonce, err := c.crypto.Encrypt(req.Message, req.AssociatedData, kh)
 	if err != nil {
-		return fmt.Errorf("encrypt: %w", err)
+		return nil, fmt.Errorf("encrypt: %w", err)
 	}
 
-	return json.NewEncoder(w).Encode(EncryptResponse{
+	return &EncryptResponse{
 		Ciphertext: cipher,
 		Nonce:      nonce,
-	})
+	}, nil
 }
 
 // Decrypt decrypts a ciphertext.
 func (c *Command) Decrypt(w io.Writer, r io.Reader) error {
 	var req DecryptRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionDecrypt)
 	if err != nil {
 		return err
 	}
 
+	resp, err := c.decrypt(kh, &req)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func (c *Command) decrypt(kh interface{}, req *DecryptRequest) (*DecryptResponse, error) {
 	plain, err := c.crypto.Decrypt(req.Ciphertext, req.AssociatedData, req.Nonce, kh)
 	if err != nil {
-		return fmt.Errorf("decrypt: %w", err)
+		return nil, fmt.Errorf("decrypt: %w", err)
 	}
 
-	return json.NewEncoder(w).Encode(DecryptResponse{Plaintext: plain})
+	return &DecryptResponse{Plaintext: plain}, nil
 }
 
 // ComputeMAC computes message authentication code for data.
 func (c *Command) ComputeMAC(w io.Writer, r io.Reader) error {
 	var req ComputeMACRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionComputeMac)
 	if err != nil {
 		return err
 	}
 
+	resp, err := c.computeMAC(kh, &req)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func (c *Command) computeMAC(kh interface{}, req *ComputeMACRequest) (*ComputeMACResponse, error) {
 	mac, err := c.crypto.ComputeMAC(req.Data, kh)
 	if err != nil {
-		return fmt.Errorf("compute mac: %w", err)
+		return nil, fmt.Errorf("compute mac: %w", err)
 	}
 
-	return json.NewEncoder(w).Encode(ComputeMACResponse{MAC: mac})
+	return &ComputeMACResponse{MAC: mac}, nil
 }
 
 // VerifyMAC verifies message authentication code for data.
 func (c *Command) VerifyMAC(_ io.Writer, r io.Reader) error {
 	var req VerifyMACRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionVerifyMAC)
 	if err != nil {
 		return err
 	}
@@ -414,24 +662,33 @@ func (c *Command) VerifyMAC(_ io.Writer, r io.Reader) error {
 func (c *Command) SignMulti(w io.Writer, r io.Reader) error {
 	var req SignMultiRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionSignMulti)
 	if err != nil {
 		return err
 	}
 
+	resp, err := c.signMulti(kh, &req)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func (c *Command) signMulti(kh interface{}, req *SignMultiRequest) (*SignMultiResponse, error) {
 	signature, err := c.crypto.SignMulti(req.Messages, kh)
 	if err != nil {
-		return fmt.Errorf("sign multi: %w", err)
+		return nil, fmt.Errorf("sign multi: %w", err)
 	}
 
-	return json.NewEncoder(w).Encode(SignMultiResponse{Signature: signature})
+	return &SignMultiResponse{Signature: signature}, nil
 }
 
 // VerifyMulti verifies a signature of messages (BBS+).
 func (c *Command) VerifyMulti(_ io.Writer, r io.Reader) error {
 	var req VerifyMultiRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionVerifyMulti)
 	if err != nil {
 		return err
 	}
@@ -447,7 +704,7 @@ func (c *Command) VerifyMulti(_ io.Writer, r io.Reader) error {
 func (c *Command) DeriveProof(w io.Writer, r io.Reader) error {
 	var req DeriveProofRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionDeriveProof)
 	if err != nil {
 		return err
 	}
@@ -464,7 +721,7 @@ func (c *Command) DeriveProof(w io.Writer, r io.Reader) error {
 func (c *Command) VerifyProof(_ io.Writer, r io.Reader) error {
 	var req VerifyProofRequest
 
-	kh, err := c.getKeyHandle(&req, r)
+	kh, err := c.getKeyHandle(&req, r, ActionVerifyProof)
 	if err != nil {
 		return err
 	}
@@ -524,6 +781,22 @@ func (c *Command) WrapKey(w io.Writer, r io.Reader) error {
 		return fmt.Errorf("unmarshal unwrap wrap request: %w", err)
 	}
 
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
+	if wr.KeyID != "" {
+		operation := ActionWrap
+
+		if req.CEK == nil {
+			operation = ActionEasy
+		}
+
+		if err = c.checkKeyUsable(wr.KeyStoreID, wr.KeyID, operation); err != nil {
+			return err
+		}
+	}
+
 	if req.CEK == nil {
 		var reqEasy EasyRequest
 
@@ -551,13 +824,29 @@ func (c *Command) WrapKey(w io.Writer, r io.Reader) error {
 			return fmt.Errorf("get key %s: %w", wr.KeyID, getErr)
 		}
 
-		opts = append(opts, crypto.WithSender(kh))
+		versions, versionsErr := keyVersionHandles(kh)
+		if versionsErr != nil {
+			return fmt.Errorf("get key versions: %w", versionsErr)
+		}
+
+		if len(versions) == 0 {
+			return fmt.Errorf("%w: key has no enabled versions", errors.ErrConflict)
+		}
+
+		opts = append(opts, crypto.WithSender(versions[0])) // sender key is the primary version
 
 		if req.Tag != nil {
 			opts = append(opts, crypto.WithTag(req.Tag))
 		}
 	}
 
+	if req.RecipientKeyID != "" {
+		req.RecipientPubKey, err = c.recipientPublicKey(wr.KeyStoreID, req.RecipientKeyID)
+		if err != nil {
+			return err
+		}
+	}
+
 	wk, err := c.crypto.WrapKey(req.CEK, req.APU, req.APV, req.RecipientPubKey, opts...)
 	if err != nil {
 		return fmt.Errorf("wrap key: %w", err)
@@ -606,7 +895,7 @@ func (c *Command) UnwrapKey(w io.Writer, r io.Reader) error {
 		return fmt.Errorf("unwrapKey cryptobox request invalid: %w", err)
 	}
 
-	kh, err := c.getKeyHandleFromRequest(wr)
+	kh, err := c.getKeyHandleFromRequest(wr, ActionUnwrap)
 	if err != nil {
 		return err
 	}
@@ -621,7 +910,7 @@ func (c *Command) UnwrapKey(w io.Writer, r io.Reader) error {
 		}
 	}
 
-	k, err := c.crypto.UnwrapKey(&req.WrappedKey, kh, opts...)
+	k, err := c.unwrapKey(&req.WrappedKey, kh, opts...)
 	if err != nil {
 		return fmt.Errorf("unwrap key: %w", err)
 	}
@@ -629,38 +918,33 @@ func (c *Command) UnwrapKey(w io.Writer, r io.Reader) error {
 	return json.NewEncoder(w).Encode(UnwrapKeyResponse{Key: k})
 }
 
-func (c *Command) getKeyHandle(req interface{}, r io.Reader) (interface{}, error) {
+// getKeyHandle returns a handle of the key for the crypto operation. Operation is named after the command's action.
+func (c *Command) getKeyHandle(req interface{}, r io.Reader, operation string) (interface{}, error) {
 	wr, err := unwrapRequest(req, r)
 	if err != nil {
 		return nil, fmt.Errorf("unwrap request: %w", err)
 	}
 
-	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
-	if err != nil {
-		return nil, fmt.Errorf("resolve key store: %w", err)
-	}
-
-	getStartTime := time.Now()
+	return c.getKeyHandleFromRequest(wr, operation)
+}
 
-	kh, err := ks.Get(wr.KeyID)
-	if err != nil {
-		return nil, fmt.Errorf("get key: %w", err)
+func (c *Command) getKeyHandleFromRequest(wr *WrappedRequest, operation string) (interface{}, error) {
+	if err := c.resolveKeyID(wr); err != nil {
+		return nil, err
 	}
 
-	c.metrics.KeyStoreGetKeyTime(time.Since(getStartTime))
-
-	return kh, nil
-}
-
-func (c *Command) getKeyHandleFromRequest(wr *WrappedRequest) (interface{}, error) {
 	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
 	if err != nil {
 		return nil, fmt.Errorf("resolve key store: %w", err)
 	}
 
+	if err = c.checkKeyUsable(wr.KeyStoreID, wr.KeyID, operation); err != nil {
+		return nil, err
+	}
+
 	getStartTime := time.Now()
 
-	kh, err := ks.Get(wr.KeyID)
+	kh, err := c.getKey(ks, wr.KeyStoreID, wr.KeyID)
 	if err != nil {
 		return nil, fmt.Errorf("get key: %w", err)
 	}
@@ -701,23 +985,53 @@ func unwrapRequest(req interface{}, r io.Reader) (*WrappedRequest, error) {
 }
 
 func (c *Command) resolveKeyStore(keyStoreID, user string, secretShare []byte) (kms.KeyManager, error) {
+	if c.keyManagerCache != nil && c.keyManagerCacheTTL > 0 {
+		return c.resolveCachedKeyStore(keyStoreID, user, secretShare)
+	}
+
+	keyURI, p, err := c.resolveKeyStoreProvider(keyStoreID, user, secretShare)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.keyStoreCreator.Create(keyURI, p)
+}
+
+// resolveKeyStoreProvider returns the primary key URI and the provider (storage and secret lock) of the key store.
+func (c *Command) resolveKeyStoreProvider(keyStoreID, user string, secretShare []byte) (string, *keyStoreProvider,
+	error) {
 	startTime := time.Now()
 	defer func() { c.metrics.KeyStoreResolveTime(time.Since(startTime)) }()
 
-	b, err := c.store.Get(keyStoreID)
+	meta, err := c.getActiveKeyStoreMeta(keyStoreID)
 	if err != nil {
-		return nil, fmt.Errorf("get key store meta: %w", err)
+		return "", nil, err
 	}
 
-	var meta keyStoreMeta
+	return c.newKeyStoreProvider(meta, user, secretShare)
+}
 
-	if err = json.Unmarshal(b, &meta); err != nil {
-		return nil, fmt.Errorf("unmarshal key store meta: %w", err)
+// getActiveKeyStoreMeta returns metadata of the key store or ErrConflict if the key store is deactivated.
+func (c *Command) getActiveKeyStoreMeta(keyStoreID string) (*keyStoreMeta, error) {
+	meta, err := c.getKeyStoreMeta(keyStoreID)
+	if err != nil {
+		return nil, err
 	}
 
-	storageProvider, err := c.getStorageProvider(&meta)
+	if meta.status() == keyStoreStatusDeactivated {
+		return nil, fmt.Errorf("%w: key store is deactivated", errors.ErrConflict)
+	}
+
+	return meta, nil
+}
+
+// newKeyStoreProvider returns the primary key URI and the provider (storage and secret lock) for the key store
+// metadata.
+func (c *Command) newKeyStoreProvider(meta *keyStoreMeta, user string, secretShare []byte) (string, *keyStoreProvider,
+	error) {
+	kmsStore, err := c.openKMSStore(meta)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	var secretLock secretlock.Service
@@ -725,7 +1039,7 @@ func (c *Command) resolveKeyStore(keyStoreID, user string, secretShare []byte) (
 	if c.shamirProvider != nil {
 		secretLock, err = c.createShamirSecretLock(user, secretShare)
 		if err != nil {
-			return nil, fmt.Errorf("create shamir secret lock: %w", err)
+			return "", nil, fmt.Errorf("create shamir secret lock: %w", err)
 		}
 	} else {
 		secretLock = key.NewLock(&keyLockProvider{
@@ -740,6 +1054,34 @@ func (c *Command) resolveKeyStore(keyStoreID, user string, secretShare []byte) (
 		keyID = "noop"
 	}
 
+	return localKeyURIPrefix + keyID, &keyStoreProvider{
+		storageProvider: kmsStore,
+		secretLock:      secretLock,
+	}, nil
+}
+
+func (c *Command) getKeyStoreMeta(keyStoreID string) (*keyStoreMeta, error) {
+	b, err := c.store.Get(keyStoreID)
+	if err != nil {
+		return nil, fmt.Errorf("get key store meta: %w", err)
+	}
+
+	var meta keyStoreMeta
+
+	if err = json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("unmarshal key store meta: %w", err)
+	}
+
+	return &meta, nil
+}
+
+// openKMSStore opens the store with user's keysets (local or EDV) for the given key store.
+func (c *Command) openKMSStore(meta *keyStoreMeta) (kms.Store, error) {
+	storageProvider, err := c.getStorageProvider(meta)
+	if err != nil {
+		return nil, err
+	}
+
 	// TODO (#327): Create our own implementation of the KMS storage interface and pass it in here instead of wrapping
 	//  the Aries storage provider.
 	kmsStore, err := kms.NewAriesProviderWrapper(storageProvider)
@@ -747,27 +1089,13 @@ func (c *Command) resolveKeyStore(keyStoreID, user string, secretShare []byte) (
 		return nil, err
 	}
 
-	return c.keyStoreCreator.Create(localKeyURIPrefix+keyID, &keyStoreProvider{
-		storageProvider: kmsStore,
-		secretLock:      secretLock,
-	})
+	return kmsStore, nil
 }
 
 func (c *Command) getStorageProvider(meta *keyStoreMeta) (storage.Provider, error) {
-	var storageProvider storage.Provider
-
-	if meta.EDV.VaultURL != "" {
-		var err error
-
-		storageProvider, err = c.resolveEDVProvider(meta.EDV.VaultURL, meta.EDV.RecipientKeyID, meta.EDV.MACKeyID,
-			meta.EDV.Capability)
-		if err != nil {
-			return nil, fmt.Errorf("resolve edv provider: %w", err)
-		}
-
-		storageProvider = metrics.Wrap(storageProvider, "EDV")
-	} else {
-		storageProvider = c.keyStorageProvider
+	storageProvider, err := c.getBackendStorageProvider(meta.EDV)
+	if err != nil {
+		return nil, err
 	}
 
 	if c.cacheProvider != nil && c.keyStoreCacheTTL > 0 {
@@ -777,6 +1105,22 @@ func (c *Command) getStorageProvider(meta *keyStoreMeta) (storage.Provider, erro
 	return storageProvider, nil
 }
 
+// getBackendStorageProvider returns the uncached storage provider of the EDV vault or server's local storage if the
+// vault is not set.
+func (c *Command) getBackendStorageProvider(edvParams edvParameters) (storage.Provider, error) {
+	if edvParams.VaultURL == "" {
+		return c.keyStorageProvider, nil
+	}
+
+	storageProvider, err := c.resolveEDVProvider(edvParams.VaultURL, edvParams.RecipientKeyID, edvParams.MACKeyID,
+		edvParams.Capability)
+	if err != nil {
+		return nil, fmt.Errorf("resolve edv provider: %w", err)
+	}
+
+	return metrics.Wrap(storageProvider, "EDV"), nil
+}
+
 func (c *Command) resolveEDVProvider(vaultURL, recKeyID, macKeyID string, capability []byte) (storage.Provider, error) {
 	recPubBytes, _, err := c.kms.ExportPubKeyBytes(recKeyID)
 	if err != nil {
@@ -819,10 +1163,7 @@ func (c *Command) createEDVStorageProvider(vaultURL string, recipientPubKey *cry
 		edv.WithDeterministicDocumentIDs(),
 	)
 
-	s := strings.Split(vaultURL, "/")
-
-	edvServerURL := strings.Join(s[:len(s)-1], "/")
-	vaultID := s[len(s)-1]
+	edvServerURL, vaultID := splitVaultURL(vaultURL)
 
 	return edv.NewRESTProvider(
 		edvServerURL,
@@ -834,3 +1175,10 @@ func (c *Command) createEDVStorageProvider(vaultURL string, recipientPubKey *cry
 		}),
 	), nil
 }
+
+// splitVaultURL splits EDV vault URL into EDV server URL and vault ID.
+func splitVaultURL(vaultURL string) (string, string) {
+	s := strings.Split(vaultURL, "/")
+
+	return strings.Join(s[:len(s)-1], "/"), s[len(s)-1]
+}
@@ -0,0 +1,321 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	aliasesStoreName    = "aliases"
+	aliasKeyIDTagName   = "keyID"
+	aliasKeyIDPrefix    = "alias:" // key ID in the form "alias:<name>" refers to the key the alias points to
+	maxAliasNameLength  = 256
+	aliasNameCharacters = "letters, digits, '-' and '_'"
+)
+
+//nolint:gochecknoglobals
+var aliasNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// aliasMeta is an alias of a key in user's key store saved in the underlying storage.
+type aliasMeta struct {
+	Name       string    `json:"name"`
+	KeyStoreID string    `json:"key_store_id"`
+	KeyID      string    `json:"key_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// SetAlias creates an alias for a key or repoints existing alias to another key.
+func (c *Command) SetAlias(w io.Writer, r io.Reader) error {
+	var req SetAliasRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if _, err = c.findKeyMeta(wr.KeyStoreID, req.KeyID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	alias, err := c.getAlias(wr.KeyStoreID, req.Name)
+	if err != nil && !goerrors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("get alias: %w", err)
+	}
+
+	if alias == nil {
+		alias = &aliasMeta{
+			Name:       req.Name,
+			KeyStoreID: wr.KeyStoreID,
+			CreatedAt:  now,
+		}
+	}
+
+	alias.KeyID = req.KeyID
+	alias.UpdatedAt = now
+
+	if err = c.saveAlias(alias); err != nil {
+		return fmt.Errorf("save alias: %w", err)
+	}
+
+	return json.NewEncoder(w).Encode(c.aliasInfo(alias))
+}
+
+// GetAlias returns the key an alias points to.
+func (c *Command) GetAlias(w io.Writer, r io.Reader) error {
+	var req AliasRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	alias, err := c.findAlias(wr.KeyStoreID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(c.aliasInfo(alias))
+}
+
+// ListAliases lists aliases in the key store.
+func (c *Command) ListAliases(w io.Writer, r io.Reader) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	aliases, err := c.queryAliases(fmt.Sprintf("%s:%s", keyStoreIDTagName, wr.KeyStoreID))
+	if err != nil {
+		return fmt.Errorf("list aliases: %w", err)
+	}
+
+	resp := ListAliasesResponse{Aliases: make([]AliasInfo, 0, len(aliases))}
+
+	for _, alias := range aliases {
+		resp.Aliases = append(resp.Aliases, c.aliasInfo(alias))
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// DeleteAlias deletes an alias. The key the alias points to is not affected.
+func (c *Command) DeleteAlias(_ io.Writer, r io.Reader) error {
+	var req AliasRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if _, err = c.findAlias(wr.KeyStoreID, req.Name); err != nil {
+		return err
+	}
+
+	if err = c.aliasStore.Delete(aliasID(wr.KeyStoreID, req.Name)); err != nil {
+		return fmt.Errorf("delete alias: %w", err)
+	}
+
+	return nil
+}
+
+// resolveKeyID replaces a key ID in the form "alias:<name>" with ID of the key the alias points to.
+func (c *Command) resolveKeyID(wr *WrappedRequest) error {
+	if !strings.HasPrefix(wr.KeyID, aliasKeyIDPrefix) {
+		return nil
+	}
+
+	alias, err := c.findAlias(wr.KeyStoreID, strings.TrimPrefix(wr.KeyID, aliasKeyIDPrefix))
+	if err != nil {
+		return err
+	}
+
+	wr.KeyID = alias.KeyID
+
+	return nil
+}
+
+// repointAliases points aliases of the old key to the new one. All aliases are updated in one batch.
+func (c *Command) repointAliases(keyStoreID, oldKeyID, newKeyID string) error {
+	aliases, err := c.queryAliases(fmt.Sprintf("%s:%s", aliasKeyIDTagName, keyMetaID(keyStoreID, oldKeyID)))
+	if err != nil {
+		return fmt.Errorf("query aliases: %w", err)
+	}
+
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+
+	ops := make([]storage.Operation, 0, len(aliases))
+
+	for _, alias := range aliases {
+		alias.KeyID = newKeyID
+		alias.UpdatedAt = now
+
+		op, opErr := aliasPutOperation(alias)
+		if opErr != nil {
+			return opErr
+		}
+
+		ops = append(ops, op)
+	}
+
+	if err = c.aliasStore.Batch(ops); err != nil {
+		return fmt.Errorf("batch: %w", err)
+	}
+
+	return nil
+}
+
+// deleteAliases deletes aliases that satisfy the expression.
+func (c *Command) deleteAliases(expression string) error {
+	aliases, err := c.queryAliases(expression)
+	if err != nil {
+		return fmt.Errorf("query aliases: %w", err)
+	}
+
+	for _, alias := range aliases {
+		if err = c.aliasStore.Delete(aliasID(alias.KeyStoreID, alias.Name)); err != nil {
+			return fmt.Errorf("delete alias %s: %w", alias.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Command) aliasInfo(alias *aliasMeta) AliasInfo {
+	return AliasInfo{
+		Name:      alias.Name,
+		KeyID:     alias.KeyID,
+		KeyURL:    c.keyURL(alias.KeyStoreID, alias.KeyID),
+		CreatedAt: alias.CreatedAt,
+		UpdatedAt: alias.UpdatedAt,
+	}
+}
+
+func aliasID(keyStoreID, name string) string {
+	return keyStoreID + "_" + name
+}
+
+func aliasPutOperation(alias *aliasMeta) (storage.Operation, error) {
+	b, err := json.Marshal(alias)
+	if err != nil {
+		return storage.Operation{}, fmt.Errorf("marshal: %w", err)
+	}
+
+	return storage.Operation{
+		Key:   aliasID(alias.KeyStoreID, alias.Name),
+		Value: b,
+		Tags: []storage.Tag{
+			{Name: keyStoreIDTagName, Value: alias.KeyStoreID},
+			{Name: aliasKeyIDTagName, Value: keyMetaID(alias.KeyStoreID, alias.KeyID)},
+		},
+	}, nil
+}
+
+func (c *Command) saveAlias(alias *aliasMeta) error {
+	op, err := aliasPutOperation(alias)
+	if err != nil {
+		return err
+	}
+
+	if err = c.aliasStore.Put(op.Key, op.Value, op.Tags...); err != nil {
+		return fmt.Errorf("put: %w", err)
+	}
+
+	return nil
+}
+
+// getAlias returns the alias. Returns storage.ErrDataNotFound if the alias doesn't exist.
+func (c *Command) getAlias(keyStoreID, name string) (*aliasMeta, error) {
+	b, err := c.aliasStore.Get(aliasID(keyStoreID, name))
+	if err != nil {
+		return nil, err
+	}
+
+	var alias aliasMeta
+
+	if err = json.Unmarshal(b, &alias); err != nil {
+		return nil, fmt.Errorf("unmarshal alias: %w", err)
+	}
+
+	return &alias, nil
+}
+
+// findAlias returns the alias or ErrNotFound if the alias doesn't exist.
+func (c *Command) findAlias(keyStoreID, name string) (*aliasMeta, error) {
+	alias, err := c.getAlias(keyStoreID, name)
+	if err != nil {
+		if goerrors.Is(err, storage.ErrDataNotFound) {
+			return nil, fmt.Errorf("%w: alias not found", errors.ErrNotFound)
+		}
+
+		return nil, fmt.Errorf("get alias: %w", err)
+	}
+
+	return alias, nil
+}
+
+// queryAliases returns aliases that satisfy the expression sorted by name.
+func (c *Command) queryAliases(expression string) ([]*aliasMeta, error) {
+	iter, err := c.aliasStore.Query(expression)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer iter.Close() // nolint: errcheck
+
+	var aliases []*aliasMeta
+
+	for {
+		ok, nextErr := iter.Next()
+		if nextErr != nil {
+			return nil, fmt.Errorf("iterator next: %w", nextErr)
+		}
+
+		if !ok {
+			break
+		}
+
+		b, valueErr := iter.Value()
+		if valueErr != nil {
+			return nil, fmt.Errorf("iterator value: %w", valueErr)
+		}
+
+		var alias aliasMeta
+
+		if err = json.Unmarshal(b, &alias); err != nil {
+			return nil, fmt.Errorf("unmarshal alias: %w", err)
+		}
+
+		aliases = append(aliases, &alias)
+	}
+
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+
+	return aliases, nil
+}
@@ -0,0 +1,507 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+	zcapldsvc "github.com/trustbloc/kms/pkg/zcapld"
+)
+
+// keyStoreBackupVersion is a version of the key store backup contents. Restore rejects backups of other versions.
+const keyStoreBackupVersion = 1
+
+// keyStoreBackup is the signed contents of a key store backup. Keysets are saved as stored, so no secret is exposed:
+// user's keysets stay encrypted under the key store's secret lock and server keys under the server's secret lock.
+type keyStoreBackup struct {
+	Version    int               `json:"version"`
+	CreatedAt  time.Time         `json:"created_at"`
+	KeyStore   *keyStoreMeta     `json:"key_store"`
+	Keys       []*keyMeta        `json:"keys,omitempty"`
+	Aliases    []*aliasMeta      `json:"aliases,omitempty"`
+	Keysets    map[string][]byte `json:"keysets,omitempty"`        // user's keysets by key ID
+	ServerKeys map[string][]byte `json:"server_keysets,omitempty"` // main key and EDV keys by key ID
+	ZCAPs      []json.RawMessage `json:"zcaps,omitempty"`
+}
+
+// BackupKeyStore creates a signed archive of the key store with its metadata, keysets, key metadata, aliases and
+// root ZCAP. The archive is signed with the server's backup signing key identified by did:key.
+func (c *Command) BackupKeyStore(w io.Writer, r io.Reader) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if c.backupSigningKey == nil {
+		return fmt.Errorf("%w: backup signing key is not configured", errors.ErrBadRequest)
+	}
+
+	meta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	backup, err := c.backupKeyStore(meta)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(backup)
+	if err != nil {
+		return fmt.Errorf("marshal backup: %w", err)
+	}
+
+	return json.NewEncoder(w).Encode(KeyStoreBackup{
+		Payload:   payload,
+		Signer:    c.backupSigner,
+		Signature: ed25519.Sign(c.backupSigningKey, payload),
+	})
+}
+
+// backupSigners returns did:key of the backup signing key and public keys of trusted backup signers by did:key.
+// Backups signed with the server's own signing key are trusted.
+func backupSigners(signingKey ed25519.PrivateKey, trusted []string) (string, map[string]ed25519.PublicKey, error) {
+	signers := make(map[string]ed25519.PublicKey, len(trusted)+1)
+
+	for _, did := range trusted {
+		pub, code, err := fingerprint.PubKeyFromFingerprint(strings.TrimPrefix(did, "did:key:"))
+		if err != nil || !strings.HasPrefix(did, "did:key:") || code != fingerprint.ED25519PubKeyMultiCodec ||
+			len(pub) != ed25519.PublicKeySize {
+			return "", nil, fmt.Errorf("invalid backup signer %s: not an Ed25519 did:key", did)
+		}
+
+		signers[did] = pub
+	}
+
+	if signingKey == nil {
+		return "", signers, nil
+	}
+
+	pub, ok := signingKey.Public().(ed25519.PublicKey)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid backup signing key")
+	}
+
+	did, _ := fingerprint.CreateDIDKey(pub)
+	signers[did] = pub
+
+	return did, signers, nil
+}
+
+func (c *Command) backupKeyStore(meta *keyStoreMeta) (*keyStoreBackup, error) {
+	keys, err := c.listKeyMeta(meta.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list key metadata: %w", err)
+	}
+
+	aliases, err := c.queryAliases(fmt.Sprintf("%s:%s", keyStoreIDTagName, meta.ID))
+	if err != nil {
+		return nil, fmt.Errorf("query aliases: %w", err)
+	}
+
+	keysets, err := c.backupKeysets(meta, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	serverKeys, err := c.backupServerKeys(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &keyStoreBackup{
+		Version:    keyStoreBackupVersion,
+		CreatedAt:  time.Now().UTC(),
+		KeyStore:   meta,
+		Keys:       keys,
+		Aliases:    aliases,
+		Keysets:    keysets,
+		ServerKeys: serverKeys,
+	}
+
+	if c.enableZCAPs {
+		capability, resolveErr := c.zcap.Resolve(c.baseKeyStoreURL + "/" + meta.ID)
+		if resolveErr != nil {
+			return nil, fmt.Errorf("resolve root zcap: %w", resolveErr)
+		}
+
+		b, marshalErr := json.Marshal(capability)
+		if marshalErr != nil {
+			return nil, fmt.Errorf("marshal root zcap: %w", marshalErr)
+		}
+
+		backup.ZCAPs = append(backup.ZCAPs, b)
+	}
+
+	return backup, nil
+}
+
+// backupKeysets returns user's keysets of the keys as stored in local storage or EDV. Imported public keys have no
+// keyset.
+func (c *Command) backupKeysets(meta *keyStoreMeta, keys []*keyMeta) (map[string][]byte, error) {
+	keysets := make(map[string][]byte, len(keys))
+
+	if len(keys) == 0 {
+		return keysets, nil
+	}
+
+	kmsStore, err := c.openKMSStore(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, km := range keys {
+		if km.PublicKey != nil {
+			continue
+		}
+
+		b, getErr := kmsStore.Get(km.ID)
+		if getErr != nil {
+			return nil, fmt.Errorf("get keyset %s: %w", km.ID, getErr)
+		}
+
+		keysets[km.ID] = b
+	}
+
+	return keysets, nil
+}
+
+// backupServerKeys returns keysets of the main key and EDV keys as stored in the server's storage.
+func (c *Command) backupServerKeys(meta *keyStoreMeta) (map[string][]byte, error) {
+	serverKeys := make(map[string][]byte)
+
+	kmsStore, err := kms.NewAriesProviderWrapper(c.storageProvider)
+	if err != nil {
+		return nil, fmt.Errorf("open kms db: %w", err)
+	}
+
+	for _, kid := range meta.serverKeyIDs() {
+		b, getErr := kmsStore.Get(kid)
+		if getErr != nil {
+			return nil, fmt.Errorf("get server key %s: %w", kid, getErr)
+		}
+
+		serverKeys[kid] = b
+	}
+
+	return serverKeys, nil
+}
+
+// RestoreKeyStore restores the key store from the signed archive created by BackupKeyStore. The archive must be signed
+// by one of the trusted backup signers. The key store keeps its ID, so it can't be restored while it exists, and none
+// of its keys may exist on the server. Restored entries are removed if restore fails, so a failed restore can be
+// retried. Restoring into another instance requires the same server's secret lock if the key store uses server keys
+// (key-based secret lock or EDV).
+func (c *Command) RestoreKeyStore(w io.Writer, r io.Reader) error {
+	var req RestoreKeyStoreRequest
+
+	if _, err := unwrapRequest(&req, r); err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	backup, err := c.verifyBackup(req.Backup)
+	if err != nil {
+		return err
+	}
+
+	meta := backup.KeyStore
+
+	_, err = c.getKeyStoreMeta(meta.ID)
+	if err == nil {
+		return fmt.Errorf("%w: key store already exists", errors.ErrConflict)
+	}
+
+	if !goerrors.Is(err, storage.ErrDataNotFound) {
+		return err
+	}
+
+	if err = c.checkRestoredKeysNotExist(backup); err != nil {
+		return err
+	}
+
+	resp, err := c.restoreKeyStore(backup)
+	if err != nil {
+		c.rollbackRestore(backup)
+
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func (c *Command) restoreKeyStore(backup *keyStoreBackup) (*RestoreKeyStoreResponse, error) {
+	if err := c.restoreKeys(backup); err != nil {
+		return nil, err
+	}
+
+	meta := backup.KeyStore
+	keyStoreURL := c.baseKeyStoreURL + "/" + meta.ID
+
+	var (
+		rootCapability []byte
+		err            error
+	)
+
+	if c.enableZCAPs {
+		rootCapability, err = c.restoreRootZCAP(keyStoreURL, meta.Controller, backup.ZCAPs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = c.save(meta); err != nil {
+		return nil, fmt.Errorf("save key store metadata: %w", err)
+	}
+
+	return &RestoreKeyStoreResponse{
+		KeyStoreURL: keyStoreURL,
+		Capability:  rootCapability,
+	}, nil
+}
+
+// verifyBackup checks that the archive is signed by a trusted backup signer and returns its contents. Server keys in
+// the backup are limited to those the key store references.
+func (c *Command) verifyBackup(archive *KeyStoreBackup) (*keyStoreBackup, error) {
+	pub, ok := c.backupTrustedSigners[archive.Signer]
+	if !ok {
+		return nil, fmt.Errorf("%w: backup signer is not trusted", errors.ErrForbidden)
+	}
+
+	if !ed25519.Verify(pub, archive.Payload, archive.Signature) {
+		return nil, fmt.Errorf("%w: invalid backup signature", errors.ErrBadRequest)
+	}
+
+	var backup keyStoreBackup
+
+	if err := json.Unmarshal(archive.Payload, &backup); err != nil {
+		return nil, fmt.Errorf("%w: unmarshal backup", errors.ErrBadRequest)
+	}
+
+	if backup.Version != keyStoreBackupVersion {
+		return nil, fmt.Errorf("%w: unsupported backup version %d", errors.ErrBadRequest, backup.Version)
+	}
+
+	if backup.KeyStore == nil || backup.KeyStore.ID == "" {
+		return nil, fmt.Errorf("%w: backup has no key store", errors.ErrBadRequest)
+	}
+
+	serverKeyIDs := backup.KeyStore.serverKeyIDs()
+
+	for kid := range backup.ServerKeys {
+		if !containsString(serverKeyIDs, kid) {
+			return nil, fmt.Errorf("%w: server key %s is not referenced by the key store", errors.ErrBadRequest, kid)
+		}
+	}
+
+	for _, kid := range serverKeyIDs {
+		if _, ok = backup.ServerKeys[kid]; !ok {
+			return nil, fmt.Errorf("%w: backup has no server key %s", errors.ErrBadRequest, kid)
+		}
+	}
+
+	return &backup, nil
+}
+
+// serverKeyIDs returns IDs of the main key and EDV keys of the key store kept in the server's key manager.
+func (m *keyStoreMeta) serverKeyIDs() []string {
+	var ids []string
+
+	for _, kid := range []string{m.MainKeyID, m.EDV.RecipientKeyID, m.EDV.MACKeyID} {
+		if kid != "" {
+			ids = append(ids, kid)
+		}
+	}
+
+	return ids
+}
+
+// checkRestoredKeysNotExist returns an error if any server key, keyset or key metadata of the backup already exists,
+// so that restore never overwrites keys of other key stores.
+func (c *Command) checkRestoredKeysNotExist(backup *keyStoreBackup) error {
+	serverStore, err := kms.NewAriesProviderWrapper(c.storageProvider)
+	if err != nil {
+		return fmt.Errorf("open kms db: %w", err)
+	}
+
+	for kid := range backup.ServerKeys {
+		if _, err = serverStore.Get(kid); err == nil {
+			return fmt.Errorf("%w: server key %s already exists", errors.ErrConflict, kid)
+		} else if !goerrors.Is(err, kms.ErrKeyNotFound) {
+			return fmt.Errorf("get server key %s: %w", kid, err)
+		}
+	}
+
+	for _, meta := range backup.Keys {
+		if _, err = c.getKeyMeta(backup.KeyStore.ID, meta.ID); err == nil {
+			return fmt.Errorf("%w: key %s already exists", errors.ErrConflict, meta.ID)
+		} else if !goerrors.Is(err, storage.ErrDataNotFound) {
+			return fmt.Errorf("get key metadata: %w", err)
+		}
+	}
+
+	if len(backup.Keysets) == 0 {
+		return nil
+	}
+
+	kmsStore, err := c.openKMSStore(backup.KeyStore)
+	if err != nil {
+		return err
+	}
+
+	for kid := range backup.Keysets {
+		if _, err = kmsStore.Get(kid); err == nil {
+			return fmt.Errorf("%w: key %s already exists", errors.ErrConflict, kid)
+		} else if !goerrors.Is(err, kms.ErrKeyNotFound) {
+			return fmt.Errorf("get keyset %s: %w", kid, err)
+		}
+	}
+
+	return nil
+}
+
+// rollbackRestore removes server keys, keysets, key metadata and aliases saved by a failed restore. Errors are logged
+// as the restore error is returned to the caller.
+func (c *Command) rollbackRestore(backup *keyStoreBackup) {
+	ksID := backup.KeyStore.ID
+
+	if serverStore, err := kms.NewAriesProviderWrapper(c.storageProvider); err == nil {
+		for kid := range backup.ServerKeys {
+			if err = serverStore.Delete(kid); err != nil {
+				logger.Warnf("restore key store: delete server key %s: %v", kid, err)
+			}
+		}
+	}
+
+	if kmsStore, err := c.openKMSStore(backup.KeyStore); err == nil {
+		for kid := range backup.Keysets {
+			if err = kmsStore.Delete(kid); err != nil {
+				logger.Warnf("restore key store: delete keyset %s: %v", kid, err)
+			}
+		}
+	}
+
+	for _, meta := range backup.Keys {
+		if err := c.keyMetaStore.Delete(keyMetaID(ksID, meta.ID)); err != nil {
+			logger.Warnf("restore key store: delete key metadata %s: %v", meta.ID, err)
+		}
+	}
+
+	for _, alias := range backup.Aliases {
+		if err := c.aliasStore.Delete(aliasID(ksID, alias.Name)); err != nil {
+			logger.Warnf("restore key store: delete alias %s: %v", alias.Name, err)
+		}
+	}
+}
+
+// restoreKeys saves server keys, user's keysets, key metadata and aliases from the backup. Server keys are restored
+// first as they are needed for opening EDV-backed key stores.
+func (c *Command) restoreKeys(backup *keyStoreBackup) error {
+	serverStore, err := kms.NewAriesProviderWrapper(c.storageProvider)
+	if err != nil {
+		return fmt.Errorf("open kms db: %w", err)
+	}
+
+	for kid, b := range backup.ServerKeys {
+		if err = serverStore.Put(kid, b); err != nil {
+			return fmt.Errorf("put server key %s: %w", kid, err)
+		}
+	}
+
+	if len(backup.Keysets) > 0 {
+		kmsStore, openErr := c.openKMSStore(backup.KeyStore)
+		if openErr != nil {
+			return openErr
+		}
+
+		for kid, b := range backup.Keysets {
+			if err = kmsStore.Put(kid, b); err != nil {
+				return fmt.Errorf("put keyset %s: %w", kid, err)
+			}
+		}
+	}
+
+	for _, meta := range backup.Keys {
+		meta.KeyStoreID = backup.KeyStore.ID
+
+		if err = c.saveKeyMeta(meta); err != nil {
+			return fmt.Errorf("save key metadata: %w", err)
+		}
+	}
+
+	if len(backup.Aliases) == 0 {
+		return nil
+	}
+
+	ops := make([]storage.Operation, 0, len(backup.Aliases))
+
+	for _, alias := range backup.Aliases {
+		alias.KeyStoreID = backup.KeyStore.ID
+
+		op, opErr := aliasPutOperation(alias)
+		if opErr != nil {
+			return opErr
+		}
+
+		ops = append(ops, op)
+	}
+
+	if err = c.aliasStore.Batch(ops); err != nil {
+		return fmt.Errorf("save aliases: %w", err)
+	}
+
+	return nil
+}
+
+// restoreRootZCAP saves the root ZCAP from the backup and returns it compressed. A new root ZCAP is created if the
+// backup was made on the server with another base key store URL.
+func (c *Command) restoreRootZCAP(keyStoreURL, controller string, zcaps []json.RawMessage) ([]byte, error) {
+	for _, raw := range zcaps {
+		capability, err := zcapld.ParseCapability(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parse zcap", errors.ErrBadRequest)
+		}
+
+		if capability.ID != keyStoreURL {
+			continue
+		}
+
+		if err = c.zcap.Save(capability); err != nil {
+			return nil, fmt.Errorf("save root zcap: %w", err)
+		}
+
+		compressed, err := zcapldsvc.CompressZCAP(capability)
+		if err != nil {
+			return nil, fmt.Errorf("compress zcap: %w", err)
+		}
+
+		return compressed, nil
+	}
+
+	compressed, err := c.newCompressedZCAP(context.Background(), keyStoreURL, controller)
+	if err != nil {
+		return nil, fmt.Errorf("new compressed zcap: %w", err)
+	}
+
+	return compressed, nil
+}
@@ -0,0 +1,227 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const maxBatchOperations = 1000
+
+// batchOperation executes the crypto operation with the key handle. Request is a JSON of the operation's request.
+type batchOperation func(c *Command, kh interface{}, req json.RawMessage) (interface{}, error)
+
+// batchOperations are crypto operations supported in batch by the command's action.
+var batchOperations = map[string]batchOperation{ //nolint:gochecknoglobals
+	ActionSign: func(c *Command, kh interface{}, b json.RawMessage) (interface{}, error) {
+		var req SignRequest
+
+		if err := decodeBatchRequest(b, &req); err != nil {
+			return nil, err
+		}
+
+		return c.sign(kh, &req)
+	},
+	ActionVerify: func(c *Command, kh interface{}, b json.RawMessage) (interface{}, error) {
+		var req VerifyRequest
+
+		if err := decodeBatchRequest(b, &req); err != nil {
+			return nil, err
+		}
+
+		return nil, c.verify(kh, &req)
+	},
+	ActionEncrypt: func(c *Command, kh interface{}, b json.RawMessage) (interface{}, error) {
+		var req EncryptRequest
+
+		if err := decodeBatchRequest(b, &req); err != nil {
+			return nil, err
+		}
+
+		return c.encrypt(kh, &req)
+	},
+	ActionDecrypt: func(c *Command, kh interface{}, b json.RawMessage) (interface{}, error) {
+		var req DecryptRequest
+
+		if err := decodeBatchRequest(b, &req); err != nil {
+			return nil, err
+		}
+
+		return c.decrypt(kh, &req)
+	},
+	ActionComputeMac: func(c *Command, kh interface{}, b json.RawMessage) (interface{}, error) {
+		var req ComputeMACRequest
+
+		if err := decodeBatchRequest(b, &req); err != nil {
+			return nil, err
+		}
+
+		return c.computeMAC(kh, &req)
+	},
+	ActionSignMulti: func(c *Command, kh interface{}, b json.RawMessage) (interface{}, error) {
+		var req SignMultiRequest
+
+		if err := decodeBatchRequest(b, &req); err != nil {
+			return nil, err
+		}
+
+		return c.signMulti(kh, &req)
+	},
+}
+
+// Batch executes crypto operations with keys of one key store. The key store is resolved once and each key is fetched
+// once for the whole batch. Operations are executed in order, or concurrently if requested. A failed operation doesn't
+// stop the batch: results and errors are returned per operation in the order of the request. The batch is rejected if
+// the invoked capability doesn't allow the action of any of its operations.
+func (c *Command) Batch(w io.Writer, r io.Reader) error {
+	var req BatchRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	for _, op := range req.Operations {
+		if !capabilityAllows(wr.Capability, op.Operation) {
+			return fmt.Errorf("%w: action %s is not allowed by the capability", errors.ErrForbidden, op.Operation)
+		}
+	}
+
+	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
+	if err != nil {
+		return fmt.Errorf("resolve key store: %w", err)
+	}
+
+	results := make([]BatchResult, len(req.Operations))
+	handles := make([]interface{}, len(req.Operations))
+	keys := make(map[string]interface{})
+
+	// keys are resolved sequentially, so each of them is fetched from the key store once
+	for i, op := range req.Operations {
+		handles[i], err = c.getBatchKeyHandle(ks, keys, wr.KeyStoreID, op)
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+
+	execute := func(i int) {
+		if handles[i] == nil {
+			return
+		}
+
+		results[i] = c.executeBatchOperation(handles[i], req.Operations[i])
+	}
+
+	if req.Parallel {
+		executeParallel(len(req.Operations), execute)
+	} else {
+		for i := range req.Operations {
+			execute(i)
+		}
+	}
+
+	return json.NewEncoder(w).Encode(BatchResponse{Results: results})
+}
+
+// capabilityAllows returns true if the invoked capability allows the action. Requests not authorized with ZCAP and
+// capabilities without allowed actions are not restricted.
+func capabilityAllows(capability *InvokedCapability, action string) bool {
+	return capability == nil || len(capability.AllowedActions) == 0 || containsString(capability.AllowedActions, action)
+}
+
+// getBatchKeyHandle returns a handle of the key for the batch operation. Fetched handles are kept in keys by key ID.
+func (c *Command) getBatchKeyHandle(ks kms.KeyManager, keys map[string]interface{}, keyStoreID string,
+	op BatchOperation) (interface{}, error) {
+	wr := &WrappedRequest{KeyStoreID: keyStoreID, KeyID: op.KeyID}
+
+	if err := c.resolveKeyID(wr); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkKeyUsable(keyStoreID, wr.KeyID, op.Operation); err != nil {
+		return nil, err
+	}
+
+	if kh, ok := keys[wr.KeyID]; ok {
+		return kh, nil
+	}
+
+	getStartTime := time.Now()
+
+	kh, err := c.getKey(ks, keyStoreID, wr.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get key: %w", err)
+	}
+
+	c.metrics.KeyStoreGetKeyTime(time.Since(getStartTime))
+
+	keys[wr.KeyID] = kh
+
+	return kh, nil
+}
+
+func (c *Command) executeBatchOperation(kh interface{}, op BatchOperation) BatchResult {
+	resp, err := batchOperations[op.Operation](c, kh, op.Request)
+	if err != nil {
+		return BatchResult{Error: err.Error()}
+	}
+
+	if resp == nil {
+		return BatchResult{}
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return BatchResult{Error: fmt.Sprintf("marshal result: %s", err)}
+	}
+
+	return BatchResult{Result: b}
+}
+
+// executeParallel calls fn for indexes from 0 to n-1 with at most GOMAXPROCS concurrent calls.
+func executeParallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			fn(i)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func decodeBatchRequest(b json.RawMessage, req interface{}) error {
+	if err := json.Unmarshal(b, req); err != nil {
+		return fmt.Errorf("%w: decode request", errors.ErrBadRequest)
+	}
+
+	return nil
+}
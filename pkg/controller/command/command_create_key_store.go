@@ -31,13 +31,36 @@ const (
 	localKeyURIPrefix = "local-lock://"
 )
 
+// keyStoreStatus is a status of user's key store.
+type keyStoreStatus string
+
+const (
+	keyStoreStatusActive      keyStoreStatus = "active"
+	keyStoreStatusDeactivated keyStoreStatus = "deactivated"
+)
+
 // keyStoreMeta is metadata about user's key store saved in the underlying storage.
 type keyStoreMeta struct {
-	ID         string        `json:"id"`
-	Controller string        `json:"controller"`
-	MainKeyID  string        `json:"main_key_id"`
-	EDV        edvParameters `json:"edv,omitempty"`
-	CreatedAt  time.Time     `json:"created_at"`
+	ID         string         `json:"id"`
+	Controller string         `json:"controller"`
+	MainKeyID  string         `json:"main_key_id"`
+	EDV        edvParameters  `json:"edv,omitempty"`
+	Status     keyStoreStatus `json:"status,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+func (m *keyStoreMeta) status() keyStoreStatus {
+	if m.Status == "" {
+		return keyStoreStatusActive
+	}
+
+	return m.Status
+}
+
+// mayHaveKeysWithoutMeta reports whether the key store may have keys created before key metadata was introduced.
+// Such keys aren't listed and their keysets can't be enumerated. Key stores of that time have no creation time.
+func (m *keyStoreMeta) mayHaveKeysWithoutMeta() bool {
+	return m.CreatedAt.IsZero()
 }
 
 type edvParameters struct {
@@ -91,6 +114,7 @@ func (c *Command) CreateKeyStore(w io.Writer, r io.Reader) error { //nolint:funl
 		Controller: req.Controller,
 		MainKeyID:  mainKeyID,
 		EDV:        edvParams,
+		Status:     keyStoreStatusActive,
 		CreatedAt:  time.Now().UTC(),
 	}
 
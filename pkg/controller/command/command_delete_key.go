@@ -0,0 +1,198 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	pendingDeletionTagName = "pendingDeletion"
+	maxPendingWindowDays   = 30
+	keyStatusDeleted       = "deleted"
+)
+
+// DeleteKey deletes a key from the key store. If pending window is set, the key is disabled immediately and
+// destroyed by PurgeExpiredKeys after the window ends unless the deletion is cancelled.
+func (c *Command) DeleteKey(w io.Writer, r io.Reader) error {
+	var req DeleteKeyRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	meta, err := c.findKeyMeta(wr.KeyStoreID, wr.KeyID)
+	if err != nil {
+		return err
+	}
+
+	if req.PendingWindowDays == 0 {
+		if err = c.destroyKey(meta); err != nil {
+			return fmt.Errorf("destroy key: %w", err)
+		}
+
+		return json.NewEncoder(w).Encode(DeleteKeyResponse{Status: keyStatusDeleted})
+	}
+
+	deletionTime := time.Now().UTC().AddDate(0, 0, req.PendingWindowDays)
+
+	meta.Status = keyStatusPendingDeletion
+	meta.DeletionTime = &deletionTime
+
+	if err = c.saveKeyMeta(meta); err != nil {
+		return fmt.Errorf("save key metadata: %w", err)
+	}
+
+	return json.NewEncoder(w).Encode(DeleteKeyResponse{
+		Status:       string(meta.Status),
+		DeletionTime: meta.DeletionTime,
+	})
+}
+
+// CancelKeyDeletion cancels scheduled deletion of a key and enables the key.
+func (c *Command) CancelKeyDeletion(_ io.Writer, r io.Reader) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
+	meta, err := c.findKeyMeta(wr.KeyStoreID, wr.KeyID)
+	if err != nil {
+		return err
+	}
+
+	if meta.status() != keyStatusPendingDeletion {
+		return fmt.Errorf("%w: key is not pending deletion", errors.ErrConflict)
+	}
+
+	meta.Status = keyStatusEnabled
+	meta.DeletionTime = nil
+
+	if err = c.saveKeyMeta(meta); err != nil {
+		return fmt.Errorf("save key metadata: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeExpiredKeys destroys keys whose pending deletion window has ended. It returns the number of destroyed keys and
+// the number of keys that failed to be destroyed. Each failure is logged; failed keys are retried on the next call.
+func (c *Command) PurgeExpiredKeys() (purged, failed int, err error) {
+	metas, err := c.queryKeyMeta(pendingDeletionTagName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("query key metadata: %w", err)
+	}
+
+	now := time.Now()
+
+	for _, meta := range metas {
+		if meta.DeletionTime == nil || meta.DeletionTime.After(now) {
+			continue
+		}
+
+		if destroyErr := c.destroyKey(meta); destroyErr != nil {
+			logger.Errorf("purge expired keys: destroy key %s in key store %s: %v", meta.ID, meta.KeyStoreID,
+				destroyErr)
+
+			failed++
+
+			continue
+		}
+
+		purged++
+	}
+
+	return purged, failed, nil
+}
+
+// destroyKey removes the keyset from user's key storage (local or EDV) and deletes key metadata.
+func (c *Command) destroyKey(meta *keyMeta) error {
+	ksMeta, err := c.getKeyStoreMeta(meta.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	kmsStore, err := c.openKMSStore(ksMeta)
+	if err != nil {
+		return err
+	}
+
+	return c.removeKey(kmsStore, meta)
+}
+
+func (c *Command) removeKey(kmsStore kms.Store, meta *keyMeta) error {
+	if err := kmsStore.Delete(meta.ID); err != nil && !goerrors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("delete keyset: %w", err)
+	}
+
+	if err := c.keyMetaStore.Delete(keyMetaID(meta.KeyStoreID, meta.ID)); err != nil {
+		return fmt.Errorf("delete key metadata: %w", err)
+	}
+
+	if err := c.deleteAliases(fmt.Sprintf("%s:%s", aliasKeyIDTagName, keyMetaID(meta.KeyStoreID, meta.ID))); err != nil {
+		return fmt.Errorf("delete aliases: %w", err)
+	}
+
+	return nil
+}
+
+// checkKeyUsable returns an error if the key can't be used for crypto operations or the operation is not allowed for
+// the key at the moment. Empty operation is not checked.
+func (c *Command) checkKeyUsable(keyStoreID, keyID, operation string) error {
+	meta, err := c.getKeyMeta(keyStoreID, keyID)
+	if err != nil {
+		if goerrors.Is(err, storage.ErrDataNotFound) { // keys created before key metadata was introduced
+			return nil
+		}
+
+		return fmt.Errorf("get key metadata: %w", err)
+	}
+
+	switch meta.status() { //nolint:exhaustive
+	case keyStatusDisabled:
+		return fmt.Errorf("%w: key is disabled", errors.ErrConflict)
+	case keyStatusPendingDeletion:
+		return fmt.Errorf("%w: key is pending deletion", errors.ErrConflict)
+	}
+
+	if operation == "" {
+		return nil
+	}
+
+	if err = meta.checkKeyValidity(operation, c.keyExpiryGrace, time.Now()); err != nil {
+		return err
+	}
+
+	// crypto box keys are not restricted by key type, export of private key is controlled by the exportable flag
+	if operation == ActionEasy || operation == ActionExportPrivateKey {
+		return nil
+	}
+
+	return meta.checkKeyOperation(operation)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	goerrors "errors"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// DeleteKeyStore deletes the key store with all its keys. The key store is deactivated first, so in case of a failure
+// it stays unusable and the deletion can be retried.
+func (c *Command) DeleteKeyStore(_ io.Writer, r io.Reader) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	meta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	if meta.status() != keyStoreStatusDeactivated {
+		meta.Status = keyStoreStatusDeactivated
+
+		if err = c.save(meta); err != nil {
+			return fmt.Errorf("save key store metadata: %w", err)
+		}
+	}
+
+	c.invalidateKeyManager(wr.KeyStoreID, wr.User, wr.SecretShare)
+
+	if err = c.deleteKeys(meta); err != nil {
+		return fmt.Errorf("delete keys: %w", err)
+	}
+
+	if err = c.deleteAliases(fmt.Sprintf("%s:%s", keyStoreIDTagName, meta.ID)); err != nil {
+		return fmt.Errorf("delete aliases: %w", err)
+	}
+
+	if err = c.deleteServerKeys(meta.MainKeyID, meta.EDV.RecipientKeyID, meta.EDV.MACKeyID); err != nil {
+		return fmt.Errorf("delete server keys: %w", err)
+	}
+
+	if c.enableZCAPs {
+		if err = c.zcap.Revoke(c.baseKeyStoreURL + "/" + meta.ID); err != nil {
+			return fmt.Errorf("revoke root zcap: %w", err)
+		}
+	}
+
+	if err = c.store.Delete(meta.ID); err != nil {
+		return fmt.Errorf("delete key store metadata: %w", err)
+	}
+
+	return nil
+}
+
+// DeactivateKeyStore deactivates the key store. Keys of the deactivated key store can't be used for crypto operations.
+func (c *Command) DeactivateKeyStore(_ io.Writer, r io.Reader) error {
+	return c.setKeyStoreStatus(r, keyStoreStatusDeactivated)
+}
+
+// ActivateKeyStore activates previously deactivated key store.
+func (c *Command) ActivateKeyStore(_ io.Writer, r io.Reader) error {
+	return c.setKeyStoreStatus(r, keyStoreStatusActive)
+}
+
+func (c *Command) setKeyStoreStatus(r io.Reader, status keyStoreStatus) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	meta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	if meta.status() == status {
+		return nil
+	}
+
+	meta.Status = status
+
+	if err = c.save(meta); err != nil {
+		return fmt.Errorf("save key store metadata: %w", err)
+	}
+
+	c.invalidateKeyManager(wr.KeyStoreID, wr.User, wr.SecretShare)
+
+	return nil
+}
+
+// findKeyStoreMeta returns metadata of the key store or ErrNotFound if the key store doesn't exist.
+func (c *Command) findKeyStoreMeta(keyStoreID string) (*keyStoreMeta, error) {
+	meta, err := c.getKeyStoreMeta(keyStoreID)
+	if err != nil {
+		if goerrors.Is(err, storage.ErrDataNotFound) {
+			return nil, fmt.Errorf("%w: key store not found", errors.ErrNotFound)
+		}
+
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// deleteKeys removes all user's keysets and key metadata of the key store.
+func (c *Command) deleteKeys(meta *keyStoreMeta) error {
+	metas, err := c.listKeyMeta(meta.ID)
+	if err != nil {
+		return fmt.Errorf("list key metadata: %w", err)
+	}
+
+	if len(metas) == 0 {
+		return nil
+	}
+
+	kmsStore, err := c.openKMSStore(meta)
+	if err != nil {
+		return err
+	}
+
+	for _, km := range metas {
+		if err = c.removeKey(kmsStore, km); err != nil {
+			return fmt.Errorf("remove key %s: %w", km.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// keyCacheInvalidator is implemented by key managers that cache keys, e.g. server's key manager wrapped with cache.
+type keyCacheInvalidator interface {
+	Invalidate(keyID string)
+}
+
+// deleteServerKeys removes keys created by server's key manager for the key store (main key and EDV keys). The keys
+// are deleted directly from the server's storage as kms.KeyManager doesn't support deletion, so keys cached by the
+// key manager are invalidated afterwards. Empty IDs are skipped.
+func (c *Command) deleteServerKeys(keyIDs ...string) error {
+	var kids []string
+
+	for _, kid := range keyIDs {
+		if kid != "" {
+			kids = append(kids, kid)
+		}
+	}
+
+	if len(kids) == 0 {
+		return nil
+	}
+
+	kmsStore, err := kms.NewAriesProviderWrapper(c.storageProvider)
+	if err != nil {
+		return fmt.Errorf("open kms db: %w", err)
+	}
+
+	for _, kid := range kids {
+		if err = kmsStore.Delete(kid); err != nil && !goerrors.Is(err, storage.ErrDataNotFound) {
+			return fmt.Errorf("delete key %s: %w", kid, err)
+		}
+
+		if invalidator, ok := c.kms.(keyCacheInvalidator); ok {
+			invalidator.Invalidate(kid)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// EnableKey enables previously disabled key.
+func (c *Command) EnableKey(_ io.Writer, r io.Reader) error {
+	return c.setKeyStatus(r, keyStatusEnabled)
+}
+
+// DisableKey disables a key. Disabled key stays in the key store but can't be used for crypto operations until it is
+// enabled again.
+func (c *Command) DisableKey(_ io.Writer, r io.Reader) error {
+	return c.setKeyStatus(r, keyStatusDisabled)
+}
+
+func (c *Command) setKeyStatus(r io.Reader, status keyStatus) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
+	meta, err := c.findKeyMeta(wr.KeyStoreID, wr.KeyID)
+	if err != nil {
+		return err
+	}
+
+	if meta.status() == keyStatusPendingDeletion {
+		return fmt.Errorf("%w: key is pending deletion", errors.ErrConflict)
+	}
+
+	if meta.status() == status {
+		return nil
+	}
+
+	meta.Status = status
+
+	if err = c.saveKeyMeta(meta); err != nil {
+		return fmt.Errorf("save key metadata: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose/jwk"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose/jwk/jwksupport"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// Formats of exported public keys.
+const (
+	FormatRaw       = "raw"       // public key bytes as exported by the key store, the meaning depends on the key type
+	FormatJWK       = "jwk"       // JSON Web Key with kid, alg and crv set
+	FormatPEM       = "pem"       // PEM encoded SubjectPublicKeyInfo
+	FormatMultibase = "multibase" // base58-btc multibase encoded multicodec public key
+	FormatDIDKey    = "didkey"    // did:key identifier
+)
+
+const jwkAlgorithmECDHESA256KW = "ECDH-ES+A256KW"
+
+//nolint:gochecknoglobals
+var exportFormats = []string{FormatRaw, FormatJWK, FormatPEM, FormatMultibase, FormatDIDKey}
+
+// jwkAlgorithms are JWA algorithms of public keys exported as JWK by key type.
+//
+//nolint:gochecknoglobals
+var jwkAlgorithms = map[kms.KeyType]string{
+	kms.ED25519Type:            "EdDSA",
+	kms.ECDSAP256TypeDER:       "ES256",
+	kms.ECDSAP256TypeIEEEP1363: "ES256",
+	kms.ECDSAP384TypeDER:       "ES384",
+	kms.ECDSAP384TypeIEEEP1363: "ES384",
+	kms.ECDSAP521TypeDER:       "ES512",
+	kms.ECDSAP521TypeIEEEP1363: "ES512",
+	kms.NISTP256ECDHKWType:     jwkAlgorithmECDHESA256KW,
+	kms.NISTP384ECDHKWType:     jwkAlgorithmECDHESA256KW,
+	kms.NISTP521ECDHKWType:     jwkAlgorithmECDHESA256KW,
+	kms.X25519ECDHKWType:       jwkAlgorithmECDHESA256KW,
+}
+
+// oidX25519 is the algorithm identifier of X25519 public keys (RFC 8410).
+//
+//nolint:gochecknoglobals
+var oidX25519 = asn1.ObjectIdentifier{1, 3, 101, 110}
+
+// formatPublicKey sets the public key of the response in the requested format. Public key bytes in the key store
+// format are kept in the response for all formats.
+func formatPublicKey(resp *ExportKeyResponse, keyID string, pub []byte, keyType kms.KeyType, format string) error {
+	if format == "" || format == FormatRaw {
+		return nil
+	}
+
+	j, err := publicKeyJWK(keyID, pub, keyType)
+	if err != nil {
+		return fmt.Errorf("%w: public key of type %s can't be exported as %s", errors.ErrBadRequest, keyType, format)
+	}
+
+	resp.Format = format
+
+	switch format {
+	case FormatJWK:
+		resp.JWK = j
+	case FormatPEM:
+		der, e := marshalPKIXPublicKey(j)
+		if e != nil {
+			return e
+		}
+
+		resp.PEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	case FormatMultibase, FormatDIDKey:
+		code, raw, e := multicodecPublicKey(j)
+		if e != nil {
+			return e
+		}
+
+		if format == FormatMultibase {
+			resp.Multibase = fingerprint.KeyFingerprint(code, raw)
+		} else {
+			resp.DIDKey, _ = fingerprint.CreateDIDKeyByCode(code, raw)
+		}
+	}
+
+	return nil
+}
+
+// publicKeyJWK converts public key bytes in the key store format to JWK with kid, alg and use set.
+func publicKeyJWK(keyID string, pub []byte, keyType kms.KeyType) (*jwk.JWK, error) {
+	if keyType == kms.X25519ECDHKWType { // X25519 public key is exported as marshalled crypto.PublicKey
+		var k crypto.PublicKey
+
+		if err := json.Unmarshal(pub, &k); err != nil {
+			return nil, fmt.Errorf("unmarshal x25519 public key: %w", err)
+		}
+
+		pub = k.X
+	}
+
+	j, err := jwksupport.PubKeyBytesToJWK(pub, keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	j.KeyID = keyID
+	j.Algorithm = jwkAlgorithms[keyType]
+
+	if j.Algorithm == jwkAlgorithmECDHESA256KW {
+		j.Use = "enc"
+	} else if j.Algorithm != "" {
+		j.Use = "sig"
+	}
+
+	return j, nil
+}
+
+// marshalPKIXPublicKey returns DER encoded SubjectPublicKeyInfo of the public key.
+func marshalPKIXPublicKey(j *jwk.JWK) ([]byte, error) {
+	switch k := j.Key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		return x509.MarshalPKIXPublicKey(k)
+	case []byte: // X25519
+		return asn1.Marshal(struct {
+			Algorithm pkix.AlgorithmIdentifier
+			PublicKey asn1.BitString
+		}{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidX25519},
+			PublicKey: asn1.BitString{Bytes: k, BitLength: len(k) * 8}, //nolint:gomnd
+		})
+	default:
+		return nil, fmt.Errorf("%w: pem format is not supported for %s keys", errors.ErrBadRequest, j.Crv)
+	}
+}
+
+// multicodecPublicKey returns multicodec code and raw bytes of the public key as used in did:key identifiers. EC keys
+// are in compressed form.
+func multicodecPublicKey(j *jwk.JWK) (uint64, []byte, error) {
+	switch k := j.Key.(type) {
+	case ed25519.PublicKey:
+		return fingerprint.ED25519PubKeyMultiCodec, k, nil
+	case []byte: // X25519
+		return fingerprint.X25519PubKeyMultiCodec, k, nil
+	case *bbs12381g2pub.PublicKey:
+		b, err := k.Marshal()
+		if err != nil {
+			return 0, nil, fmt.Errorf("marshal bbs public key: %w", err)
+		}
+
+		return fingerprint.BLS12381g2PubKeyMultiCodec, b, nil
+	case *ecdsa.PublicKey:
+		var code uint64
+
+		switch k.Curve {
+		case elliptic.P256():
+			code = fingerprint.P256PubKeyMultiCodec
+		case elliptic.P384():
+			code = fingerprint.P384PubKeyMultiCodec
+		case elliptic.P521():
+			code = fingerprint.P521PubKeyMultiCodec
+		default:
+			return 0, nil, fmt.Errorf("%w: unsupported curve %s", errors.ErrBadRequest, j.Crv)
+		}
+
+		return code, elliptic.MarshalCompressed(k.Curve, k.X, k.Y), nil
+	default:
+		return 0, nil, fmt.Errorf("%w: unsupported public key %T", errors.ErrBadRequest, k)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/google/tink/go/hybrid/subtle"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	jweMediaType             = "JWE"
+	privateKeysetContentType = "application/tink-keyset+json" // content type of the private keyset encrypted in JWE
+	x25519KeySize            = 32
+)
+
+var auditLogger = log.New("kms/audit")
+
+// ExportPrivateKey exports the private keyset of the key encrypted to the caller's transport public key as ECDH-ES
+// JWE. Only keys created as exportable can be exported. Every export attempt is audit logged.
+func (c *Command) ExportPrivateKey(w io.Writer, r io.Reader) error {
+	var req ExportPrivateKeyRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
+	jwe, err := c.exportPrivateKey(wr, req.TransportKey)
+	if err != nil {
+		auditLogger.Warnf("private key export denied: key_store_id=%s key_id=%s user=%s transport_kid=%s: %v",
+			wr.KeyStoreID, wr.KeyID, wr.User, req.TransportKey.KID, err)
+
+		return err
+	}
+
+	auditLogger.Infof("private key exported: key_store_id=%s key_id=%s user=%s transport_kid=%s",
+		wr.KeyStoreID, wr.KeyID, wr.User, req.TransportKey.KID)
+
+	return json.NewEncoder(w).Encode(ExportPrivateKeyResponse{JWE: jwe})
+}
+
+func (c *Command) exportPrivateKey(wr *WrappedRequest, transportKey *crypto.PublicKey) (string, error) {
+	meta, err := c.findKeyMeta(wr.KeyStoreID, wr.KeyID)
+	if err != nil {
+		return "", err
+	}
+
+	if !meta.Exportable {
+		return "", fmt.Errorf("%w: key is not exportable", errors.ErrBadRequest)
+	}
+
+	if err = validateTransportKey(transportKey); err != nil {
+		return "", err
+	}
+
+	kh, err := c.getKeyHandleFromRequest(wr, ActionExportPrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	if err = insecurecleartextkeyset.Write(kh.(*keyset.Handle), keyset.NewJSONWriter(&buf)); err != nil {
+		return "", fmt.Errorf("write keyset: %w", err)
+	}
+
+	enc, err := jose.NewJWEEncrypt(jose.A256GCM, jweMediaType, privateKeysetContentType, "", nil,
+		[]*crypto.PublicKey{transportKey}, c.crypto)
+	if err != nil {
+		return "", fmt.Errorf("create jwe encrypter: %w", err)
+	}
+
+	jwe, err := enc.Encrypt(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("%w: encrypt keyset to transport key: %s", errors.ErrBadRequest, err)
+	}
+
+	s, err := jwe.CompactSerialize(json.Marshal)
+	if err != nil {
+		return "", fmt.Errorf("serialize jwe: %w", err)
+	}
+
+	return s, nil
+}
+
+// validateTransportKey checks that the transport key is a valid EC or X25519 public key.
+func validateTransportKey(k *crypto.PublicKey) error {
+	if err := validateECDHPublicKey(k); err != nil {
+		return fmt.Errorf("%w: transport key: %s", errors.ErrBadRequest, err)
+	}
+
+	return nil
+}
+
+// validateECDHPublicKey checks that the key is a valid EC or X25519 public key. Key wrapping panics on EC points that
+// are not on the curve.
+func validateECDHPublicKey(k *crypto.PublicKey) error {
+	switch k.Type {
+	case "EC":
+		curve, err := subtle.GetCurve(k.Curve)
+		if err != nil {
+			return err
+		}
+
+		if !curve.IsOnCurve(new(big.Int).SetBytes(k.X), new(big.Int).SetBytes(k.Y)) {
+			return fmt.Errorf("point is not on curve %s", k.Curve)
+		}
+	case "OKP":
+		if len(k.X) != x25519KeySize {
+			return goerrors.New("invalid x25519 key size")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %q", k.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// GetKeyStore returns non-secret metadata of the key store.
+func (c *Command) GetKeyStore(w io.Writer, r io.Reader) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	meta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	resp := GetKeyStoreResponse{
+		ID:         meta.ID,
+		Controller: meta.Controller,
+		MainKeyID:  meta.MainKeyID,
+		Status:     string(meta.status()),
+		CreatedAt:  meta.CreatedAt,
+	}
+
+	if meta.EDV.VaultURL != "" {
+		resp.EDV = &EDVInfo{
+			VaultURL:       meta.EDV.VaultURL,
+			RecipientKeyID: meta.EDV.RecipientKeyID,
+			MACKeyID:       meta.EDV.MACKeyID,
+		}
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,411 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/mac"
+	gcmpb "github.com/google/tink/go/proto/aes_gcm_go_proto"
+	hmacpb "github.com/google/tink/go/proto/hmac_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/google/tink/go/subtle/random"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdh"
+	ecdhpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdh_aead_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose/jwk"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	minHMACKeySize     = 16
+	importedKeyIDBytes = 32
+)
+
+type (
+	// x25519PrivateKey is a raw X25519 private key (scalar).
+	x25519PrivateKey []byte
+	// symmetricKey is raw AES or HMAC key material.
+	symmetricKey []byte
+)
+
+// importedKeyTypes are key types that can be imported. Secp256k1 keys are not supported by the underlying key store.
+//
+//nolint:gochecknoglobals
+var importedKeyTypes = []kms.KeyType{
+	kms.ED25519Type,
+	kms.ECDSAP256TypeDER,
+	kms.ECDSAP384TypeDER,
+	kms.ECDSAP521TypeDER,
+	kms.ECDSAP256TypeIEEEP1363,
+	kms.ECDSAP384TypeIEEEP1363,
+	kms.ECDSAP521TypeIEEEP1363,
+	kms.NISTP256ECDHKWType,
+	kms.NISTP384ECDHKWType,
+	kms.NISTP521ECDHKWType,
+	kms.X25519ECDHKWType,
+	kms.BLS12381G2Type,
+	kms.AES128GCMType,
+	kms.AES256GCMType,
+	kms.AES256GCMNoPrefixType,
+	kms.HMACSHA256Tag256Type,
+}
+
+func isImportedKeyType(keyType kms.KeyType) bool {
+	for _, kt := range importedKeyTypes {
+		if kt == keyType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importPrivateKey imports the private key of the request into the key store.
+func (c *Command) importPrivateKey(ks kms.KeyManager, wr *WrappedRequest, req *ImportKeyRequest) (string, interface{},
+	error) {
+	if !isImportedKeyType(req.KeyType) {
+		return "", nil, fmt.Errorf("not supported key type: %s", req.KeyType)
+	}
+
+	privateKey, err := parsePrivateKey(req.Key, req.KeyType)
+	if err != nil {
+		return "", nil, fmt.Errorf("parse private key: %w", err)
+	}
+
+	var (
+		kid string
+		kh  interface{}
+	)
+
+	switch privateKey.(type) {
+	case x25519PrivateKey, symmetricKey: // not supported by ImportPrivateKey of the key store
+		keyURI, p, resolveErr := c.resolveKeyStoreProvider(wr.KeyStoreID, wr.User, wr.SecretShare)
+		if resolveErr != nil {
+			return "", nil, fmt.Errorf("resolve key store: %w", resolveErr)
+		}
+
+		kid, kh, err = importKeyMaterial(ks, p, keyURI, privateKey, req.KeyType, req.KeyID)
+	default:
+		var opts []kms.PrivateKeyOpts
+
+		if req.KeyID != "" {
+			opts = append(opts, kms.WithKeyID(req.KeyID))
+		}
+
+		kid, kh, err = ks.ImportPrivateKey(privateKey, req.KeyType, opts...)
+	}
+
+	if err != nil {
+		return "", nil, fmt.Errorf("import private key: %w", err)
+	}
+
+	return kid, kh, nil
+}
+
+// parsePrivateKey parses key material of the imported key. The key is either a JWK or, depending on the key type,
+// a PKCS#8 private key (ED25519, ECDSA and NIST P ECDH-KW keys), a raw private key (X25519 and BLS12381G2 keys) or
+// raw key material (AES and HMAC keys).
+func parsePrivateKey(key []byte, keyType kms.KeyType) (interface{}, error) {
+	if isJWK(key) {
+		return parseJWKPrivateKey(key)
+	}
+
+	switch keyType { //nolint:exhaustive
+	case kms.X25519ECDHKWType:
+		if len(key) != curve25519.ScalarSize {
+			return nil, fmt.Errorf("%w: invalid X25519 private key size", errors.ErrValidation)
+		}
+
+		return x25519PrivateKey(key), nil
+	case kms.BLS12381G2Type:
+		return bbs12381g2pub.UnmarshalPrivateKey(key)
+	case kms.AES128GCMType, kms.AES256GCMType, kms.AES256GCMNoPrefixType, kms.HMACSHA256Tag256Type:
+		return symmetricKey(key), nil
+	default:
+		return x509.ParsePKCS8PrivateKey(key)
+	}
+}
+
+func isJWK(key []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(key), []byte("{")) && json.Valid(key)
+}
+
+func parseJWKPrivateKey(key []byte) (interface{}, error) {
+	var raw struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		D   string `json:"d"`
+	}
+
+	if err := json.Unmarshal(key, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal jwk: %w", err)
+	}
+
+	// private part of X25519 JWK is not read by jwk.JWK
+	if raw.Kty == "OKP" && raw.Crv == "X25519" {
+		d, err := base64.RawURLEncoding.DecodeString(raw.D)
+		if err != nil {
+			return nil, fmt.Errorf("decode jwk private key: %w", err)
+		}
+
+		if len(d) != curve25519.ScalarSize {
+			return nil, fmt.Errorf("%w: jwk is not a valid X25519 private key", errors.ErrValidation)
+		}
+
+		return x25519PrivateKey(d), nil
+	}
+
+	var j jwk.JWK
+
+	if err := j.UnmarshalJSON(key); err != nil {
+		return nil, fmt.Errorf("unmarshal jwk: %w", err)
+	}
+
+	switch k := j.Key.(type) {
+	case ed25519.PrivateKey, *ecdsa.PrivateKey, *bbs12381g2pub.PrivateKey:
+		return k, nil
+	case []byte:
+		return symmetricKey(k), nil
+	default:
+		return nil, fmt.Errorf("%w: jwk is not a private key", errors.ErrValidation)
+	}
+}
+
+// importKeyMaterial imports keys of types not supported by ImportPrivateKey of the key store, i.e. X25519 and
+// symmetric keys. The keyset is put into the storage of the key store and read back with the key store, so a key the
+// key store can't read is removed and the import fails instead of leaving an unusable key.
+func importKeyMaterial(ks kms.KeyManager, p *keyStoreProvider, keyURI string, key interface{}, keyType kms.KeyType,
+	keyID string) (string, interface{}, error) {
+	kh, err := newKeysetHandle(keyType, key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	kid, err := importKeysetHandle(p, keyURI, kh, keyType, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	imported, err := ks.Get(kid)
+	if err != nil {
+		if delErr := p.storageProvider.Delete(kid); delErr != nil {
+			return "", nil, fmt.Errorf("delete unreadable keyset: %w", delErr)
+		}
+
+		return "", nil, fmt.Errorf("read imported keyset: %w", err)
+	}
+
+	return kid, imported, nil
+}
+
+// newKeysetHandle creates a keyset handle with key material of the given key type. Key types not supported by
+// ImportPrivateKey of the key store are imported this way: a key is generated from the key type template and its key
+// material is replaced with the imported one.
+func newKeysetHandle(keyType kms.KeyType, key interface{}) (*keyset.Handle, error) {
+	var template *tinkpb.KeyTemplate
+
+	switch keyType { //nolint:exhaustive
+	case kms.AES128GCMType:
+		template = aead.AES128GCMKeyTemplate()
+	case kms.AES256GCMType:
+		template = aead.AES256GCMKeyTemplate()
+	case kms.AES256GCMNoPrefixType:
+		template = aead.AES256GCMNoPrefixKeyTemplate()
+	case kms.HMACSHA256Tag256Type:
+		template = mac.HMACSHA256Tag256KeyTemplate()
+	case kms.X25519ECDHKWType:
+		template = ecdh.X25519ECDHKWKeyTemplate()
+	default:
+		return nil, fmt.Errorf("%w: key doesn't match key type %s", errors.ErrValidation, keyType)
+	}
+
+	kh, err := keyset.NewHandle(template)
+	if err != nil {
+		return nil, fmt.Errorf("new keyset handle: %w", err)
+	}
+
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+	keyData := ks.Key[0].KeyData
+
+	keyData.Value, err = replaceKeyMaterial(keyType, keyData.Value, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return insecurecleartextkeyset.Read(&keyset.MemReaderWriter{Keyset: ks})
+}
+
+func replaceKeyMaterial(keyType kms.KeyType, value []byte, key interface{}) ([]byte, error) {
+	var msg proto.Message
+
+	switch k := key.(type) {
+	case symmetricKey:
+		switch keyType { //nolint:exhaustive
+		case kms.HMACSHA256Tag256Type:
+			hmacKey := new(hmacpb.HmacKey)
+			if err := proto.Unmarshal(value, hmacKey); err != nil {
+				return nil, fmt.Errorf("unmarshal hmac key: %w", err)
+			}
+
+			if len(k) < minHMACKeySize {
+				return nil, fmt.Errorf("%w: HMAC key must be at least %d bytes", errors.ErrValidation, minHMACKeySize)
+			}
+
+			hmacKey.KeyValue = k
+			msg = hmacKey
+		case kms.AES128GCMType, kms.AES256GCMType, kms.AES256GCMNoPrefixType:
+			gcmKey := new(gcmpb.AesGcmKey)
+			if err := proto.Unmarshal(value, gcmKey); err != nil {
+				return nil, fmt.Errorf("unmarshal aes-gcm key: %w", err)
+			}
+
+			if len(k) != len(gcmKey.KeyValue) {
+				return nil, fmt.Errorf("%w: AES key must be %d bytes", errors.ErrValidation, len(gcmKey.KeyValue))
+			}
+
+			gcmKey.KeyValue = k
+			msg = gcmKey
+		default:
+			return nil, fmt.Errorf("%w: key doesn't match key type %s", errors.ErrValidation, keyType)
+		}
+	case x25519PrivateKey:
+		if keyType != kms.X25519ECDHKWType {
+			return nil, fmt.Errorf("%w: key doesn't match key type %s", errors.ErrValidation, keyType)
+		}
+
+		ecdhKey := new(ecdhpb.EcdhAeadPrivateKey)
+		if err := proto.Unmarshal(value, ecdhKey); err != nil {
+			return nil, fmt.Errorf("unmarshal ecdh key: %w", err)
+		}
+
+		pub, err := curve25519.X25519(k, curve25519.Basepoint)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid X25519 private key", errors.ErrValidation)
+		}
+
+		ecdhKey.KeyValue = k
+		ecdhKey.PublicKey.X = pub
+		msg = ecdhKey
+	default:
+		return nil, fmt.Errorf("%w: key doesn't match key type %s", errors.ErrValidation, keyType)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// importKeysetHandle saves the keyset into the key store in the same format the key store uses for its keys, so that
+// the key can be used as any other key of the key store. Asymmetric keys get an ID derived from the public key,
+// symmetric keys get a random ID.
+func importKeysetHandle(p *keyStoreProvider, keyURI string, kh *keyset.Handle, keyType kms.KeyType,
+	keyID string) (string, error) {
+	var err error
+
+	if keyID == "" {
+		keyID, err = newImportedKeyID(kh, keyType)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	_, err = p.storageProvider.Get(keyID)
+	if err == nil {
+		return "", fmt.Errorf("%w: key %s already exists", errors.ErrConflict, keyID)
+	}
+
+	if !goerrors.Is(err, kms.ErrKeyNotFound) {
+		return "", fmt.Errorf("get key: %w", err)
+	}
+
+	envAEAD := aead.NewKMSEnvelopeAEAD2(aead.AES256GCMKeyTemplate(), &secretLockAEAD{
+		keyURI:     strings.TrimPrefix(keyURI, localKeyURIPrefix),
+		secretLock: p.secretLock,
+	})
+
+	buf := new(bytes.Buffer)
+
+	if err = kh.Write(keyset.NewJSONWriter(buf), envAEAD); err != nil {
+		return "", fmt.Errorf("write keyset: %w", err)
+	}
+
+	if err = p.storageProvider.Put(keyID, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("put keyset: %w", err)
+	}
+
+	return keyID, nil
+}
+
+func newImportedKeyID(kh *keyset.Handle, keyType kms.KeyType) (string, error) {
+	if keyType != kms.X25519ECDHKWType {
+		return base64.RawURLEncoding.EncodeToString(random.GetRandomBytes(importedKeyIDBytes)), nil
+	}
+
+	pubKH, err := kh.Public()
+	if err != nil {
+		return "", fmt.Errorf("get public keyset handle: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err = pubKH.WriteWithNoSecrets(localkms.NewWriter(buf)); err != nil {
+		return "", fmt.Errorf("write public key: %w", err)
+	}
+
+	kid, err := localkms.CreateKID(buf.Bytes(), keyType)
+	if err != nil {
+		return "", fmt.Errorf("create kid: %w", err)
+	}
+
+	return kid, nil
+}
+
+// secretLockAEAD encrypts keysets with the secret lock of the key store, the same way the key store does it.
+type secretLockAEAD struct {
+	keyURI     string
+	secretLock secretlock.Service
+}
+
+func (a *secretLockAEAD) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	resp, err := a.secretLock.Encrypt(a.keyURI, &secretlock.EncryptRequest{
+		Plaintext:                   base64.URLEncoding.EncodeToString(plaintext),
+		AdditionalAuthenticatedData: base64.URLEncoding.EncodeToString(additionalData),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.URLEncoding.DecodeString(resp.Ciphertext)
+}
+
+func (a *secretLockAEAD) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	resp, err := a.secretLock.Decrypt(a.keyURI, &secretlock.DecryptRequest{
+		Ciphertext:                  base64.URLEncoding.EncodeToString(ciphertext),
+		AdditionalAuthenticatedData: base64.URLEncoding.EncodeToString(additionalData),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.URLEncoding.DecodeString(resp.Plaintext)
+}
@@ -0,0 +1,285 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	goerrors "errors"
+	"fmt"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose/jwk"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// x25519PublicKey is a raw X25519 public key.
+type x25519PublicKey []byte
+
+// publicKeyOperations are operations allowed for imported public keys by key type. Public keys are kept in key
+// metadata, as the key store holds private keysets only.
+//
+//nolint:gochecknoglobals
+var publicKeyOperations = map[kms.KeyType][]string{
+	kms.ED25519Type:            {ActionVerify},
+	kms.ECDSAP256TypeDER:       {ActionVerify},
+	kms.ECDSAP384TypeDER:       {ActionVerify},
+	kms.ECDSAP521TypeDER:       {ActionVerify},
+	kms.ECDSAP256TypeIEEEP1363: {ActionVerify},
+	kms.ECDSAP384TypeIEEEP1363: {ActionVerify},
+	kms.ECDSAP521TypeIEEEP1363: {ActionVerify},
+	kms.BLS12381G2Type:         {ActionVerifyMulti, ActionVerifyProof},
+	kms.NISTP256ECDHKWType:     {ActionWrap},
+	kms.NISTP384ECDHKWType:     {ActionWrap},
+	kms.NISTP521ECDHKWType:     {ActionWrap},
+	kms.X25519ECDHKWType:       {ActionWrap},
+}
+
+// ecdhCurveNames are curve names of NIST P ECDH-KW public keys exported by the key store.
+//
+//nolint:gochecknoglobals
+var ecdhCurveNames = map[kms.KeyType]string{
+	kms.NISTP256ECDHKWType: "NIST_P256",
+	kms.NISTP384ECDHKWType: "NIST_P384",
+	kms.NISTP521ECDHKWType: "NIST_P521",
+}
+
+// validatePublicKeyImport checks that the public key of the key type can be imported with the allowed operations.
+func validatePublicKeyImport(keyType kms.KeyType, operations []string, policy *RotationPolicy) error {
+	supported, ok := publicKeyOperations[keyType]
+	if !ok {
+		return fmt.Errorf("%w: public key of type %s can't be imported", errors.ErrValidation, keyType)
+	}
+
+	for _, op := range operations {
+		if !containsString(supported, op) {
+			return fmt.Errorf("%w: operation %q is not supported by public key", errors.ErrValidation, op)
+		}
+	}
+
+	if policy != nil {
+		return fmt.Errorf("%w: rotation policy can't be set for public key", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// importPublicKey parses the public key of the request and returns its ID and public key bytes in the format the key
+// store exports public keys of the key type. Key ID is derived from the public key if not set in the request.
+func (c *Command) importPublicKey(keyStoreID string, req *ImportKeyRequest) (string, []byte, error) {
+	pub, err := parsePublicKey(req.Key, req.KeyType)
+	if err != nil {
+		return "", nil, fmt.Errorf("parse public key: %w", err)
+	}
+
+	b, err := marshalPublicKey(pub, req.KeyType)
+	if err != nil {
+		return "", nil, fmt.Errorf("parse public key: %w", err)
+	}
+
+	kid := req.KeyID
+
+	if kid == "" {
+		kid, err = localkms.CreateKID(b, req.KeyType)
+		if err != nil {
+			return "", nil, fmt.Errorf("create kid: %w", err)
+		}
+	}
+
+	_, err = c.getKeyMeta(keyStoreID, kid)
+	if err == nil {
+		return "", nil, fmt.Errorf("%w: key %s already exists", errors.ErrConflict, kid)
+	}
+
+	if !goerrors.Is(err, storage.ErrDataNotFound) {
+		return "", nil, fmt.Errorf("get key metadata: %w", err)
+	}
+
+	return kid, b, nil
+}
+
+// parsePublicKey parses a public key given as a JWK, a PEM or DER encoded SPKI or raw public key bytes of the key
+// type (uncompressed point for ECDSA and NIST P ECDH-KW keys).
+func parsePublicKey(key []byte, keyType kms.KeyType) (interface{}, error) {
+	if isJWK(key) {
+		var j jwk.JWK
+
+		if err := j.UnmarshalJSON(key); err != nil {
+			return nil, fmt.Errorf("unmarshal jwk: %w", err)
+		}
+
+		if b, ok := j.Key.([]byte); ok && keyType == kms.X25519ECDHKWType {
+			return x25519PublicKey(b), nil
+		}
+
+		return j.Key, nil
+	}
+
+	if block, _ := pem.Decode(key); block != nil {
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	if pub, err := x509.ParsePKIXPublicKey(key); err == nil {
+		return pub, nil
+	}
+
+	switch keyType { //nolint:exhaustive
+	case kms.ED25519Type:
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid ED25519 public key size", errors.ErrValidation)
+		}
+
+		return ed25519.PublicKey(key), nil
+	case kms.X25519ECDHKWType:
+		if len(key) != curve25519.PointSize {
+			return nil, fmt.Errorf("%w: invalid X25519 public key size", errors.ErrValidation)
+		}
+
+		return x25519PublicKey(key), nil
+	case kms.BLS12381G2Type:
+		return bbs12381g2pub.UnmarshalPublicKey(key)
+	default:
+		curve := keyTypeCurve(keyType)
+
+		x, y := elliptic.Unmarshal(curve, key)
+		if x == nil {
+			return nil, fmt.Errorf("%w: invalid EC public key", errors.ErrValidation)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+}
+
+func marshalPublicKey(pub interface{}, keyType kms.KeyType) ([]byte, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		if keyType == kms.ED25519Type {
+			return k, nil
+		}
+	case x25519PublicKey:
+		if keyType == kms.X25519ECDHKWType {
+			return json.Marshal(&crypto.PublicKey{X: k, Curve: "X25519", Type: "OKP"})
+		}
+	case *bbs12381g2pub.PublicKey:
+		if keyType == kms.BLS12381G2Type {
+			return k.Marshal()
+		}
+	case *ecdsa.PublicKey:
+		if k.Curve == keyTypeCurve(keyType) {
+			return marshalECPublicKey(k, keyType)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: key doesn't match key type %s", errors.ErrValidation, keyType)
+}
+
+func marshalECPublicKey(pub *ecdsa.PublicKey, keyType kms.KeyType) ([]byte, error) {
+	switch keyType { //nolint:exhaustive
+	case kms.ECDSAP256TypeDER, kms.ECDSAP384TypeDER, kms.ECDSAP521TypeDER:
+		return x509.MarshalPKIXPublicKey(pub)
+	case kms.NISTP256ECDHKWType, kms.NISTP384ECDHKWType, kms.NISTP521ECDHKWType:
+		return json.Marshal(&crypto.PublicKey{
+			X:     pub.X.Bytes(),
+			Y:     pub.Y.Bytes(),
+			Curve: ecdhCurveNames[keyType],
+			Type:  "EC",
+		})
+	default:
+		return elliptic.Marshal(pub.Curve, pub.X, pub.Y), nil
+	}
+}
+
+// keyTypeCurve returns the elliptic curve of ECDSA and NIST P ECDH-KW key types or nil for other key types.
+func keyTypeCurve(keyType kms.KeyType) elliptic.Curve {
+	switch keyType { //nolint:exhaustive
+	case kms.ECDSAP256TypeDER, kms.ECDSAP256TypeIEEEP1363, kms.NISTP256ECDHKWType:
+		return elliptic.P256()
+	case kms.ECDSAP384TypeDER, kms.ECDSAP384TypeIEEEP1363, kms.NISTP384ECDHKWType:
+		return elliptic.P384()
+	case kms.ECDSAP521TypeDER, kms.ECDSAP521TypeIEEEP1363, kms.NISTP521ECDHKWType:
+		return elliptic.P521()
+	default:
+		return nil
+	}
+}
+
+// getKey returns a handle of the key from the key store. Imported public keys are not kept in the key store, their
+// handles are built from the public key in key metadata.
+func (c *Command) getKey(ks kms.KeyManager, keyStoreID, keyID string) (interface{}, error) {
+	meta, err := c.getKeyMeta(keyStoreID, keyID)
+	if err != nil && !goerrors.Is(err, storage.ErrDataNotFound) {
+		return nil, fmt.Errorf("get key metadata: %w", err)
+	}
+
+	if meta != nil && meta.PublicKey != nil {
+		return ks.PubKeyBytesToHandle(meta.PublicKey, meta.KeyType)
+	}
+
+	kh, err := ks.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// failure to backfill metadata doesn't fail the operation with the key
+	if meta == nil {
+		if err = c.backfillKeyMeta(ks, keyStoreID, keyID, kh); err != nil {
+			logger.Warnf("backfill metadata of key %s in key store %s: %v", keyID, keyStoreID, err)
+		}
+	}
+
+	return kh, nil
+}
+
+// recipientPublicKey returns the imported public key to wrap keys to.
+func (c *Command) recipientPublicKey(keyStoreID, keyID string) (*crypto.PublicKey, error) {
+	if err := c.checkKeyUsable(keyStoreID, keyID, ActionWrap); err != nil {
+		return nil, err
+	}
+
+	meta, err := c.findKeyMeta(keyStoreID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.PublicKey == nil {
+		return nil, fmt.Errorf("%w: recipient key is not an imported public key", errors.ErrBadRequest)
+	}
+
+	var pub crypto.PublicKey
+
+	if err = json.Unmarshal(meta.PublicKey, &pub); err != nil {
+		return nil, fmt.Errorf("unmarshal recipient public key: %w", err)
+	}
+
+	pub.KID = keyID
+
+	return &pub, nil
+}
+
+// publicKeyHandle returns a handle of public keys of the key. Handles of imported public keys are returned as is.
+func publicKeyHandle(kh *keyset.Handle) (*keyset.Handle, error) {
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	if len(ks.Key) > 0 && ks.Key[0].KeyData.KeyMaterialType == tinkpb.KeyData_ASYMMETRIC_PUBLIC {
+		return kh, nil
+	}
+
+	return kh.Public()
+}
@@ -0,0 +1,249 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose/kid/resolver"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// JWE serializations.
+const (
+	JWECompact = "compact" // JWE Compact Serialization, for one recipient and no AAD only
+	JWEJSON    = "json"    // general JWE JSON Serialization
+)
+
+// jweContentEncryptions are supported content encryption algorithms.
+//
+//nolint:gochecknoglobals
+var jweContentEncryptions = []string{string(jose.A256GCM), string(jose.XC20P)}
+
+// EncryptJWE encrypts the plaintext to recipients and returns a JWE. If the request has a key, the JWE is authcrypt
+// (ECDH-1PU) with the key as the sender, otherwise anoncrypt (ECDH-ES). Recipients are public keys from the request
+// and imported public keys of the key store.
+func (c *Command) EncryptJWE(w io.Writer, r io.Reader) error {
+	var req EncryptJWERequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	recipients, err := c.jweRecipients(wr.KeyStoreID, &req)
+	if err != nil {
+		return err
+	}
+
+	senderKH, err := c.getJWESenderKey(wr)
+	if err != nil {
+		return err
+	}
+
+	encAlg := jose.A256GCM
+	if req.ContentEncryption != "" {
+		encAlg = jose.EncAlg(req.ContentEncryption)
+	}
+
+	enc, err := jose.NewJWEEncrypt(encAlg, jweMediaType, req.ContentType, wr.KeyID, senderKH, recipients, c.crypto)
+	if err != nil {
+		return fmt.Errorf("create jwe encrypter: %w", err)
+	}
+
+	jwe, err := enc.EncryptWithAuthData(req.Plaintext, req.AAD)
+	if err != nil {
+		return fmt.Errorf("%w: encrypt jwe: %s", errors.ErrBadRequest, err)
+	}
+
+	var b []byte
+
+	if req.Serialization == JWEJSON {
+		var s string
+
+		if s, err = jwe.FullSerialize(json.Marshal); err == nil {
+			b = []byte(s)
+		}
+	} else {
+		var s string
+
+		if s, err = jwe.CompactSerialize(json.Marshal); err == nil {
+			b, err = json.Marshal(s)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("serialize jwe: %w", err)
+	}
+
+	return json.NewEncoder(w).Encode(EncryptJWEResponse{JWE: b})
+}
+
+// DecryptJWE decrypts a JWE with the key as the recipient. Sender public key is needed to decrypt authcrypt JWE.
+func (c *Command) DecryptJWE(w io.Writer, r io.Reader) error {
+	var req DecryptJWERequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	serialized := string(req.JWE)
+
+	var compact string
+
+	if json.Unmarshal(req.JWE, &compact) == nil {
+		serialized = compact
+	}
+
+	jwe, err := jose.Deserialize(serialized)
+	if err != nil {
+		return fmt.Errorf("%w: deserialize jwe: %s", errors.ErrBadRequest, err)
+	}
+
+	kh, err := c.getKeyHandleFromRequest(wr, ActionUnwrap)
+	if err != nil {
+		return err
+	}
+
+	dec := jose.NewJWEDecrypt(
+		[]resolver.KIDResolver{newJWEKIDResolver(jwe, req.SenderPubKey)},
+		&jweCrypto{Crypto: c.crypto, cmd: c},
+		&jweRecipientKeyManager{kh: kh},
+	)
+
+	plaintext, err := dec.Decrypt(jwe)
+	if err != nil {
+		return fmt.Errorf("%w: decrypt jwe: %s", errors.ErrBadRequest, err)
+	}
+
+	return json.NewEncoder(w).Encode(DecryptJWEResponse{Plaintext: plaintext})
+}
+
+// jweRecipients returns public keys of JWE recipients.
+func (c *Command) jweRecipients(keyStoreID string, req *EncryptJWERequest) ([]*crypto.PublicKey, error) {
+	recipients := make([]*crypto.PublicKey, 0, len(req.Recipients)+len(req.RecipientKeyIDs))
+
+	for i, k := range req.Recipients {
+		if err := validateECDHPublicKey(k); err != nil {
+			return nil, fmt.Errorf("%w: recipient %d: %s", errors.ErrBadRequest, i, err)
+		}
+
+		recipients = append(recipients, k)
+	}
+
+	for _, kid := range req.RecipientKeyIDs {
+		k, err := c.recipientPublicKey(keyStoreID, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		recipients = append(recipients, k)
+	}
+
+	return recipients, nil
+}
+
+// getJWESenderKey returns a handle of the primary version of the sender key for authcrypt, or nil for anoncrypt.
+func (c *Command) getJWESenderKey(wr *WrappedRequest) (*keyset.Handle, error) {
+	if wr.KeyID == "" {
+		return nil, nil
+	}
+
+	kh, err := c.getKeyHandleFromRequest(wr, ActionWrap)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := keyVersionHandles(kh)
+	if err != nil {
+		return nil, fmt.Errorf("get key versions: %w", err)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: key has no enabled versions", errors.ErrConflict)
+	}
+
+	senderKH, ok := versions[0].(*keyset.Handle)
+	if !ok {
+		return nil, fmt.Errorf("%w: sender key must be a private key", errors.ErrBadRequest)
+	}
+
+	return senderKH, nil
+}
+
+// jweKIDResolver resolves the sender key ID of authcrypt JWE to the sender public key from the request. Recipient key
+// IDs are resolved as is, the recipient key is known from the request.
+type jweKIDResolver struct {
+	recipientKIDs map[string]struct{}
+	sender        *crypto.PublicKey
+}
+
+func newJWEKIDResolver(jwe *jose.JSONWebEncryption, sender *crypto.PublicKey) *jweKIDResolver {
+	r := &jweKIDResolver{recipientKIDs: make(map[string]struct{}), sender: sender}
+
+	for _, rec := range jwe.Recipients {
+		if rec.Header != nil {
+			r.recipientKIDs[rec.Header.KID] = struct{}{}
+		}
+	}
+
+	if kid, ok := jwe.ProtectedHeaders.KeyID(); ok {
+		r.recipientKIDs[kid] = struct{}{}
+	}
+
+	return r
+}
+
+func (r *jweKIDResolver) Resolve(kid string) (*crypto.PublicKey, error) {
+	if _, ok := r.recipientKIDs[kid]; ok {
+		return &crypto.PublicKey{KID: kid}, nil
+	}
+
+	if r.sender == nil {
+		return nil, fmt.Errorf("sender public key is required to decrypt jwe from %s", kid)
+	}
+
+	return r.sender, nil
+}
+
+// jweRecipientKeyManager returns the recipient key for any key ID of the JWE recipients. Key IDs in the JWE may be
+// different from the key store's ones, e.g. DID URLs.
+type jweRecipientKeyManager struct {
+	kms.KeyManager
+	kh interface{}
+}
+
+func (m *jweRecipientKeyManager) Get(string) (interface{}, error) {
+	return m.kh, nil
+}
+
+// jweCrypto unwraps content encryption keys with all enabled versions of the recipient key.
+type jweCrypto struct {
+	crypto.Crypto
+	cmd *Command
+}
+
+func (c *jweCrypto) UnwrapKey(wk *crypto.RecipientWrappedKey, kh interface{},
+	opts ...crypto.WrapKeyOpts) ([]byte, error) {
+	return c.cmd.unwrapKey(wk, kh, opts...)
+}
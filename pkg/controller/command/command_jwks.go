@@ -0,0 +1,148 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	publishedTagName = "published"
+	// JWKSMaxAge is the time clients may cache the JWK Set of the key store.
+	JWKSMaxAge = 5 * time.Minute
+	// retiredJWKGracePeriod is how long, on top of JWKSMaxAge, the JWK Set keeps JWKs of versions replaced by rotation,
+	// so that clients that fetched the set before rotation can still verify with the previous version.
+	retiredJWKGracePeriod = time.Hour
+)
+
+// retiredJWK is a published JWK of the key version replaced by rotation.
+type retiredJWK struct {
+	JWK       json.RawMessage `json:"jwk"`
+	RetiredAt time.Time       `json:"retired_at"`
+}
+
+// JWKS returns a JWK Set of public keys published in the key store. Only public material is returned, so the key store
+// is not opened and no user's secret share is needed. Disabled keys, keys pending deletion and keys expired beyond the
+// grace period are not included. JWKs of versions replaced by rotation are kept in the set for JWKSMaxAge plus
+// retiredJWKGracePeriod after rotation.
+func (c *Command) JWKS(w io.Writer, r io.Reader) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	ksMeta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	if ksMeta.status() == keyStoreStatusDeactivated {
+		return fmt.Errorf("%w: key store is deactivated", errors.ErrConflict)
+	}
+
+	metas, err := c.queryKeyMeta(fmt.Sprintf("%s:%s", publishedTagName, wr.KeyStoreID))
+	if err != nil {
+		return fmt.Errorf("query key metadata: %w", err)
+	}
+
+	keys := make([]json.RawMessage, 0, len(metas))
+	now := time.Now()
+
+	for _, meta := range metas {
+		if meta.status() != keyStatusEnabled {
+			continue
+		}
+
+		if meta.NotAfter != nil && now.After(meta.NotAfter.Add(c.keyExpiryGrace)) {
+			continue
+		}
+
+		for _, retired := range retainedJWKs(meta.RetiredJWKs, now) {
+			keys = append(keys, retired.JWK)
+		}
+
+		if meta.PublishedJWK != nil {
+			keys = append(keys, meta.PublishedJWK)
+		}
+	}
+
+	return json.NewEncoder(w).Encode(JWKSResponse{Keys: keys})
+}
+
+// validatePublish checks that keys of the key type can be published in the JWK Set of the key store.
+func validatePublish(keyType kms.KeyType) error {
+	if _, ok := jwkAlgorithms[keyType]; !ok {
+		return fmt.Errorf("%w: key of type %s can't be published", errors.ErrValidation, keyType)
+	}
+
+	return nil
+}
+
+// publishKey sets the JWK of the key to be published in the JWK Set of the key store. Public key is converted at
+// publish time, as the JWK Set is served without opening the key store.
+func publishKey(ks kms.KeyManager, meta *keyMeta) error {
+	pub, keyType := meta.PublicKey, meta.KeyType
+
+	if pub == nil {
+		var err error
+
+		pub, keyType, err = ks.ExportPubKeyBytes(meta.ID)
+		if err != nil {
+			return fmt.Errorf("export public key bytes: %w", err)
+		}
+	}
+
+	j, err := publicKeyJWK(meta.ID, pub, keyType)
+	if err != nil {
+		return fmt.Errorf("convert public key to jwk: %w", err)
+	}
+
+	b, err := j.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("marshal jwk: %w", err)
+	}
+
+	meta.Published = true
+	meta.PublishedJWK = b
+
+	return nil
+}
+
+func (m *keyMeta) unpublish() {
+	m.Published = false
+	m.PublishedJWK = nil
+	m.RetiredJWKs = nil
+}
+
+// retireJWKs carries JWKs of the old key over to the rotated one: the published JWK of the old key is retired at the
+// rotation time and previously retired JWKs are kept until their retention ends.
+func (m *keyMeta) retireJWKs(oldMeta *keyMeta, rotatedAt time.Time) {
+	m.RetiredJWKs = retainedJWKs(oldMeta.RetiredJWKs, rotatedAt)
+
+	if oldMeta.PublishedJWK != nil {
+		m.RetiredJWKs = append(m.RetiredJWKs, retiredJWK{JWK: oldMeta.PublishedJWK, RetiredAt: rotatedAt})
+	}
+}
+
+func retainedJWKs(retired []retiredJWK, now time.Time) []retiredJWK {
+	var retained []retiredJWK
+
+	for _, r := range retired {
+		if now.Before(r.RetiredAt.Add(JWKSMaxAge + retiredJWKGracePeriod)) {
+			retained = append(retained, r)
+		}
+	}
+
+	return retained
+}
@@ -0,0 +1,367 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// JWS serializations.
+const (
+	JWSCompact = "compact" // JWS Compact Serialization
+	JWSJSON    = "json"    // general JWS JSON Serialization
+)
+
+const (
+	jwsHeaderAlgorithm = "alg"
+	jwsHeaderKeyID     = "kid"
+	jwsHeaderB64       = "b64"
+	jwsHeaderCritical  = "crit"
+	jwsCompactParts    = 3
+)
+
+// jwsAlgorithms are JWS algorithms of signing keys by key type.
+//
+//nolint:gochecknoglobals
+var jwsAlgorithms = map[kms.KeyType]string{
+	kms.ED25519Type:                 "EdDSA",
+	kms.ECDSAP256TypeDER:            "ES256",
+	kms.ECDSAP256TypeIEEEP1363:      "ES256",
+	kms.ECDSAP384TypeDER:            "ES384",
+	kms.ECDSAP384TypeIEEEP1363:      "ES384",
+	kms.ECDSAP521TypeDER:            "ES512",
+	kms.ECDSAP521TypeIEEEP1363:      "ES512",
+}
+
+// ecdsaDERIntegerSizes are sizes of R and S integers of ECDSA signatures for key types that sign in ASN.1 DER format.
+// JWS uses IEEE P1363 format (R || S) with integers of the curve size.
+//
+//nolint:gochecknoglobals
+var ecdsaDERIntegerSizes = map[kms.KeyType]int{
+	kms.ECDSAP256TypeDER: 32,
+	kms.ECDSAP384TypeDER: 48,
+	kms.ECDSAP521TypeDER: 66,
+}
+
+// jwsJSON is general or flattened JWS JSON Serialization. Unprotected headers are ignored.
+type jwsJSON struct {
+	Payload    string             `json:"payload"`
+	Signatures []jwsJSONSignature `json:"signatures,omitempty"`
+	Protected  string             `json:"protected,omitempty"` // flattened serialization
+	Signature  string             `json:"signature,omitempty"` // flattened serialization
+}
+
+type jwsJSONSignature struct {
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+// SignJWS creates a JWS of the payload. Algorithm and key ID headers are set from the key; other protected headers are
+// taken from the request. ECDSA signatures are converted to the format required by JWS.
+func (c *Command) SignJWS(w io.Writer, r io.Reader) error {
+	var req SignJWSRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	kh, keyType, err := c.getJWSKey(wr, ActionSign)
+	if err != nil {
+		return err
+	}
+
+	jws, err := c.createJWS(kh, keyType, wr.KeyID, req.Headers, req.Payload)
+	if err != nil {
+		return err
+	}
+
+	var b []byte
+
+	if req.Serialization == JWSJSON {
+		b, err = json.Marshal(jws)
+	} else {
+		b, err = json.Marshal(jws.compact())
+	}
+
+	if err != nil {
+		return fmt.Errorf("marshal jws: %w", err)
+	}
+
+	return json.NewEncoder(w).Encode(SignJWSResponse{JWS: b})
+}
+
+// createJWS signs the payload and returns JWS with one signature. Alg header is set from the key type and kid defaults
+// to the key ID.
+func (c *Command) createJWS(kh interface{}, keyType kms.KeyType, keyID string, extraHeaders map[string]interface{},
+	payload []byte) (*jwsJSON, error) {
+	headers := make(map[string]interface{}, len(extraHeaders)+2) //nolint:gomnd
+	for k, v := range extraHeaders {
+		headers[k] = v
+	}
+
+	headers[jwsHeaderAlgorithm] = jwsAlgorithms[keyType]
+
+	if _, ok := headers[jwsHeaderKeyID]; !ok {
+		headers[jwsHeaderKeyID] = keyID
+	}
+
+	protected, err := json.Marshal(headers)
+	if err != nil {
+		return nil, fmt.Errorf("marshal jws headers: %w", err)
+	}
+
+	b64Protected := base64.RawURLEncoding.EncodeToString(protected)
+	b64Payload := base64.RawURLEncoding.EncodeToString(payload)
+
+	resp, err := c.sign(kh, &SignRequest{Message: []byte(b64Protected + "." + b64Payload)})
+	if err != nil {
+		return nil, err
+	}
+
+	signature := resp.Signature
+
+	if size, ok := ecdsaDERIntegerSizes[keyType]; ok {
+		if signature, err = ecdsaDERToP1363(signature, size); err != nil {
+			return nil, fmt.Errorf("convert signature: %w", err)
+		}
+	}
+
+	return &jwsJSON{
+		Payload: b64Payload,
+		Signatures: []jwsJSONSignature{{
+			Protected: b64Protected,
+			Signature: base64.RawURLEncoding.EncodeToString(signature),
+		}},
+	}, nil
+}
+
+// compact returns compact serialization of the JWS with its first signature.
+func (j *jwsJSON) compact() string {
+	return j.Signatures[0].Protected + "." + j.Payload + "." + j.Signatures[0].Signature
+}
+
+// VerifyJWS verifies a JWS in compact, general or flattened JSON serialization. JWS is valid if any of its signatures
+// with the algorithm of the key is verified. Payload and protected headers of the verified signature are returned.
+func (c *Command) VerifyJWS(w io.Writer, r io.Reader) error {
+	var req VerifyJWSRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	jws, err := parseJWS(req.JWS)
+	if err != nil {
+		return err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: decode jws payload", errors.ErrBadRequest)
+	}
+
+	kh, keyType, err := c.getJWSKey(wr, ActionVerify)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range jws.Signatures {
+		headers, verifyErr := c.verifyJWSSignature(kh, keyType, s, jws.Payload)
+		if verifyErr != nil {
+			continue
+		}
+
+		return json.NewEncoder(w).Encode(VerifyJWSResponse{Payload: payload, Headers: headers})
+	}
+
+	return fmt.Errorf("%w: jws signature verification failed", errors.ErrBadRequest)
+}
+
+// verifyJWSSignature verifies the signature of the JWS and returns its protected headers.
+func (c *Command) verifyJWSSignature(kh interface{}, keyType kms.KeyType, s jwsJSONSignature,
+	b64Payload string) (map[string]interface{}, error) {
+	protected, err := base64.RawURLEncoding.DecodeString(s.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("decode protected headers: %w", err)
+	}
+
+	var headers map[string]interface{}
+
+	if err = json.Unmarshal(protected, &headers); err != nil {
+		return nil, fmt.Errorf("unmarshal protected headers: %w", err)
+	}
+
+	if headers[jwsHeaderAlgorithm] != jwsAlgorithms[keyType] {
+		return nil, fmt.Errorf("unexpected algorithm %v", headers[jwsHeaderAlgorithm])
+	}
+
+	if b64, ok := headers[jwsHeaderB64]; ok && b64 != true {
+		return nil, goerrors.New("unencoded payload is not supported")
+	}
+
+	// critical extensions must be understood by the recipient (RFC 7515 section 4.1.11), none are supported
+	if _, ok := headers[jwsHeaderCritical]; ok {
+		return nil, goerrors.New("crit header is not supported")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+
+	if size, ok := ecdsaDERIntegerSizes[keyType]; ok {
+		if signature, err = ecdsaP1363ToDER(signature, size); err != nil {
+			return nil, fmt.Errorf("convert signature: %w", err)
+		}
+	}
+
+	err = c.verify(kh, &VerifyRequest{Signature: signature, Message: []byte(s.Protected + "." + b64Payload)})
+	if err != nil {
+		return nil, err
+	}
+
+	return headers, nil
+}
+
+// getJWSKey returns a handle and type of the key for the JWS operation.
+func (c *Command) getJWSKey(wr *WrappedRequest, operation string) (interface{}, kms.KeyType, error) {
+	kh, err := c.getKeyHandleFromRequest(wr, operation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keyType, err := c.getKeyType(wr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, ok := jwsAlgorithms[keyType]; !ok {
+		return nil, "", fmt.Errorf("%w: key of type %s can't be used for jws", errors.ErrBadRequest, keyType)
+	}
+
+	return kh, keyType, nil
+}
+
+// getKeyType returns type of the requested key from key metadata or, for keys created before key metadata was
+// introduced, from the key store.
+func (c *Command) getKeyType(wr *WrappedRequest) (kms.KeyType, error) {
+	meta, err := c.getKeyMeta(wr.KeyStoreID, wr.KeyID)
+	switch {
+	case err == nil:
+		return meta.KeyType, nil
+	case goerrors.Is(err, storage.ErrDataNotFound):
+		ks, resolveErr := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
+		if resolveErr != nil {
+			return "", fmt.Errorf("resolve key store: %w", resolveErr)
+		}
+
+		_, keyType, exportErr := ks.ExportPubKeyBytes(wr.KeyID)
+		if exportErr != nil {
+			return "", fmt.Errorf("export public key bytes: %w", exportErr)
+		}
+
+		return keyType, nil
+	default:
+		return "", fmt.Errorf("get key metadata: %w", err)
+	}
+}
+
+// parseJWS parses JWS in compact (JSON string), general or flattened JSON serialization. The result always has
+// signatures set.
+func parseJWS(b json.RawMessage) (*jwsJSON, error) {
+	var compact string
+
+	if err := json.Unmarshal(b, &compact); err == nil {
+		return parseCompactJWS(compact)
+	}
+
+	var jws jwsJSON
+
+	if err := json.Unmarshal(b, &jws); err != nil {
+		return nil, fmt.Errorf("%w: invalid jws json serialization", errors.ErrBadRequest)
+	}
+
+	if jws.Signature != "" {
+		jws.Signatures = append(jws.Signatures, jwsJSONSignature{Protected: jws.Protected, Signature: jws.Signature})
+	}
+
+	if len(jws.Signatures) == 0 {
+		return nil, fmt.Errorf("%w: jws has no signatures", errors.ErrBadRequest)
+	}
+
+	return &jws, nil
+}
+
+// parseCompactJWS parses JWS in compact serialization.
+func parseCompactJWS(compact string) (*jwsJSON, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != jwsCompactParts {
+		return nil, fmt.Errorf("%w: invalid jws compact serialization", errors.ErrBadRequest)
+	}
+
+	return &jwsJSON{
+		Payload:    parts[1],
+		Signatures: []jwsJSONSignature{{Protected: parts[0], Signature: parts[2]}},
+	}, nil
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// ecdsaDERToP1363 converts ECDSA signature from ASN.1 DER to IEEE P1363 format with integers of the given size.
+func ecdsaDERToP1363(der []byte, size int) ([]byte, error) {
+	var sig ecdsaSignature
+
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("unmarshal der signature: %w", err)
+	}
+
+	if len(sig.R.Bytes()) > size || len(sig.S.Bytes()) > size {
+		return nil, goerrors.New("invalid der signature")
+	}
+
+	b := make([]byte, 2*size) //nolint:gomnd
+
+	sig.R.FillBytes(b[:size])
+	sig.S.FillBytes(b[size:])
+
+	return b, nil
+}
+
+// ecdsaP1363ToDER converts ECDSA signature from IEEE P1363 format with integers of the given size to ASN.1 DER.
+func ecdsaP1363ToDER(sig []byte, size int) ([]byte, error) {
+	if len(sig) != 2*size { //nolint:gomnd
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+
+	return asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(sig[:size]),
+		S: new(big.Int).SetBytes(sig[size:]),
+	})
+}
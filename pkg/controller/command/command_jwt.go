@@ -0,0 +1,216 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	defaultJWTLifetime    = time.Hour
+	defaultJWTMaxLifetime = 24 * time.Hour
+	jwtLeeway             = time.Minute // allowed clock skew when checking time claims
+)
+
+// Registered JWT claims.
+const (
+	jwtClaimIssuer    = "iss"
+	jwtClaimAudience  = "aud"
+	jwtClaimExpiry    = "exp"
+	jwtClaimNotBefore = "nbf"
+	jwtClaimIssuedAt  = "iat"
+	jwtClaimID        = "jti"
+)
+
+// jwtServerClaims are claims set by the server on issued JWTs.
+//
+//nolint:gochecknoglobals
+var jwtServerClaims = []string{jwtClaimIssuedAt, jwtClaimExpiry, jwtClaimID}
+
+// SignJWT issues a JWT with the claim set signed by the key. Issued at, expiry and JWT ID claims are set by the server;
+// expiry is ExpiresIn seconds from now, the configured JWT lifetime by default (one hour unless configured) and the
+// configured max lifetime (24 hours unless configured) at most.
+func (c *Command) SignJWT(w io.Writer, r io.Reader) error {
+	var req SignJWTRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if maxExpiresIn := int64(c.jwtMaxLifetime / time.Second); req.ExpiresIn > maxExpiresIn {
+		return fmt.Errorf("validate request: %w: expires in must be between 0 and %d seconds", errors.ErrValidation,
+			maxExpiresIn)
+	}
+
+	kh, keyType, err := c.getJWSKey(wr, ActionSign)
+	if err != nil {
+		return err
+	}
+
+	expiresIn := c.jwtLifetime
+	if req.ExpiresIn > 0 {
+		expiresIn = time.Duration(req.ExpiresIn) * time.Second
+	}
+
+	now := time.Now()
+
+	claims := make(map[string]interface{}, len(req.Claims)+len(jwtServerClaims))
+	for k, v := range req.Claims {
+		claims[k] = v
+	}
+
+	claims[jwtClaimIssuedAt] = now.Unix()
+	claims[jwtClaimExpiry] = now.Add(expiresIn).Unix()
+	claims[jwtClaimID] = xid.New().String()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return fmt.Errorf("marshal claims: %w", err)
+	}
+
+	jws, err := c.createJWS(kh, keyType, wr.KeyID, map[string]interface{}{"typ": "JWT"}, payload)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(SignJWTResponse{JWT: jws.compact()})
+}
+
+// VerifyJWT validates a JWT issued with the key. Signature and time claims are always checked, the JWT must have
+// an expiry. Audience and issuer are checked if set in the request. Claims of the valid JWT are returned.
+func (c *Command) VerifyJWT(w io.Writer, r io.Reader) error {
+	var req VerifyJWTRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	jws, err := parseCompactJWS(req.JWT)
+	if err != nil {
+		return err
+	}
+
+	kh, keyType, err := c.getJWSKey(wr, ActionVerify)
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.verifyJWSSignature(kh, keyType, jws.Signatures[0], jws.Payload); err != nil {
+		return fmt.Errorf("%w: jwt signature verification failed", errors.ErrBadRequest)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: decode jwt claims", errors.ErrBadRequest)
+	}
+
+	var claims map[string]interface{}
+
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return fmt.Errorf("%w: unmarshal jwt claims", errors.ErrBadRequest)
+	}
+
+	if err = checkJWTClaims(claims, &req, time.Now()); err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(VerifyJWTResponse{Claims: claims})
+}
+
+// checkJWTClaims checks time claims of the JWT and its audience and issuer if expected values are set in the request.
+func checkJWTClaims(claims map[string]interface{}, req *VerifyJWTRequest, now time.Time) error {
+	exp, ok := numericDate(claims[jwtClaimExpiry])
+	if !ok {
+		return fmt.Errorf("%w: jwt has no valid exp claim", errors.ErrBadRequest)
+	}
+
+	if now.After(exp.Add(jwtLeeway)) {
+		return fmt.Errorf("%w: jwt is expired", errors.ErrBadRequest)
+	}
+
+	if nbf, ok := numericDate(claims[jwtClaimNotBefore]); ok && now.Add(jwtLeeway).Before(nbf) {
+		return fmt.Errorf("%w: jwt is not valid yet", errors.ErrBadRequest)
+	}
+
+	if iat, ok := numericDate(claims[jwtClaimIssuedAt]); ok && now.Add(jwtLeeway).Before(iat) {
+		return fmt.Errorf("%w: jwt is issued in the future", errors.ErrBadRequest)
+	}
+
+	if req.Issuer != "" && claims[jwtClaimIssuer] != req.Issuer {
+		return fmt.Errorf("%w: invalid jwt issuer", errors.ErrBadRequest)
+	}
+
+	if req.Audience != "" && !hasAudience(claims[jwtClaimAudience], req.Audience) {
+		return fmt.Errorf("%w: invalid jwt audience", errors.ErrBadRequest)
+	}
+
+	return nil
+}
+
+// numericDate returns time of the NumericDate claim value, i.e. seconds since the epoch.
+func numericDate(v interface{}) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	sec, frac := math.Modf(f)
+
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), true
+}
+
+// hasAudience checks that the aud claim, a string or an array of strings, contains the audience.
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if a == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// jwtLifetimes returns the default and max lifetime of issued JWTs. Not positive values are replaced with defaults.
+func jwtLifetimes(lifetime, maxLifetime time.Duration) (time.Duration, time.Duration, error) {
+	if lifetime <= 0 {
+		lifetime = defaultJWTLifetime
+	}
+
+	if maxLifetime <= 0 {
+		maxLifetime = defaultJWTMaxLifetime
+	}
+
+	if lifetime > maxLifetime {
+		return 0, 0, fmt.Errorf("jwt lifetime %s exceeds max lifetime %s", lifetime, maxLifetime)
+	}
+
+	return lifetime, maxLifetime, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+const (
+	keyManagerCacheKeyPrefix = "kms_key_manager_"
+	keyManagerCacheItemCost  = 1
+)
+
+// cachedKeyManager is user's key manager kept in the cache together with a hash of the key store metadata it was
+// created for.
+type cachedKeyManager struct {
+	keyManager kms.KeyManager
+	metaHash   [sha256.Size]byte
+}
+
+// resolveCachedKeyStore returns user's key manager from the cache or creates and caches a new one. Key store metadata
+// is still read on each call (through the storage cache), so a key manager is re-created as soon as metadata or EDV
+// capability is updated, even by another server instance, and deactivated key stores are never served.
+func (c *Command) resolveCachedKeyStore(keyStoreID, user string, secretShare []byte) (kms.KeyManager, error) {
+	startTime := time.Now()
+	defer func() { c.metrics.KeyStoreResolveTime(time.Since(startTime)) }()
+
+	meta, err := c.getActiveKeyStoreMeta(keyStoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("marshal key store meta: %w", err)
+	}
+
+	metaHash := sha256.Sum256(metaBytes)
+	cacheKey := keyManagerCacheKey(keyStoreID, user, secretShare)
+
+	if v, ok := c.keyManagerCache.Get(cacheKey); ok {
+		if cached, ok := v.(*cachedKeyManager); ok && cached.metaHash == metaHash {
+			c.metrics.KeyManagerCacheHit()
+
+			return cached.keyManager, nil
+		}
+	}
+
+	c.metrics.KeyManagerCacheMiss()
+
+	keyURI, p, err := c.newKeyStoreProvider(meta, user, secretShare)
+	if err != nil {
+		return nil, err
+	}
+
+	km, err := c.keyStoreCreator.Create(keyURI, p)
+	if err != nil {
+		return nil, err
+	}
+
+	c.keyManagerCache.SetWithTTL(cacheKey, &cachedKeyManager{keyManager: km, metaHash: metaHash},
+		keyManagerCacheItemCost, c.keyManagerCacheTTL)
+
+	return km, nil
+}
+
+// invalidateKeyManager removes user's key manager of the key store from the cache after the key store is updated,
+// deactivated, deleted or migrated. Key managers of other users are re-created on the next request, as the metadata
+// hash they were cached with no longer matches.
+func (c *Command) invalidateKeyManager(keyStoreID, user string, secretShare []byte) {
+	if c.keyManagerCache != nil {
+		c.keyManagerCache.Del(keyManagerCacheKey(keyStoreID, user, secretShare))
+	}
+}
+
+// keyManagerCacheKey returns a cache key for user's key manager. The secret share is part of the key, so a request
+// with another share never gets a key manager unlocked with a different one. Only a hash is kept in the cache.
+func keyManagerCacheKey(keyStoreID, user string, secretShare []byte) string {
+	h := sha256.New()
+
+	for _, b := range [][]byte{[]byte(keyStoreID), []byte(user), secretShare} {
+		h.Write([]byte(fmt.Sprintf("%d:", len(b))))
+		h.Write(b)
+	}
+
+	return keyManagerCacheKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
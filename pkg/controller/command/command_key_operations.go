@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// Key operations are named after actions of the corresponding crypto commands.
+//
+//nolint:gochecknoglobals
+var (
+	signatureOperations = []string{ActionSign, ActionVerify}
+	aeadOperations      = []string{ActionEncrypt, ActionDecrypt}
+	macOperations       = []string{ActionComputeMac, ActionVerifyMAC}
+	keyWrapOperations   = []string{ActionWrap, ActionUnwrap}
+	bbsOperations       = []string{ActionSignMulti, ActionVerifyMulti, ActionDeriveProof, ActionVerifyProof}
+)
+
+// keyTypeOperations are operations supported by key types.
+//
+//nolint:gochecknoglobals
+var keyTypeOperations = map[kms.KeyType][]string{
+	kms.ED25519Type:            signatureOperations,
+	kms.ECDSAP256TypeDER:       signatureOperations,
+	kms.ECDSAP384TypeDER:       signatureOperations,
+	kms.ECDSAP521TypeDER:       signatureOperations,
+	kms.ECDSAP256TypeIEEEP1363: signatureOperations,
+	kms.ECDSAP384TypeIEEEP1363: signatureOperations,
+	kms.ECDSAP521TypeIEEEP1363: signatureOperations,
+	kms.AES128GCMType:          aeadOperations,
+	kms.AES256GCMType:          aeadOperations,
+	kms.AES256GCMNoPrefixType:  aeadOperations,
+	kms.ChaCha20Poly1305Type:   aeadOperations,
+	kms.XChaCha20Poly1305Type:  aeadOperations,
+	kms.HMACSHA256Tag256Type:   macOperations,
+	kms.NISTP256ECDHKWType:     keyWrapOperations,
+	kms.NISTP384ECDHKWType:     keyWrapOperations,
+	kms.NISTP521ECDHKWType:     keyWrapOperations,
+	kms.X25519ECDHKWType:       keyWrapOperations,
+	kms.BLS12381G2Type:         bbsOperations,
+}
+
+// allowedOperations returns operations allowed for the key. If the operations were not narrowed at creation, all
+// operations supported by the key type are allowed. Returns nil if the key type is unknown, i.e. no restrictions.
+func (m *keyMeta) allowedOperations() []string {
+	if len(m.AllowedOperations) > 0 {
+		return m.AllowedOperations
+	}
+
+	return keyTypeOperations[m.KeyType]
+}
+
+// checkKeyOperation returns an error if the operation is not allowed for the key.
+func (m *keyMeta) checkKeyOperation(operation string) error {
+	allowed := m.allowedOperations()
+
+	if allowed == nil || containsString(allowed, operation) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: operation %s is not allowed for the key", errors.ErrBadRequest, operation)
+}
+
+// validateAllowedOperations checks that allowed operations are supported by the key type.
+func validateAllowedOperations(keyType kms.KeyType, operations []string) error {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	supported, ok := keyTypeOperations[keyType]
+	if !ok {
+		return fmt.Errorf("%w: allowed operations can't be set for key type %s", errors.ErrValidation, keyType)
+	}
+
+	for _, op := range operations {
+		if !containsString(supported, op) {
+			return fmt.Errorf("%w: operation %q is not supported by key type %s", errors.ErrValidation, op, keyType)
+		}
+	}
+
+	return nil
+}
+
+// rotatedKeyOperations returns allowed operations for the key rotated to a new key type. Operations not supported by
+// the new key type are dropped. Rotation fails if the key is restricted and none of its operations are supported by
+// the new key type, as an empty list would allow all operations of the new key type.
+func rotatedKeyOperations(operations []string, keyType kms.KeyType) ([]string, error) {
+	if len(operations) == 0 {
+		return nil, nil
+	}
+
+	var rotated []string
+
+	for _, op := range operations {
+		if containsString(keyTypeOperations[keyType], op) {
+			rotated = append(rotated, op)
+		}
+	}
+
+	if len(rotated) == 0 {
+		return nil, fmt.Errorf("%w: none of allowed operations %v is supported by key type %s", errors.ErrBadRequest,
+			operations, keyType)
+	}
+
+	return rotated, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,208 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	rotationPolicyTagName   = "rotationPolicy"
+	maxRotationIntervalDays = 3650
+)
+
+// setRotationPolicy sets the rotation policy of the key and schedules the next rotation counting from the given time.
+// Nil policy or policy with zero interval disables scheduled rotation.
+func (m *keyMeta) setRotationPolicy(policy *RotationPolicy, from time.Time) {
+	if policy == nil || policy.IntervalDays == 0 {
+		m.RotationPolicy = nil
+		m.NextRotationAt = nil
+
+		return
+	}
+
+	next := from.AddDate(0, 0, policy.IntervalDays)
+
+	m.RotationPolicy = policy
+	m.NextRotationAt = &next
+}
+
+// checkRotationPolicy returns an error if the rotation policy schedules rotation the server can't run. Scheduled
+// rotation opens key stores without user's secret share, so it is not supported with Shamir secret lock.
+func (c *Command) checkRotationPolicy(policy *RotationPolicy) error {
+	if policy != nil && policy.IntervalDays > 0 && c.shamirProvider != nil {
+		return fmt.Errorf("%w: scheduled rotation is not supported with shamir secret lock", errors.ErrBadRequest)
+	}
+
+	return nil
+}
+
+// rotateKey rotates the key in the key store. Metadata of the rotated key keeps attributes and version history of the
+// key, aliases of the key are repointed to the rotated key.
+func (c *Command) rotateKey(ks kms.KeyManager, keyStoreID, keyID string, keyType kms.KeyType) (*keyMeta, error) {
+	oldMeta, err := c.getKeyMeta(keyStoreID, keyID)
+	if err != nil && !goerrors.Is(err, storage.ErrDataNotFound) {
+		return nil, fmt.Errorf("get key metadata: %w", err)
+	}
+
+	if oldMeta != nil && oldMeta.PublicKey != nil {
+		return nil, fmt.Errorf("%w: imported public key can't be rotated", errors.ErrBadRequest)
+	}
+
+	var operations []string
+
+	if oldMeta != nil {
+		if operations, err = rotatedKeyOperations(oldMeta.AllowedOperations, keyType); err != nil {
+			return nil, err
+		}
+	}
+
+	kid, kh, err := ks.Rotate(keyType, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("rotate key: %w", err)
+	}
+
+	createdAt := time.Now().UTC()
+
+	meta := &keyMeta{
+		ID:            kid,
+		KeyStoreID:    keyStoreID,
+		KeyType:       keyType,
+		Status:        keyStatusEnabled,
+		Versions:      newKeyVersions(kid, kh, createdAt),
+		LastRotatedAt: &createdAt,
+		CreatedAt:     createdAt,
+	}
+
+	if oldMeta != nil {
+		meta.Label = oldMeta.Label
+		meta.Description = oldMeta.Description
+		meta.Tags = oldMeta.Tags
+		meta.Versions = append(oldMeta.Versions, meta.Versions...)
+		meta.AllowedOperations = operations
+		meta.NotBefore = oldMeta.NotBefore
+		meta.NotAfter = oldMeta.NotAfter
+		meta.Exportable = oldMeta.Exportable
+		meta.setRotationPolicy(oldMeta.RotationPolicy, createdAt)
+		meta.retireJWKs(oldMeta, createdAt)
+
+		// JWK Set publishes the rotated key if it is still of a publishable type
+		if oldMeta.Published && validatePublish(keyType) == nil {
+			if err = publishKey(ks, meta); err != nil {
+				return nil, fmt.Errorf("publish key: %w", err)
+			}
+		}
+	}
+
+	if err = c.saveKeyMeta(meta); err != nil {
+		return nil, fmt.Errorf("save key metadata: %w", err)
+	}
+
+	// rotated keyset is saved under a new key ID, the old one is removed from the key store; old metadata is removed
+	// last, so the key keeps its metadata if saving the new one fails
+	if kid != keyID {
+		if err = c.keyMetaStore.Delete(keyMetaID(keyStoreID, keyID)); err != nil {
+			return nil, fmt.Errorf("delete key metadata: %w", err)
+		}
+	}
+
+	if err = c.repointAliases(keyStoreID, keyID, kid); err != nil {
+		return nil, fmt.Errorf("repoint aliases: %w", err)
+	}
+
+	return meta, nil
+}
+
+// RotateDueKeys rotates keys whose scheduled rotation time has come. It returns the number of rotated keys. Keys that
+// fail to be rotated are skipped and will be retried on the next call. Disabled keys and keys pending deletion are not
+// rotated.
+//
+// Key stores are opened without user's secret share, so scheduled rotation is not supported if key stores are
+// protected with Shamir secret lock.
+func (c *Command) RotateDueKeys() (int, error) {
+	if c.shamirProvider != nil {
+		return 0, fmt.Errorf("%w: scheduled rotation is not supported with shamir secret lock", errors.ErrBadRequest)
+	}
+
+	metas, err := c.queryKeyMeta(rotationPolicyTagName)
+	if err != nil {
+		return 0, fmt.Errorf("query key metadata: %w", err)
+	}
+
+	var (
+		rotated, failed int
+		rotateErr       error
+		next            *time.Time
+	)
+
+	now := time.Now()
+
+	for _, meta := range metas {
+		if meta.status() != keyStatusEnabled || meta.NextRotationAt == nil {
+			continue
+		}
+
+		if meta.NextRotationAt.After(now) {
+			next = earliestTime(next, meta.NextRotationAt)
+
+			continue
+		}
+
+		rotatedMeta, e := c.rotateDueKey(meta)
+		if e != nil {
+			rotateErr = fmt.Errorf("rotate key %s in key store %s: %w", meta.ID, meta.KeyStoreID, e)
+			failed++
+
+			continue
+		}
+
+		rotated++
+		next = earliestTime(next, rotatedMeta.NextRotationAt)
+	}
+
+	c.metrics.KeysRotated(rotated)
+	c.metrics.KeyRotationFailures(failed)
+
+	if rotated > 0 {
+		c.metrics.LastKeyRotationTime(now)
+	}
+
+	if next != nil {
+		c.metrics.NextKeyRotationTime(*next)
+	}
+
+	return rotated, rotateErr
+}
+
+func (c *Command) rotateDueKey(meta *keyMeta) (*keyMeta, error) {
+	ks, err := c.resolveKeyStore(meta.KeyStoreID, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("resolve key store: %w", err)
+	}
+
+	keyType := meta.RotationPolicy.KeyType
+	if keyType == "" {
+		keyType = meta.KeyType
+	}
+
+	return c.rotateKey(ks, meta.KeyStoreID, meta.ID, keyType)
+}
+
+func earliestTime(t1, t2 *time.Time) *time.Time {
+	if t1 == nil || (t2 != nil && t2.Before(*t1)) {
+		return t2
+	}
+
+	return t1
+}
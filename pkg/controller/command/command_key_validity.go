@@ -0,0 +1,49 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// verificationOperations are operations that process data protected by the key earlier. They are allowed for a grace
+// period after the key expires, so that signatures, ciphertexts and wrapped keys produced in time can still be used.
+//
+//nolint:gochecknoglobals
+var verificationOperations = []string{
+	ActionVerify, ActionDecrypt, ActionVerifyMAC, ActionVerifyMulti, ActionVerifyProof, ActionUnwrap,
+}
+
+// checkKeyValidity returns an error if the operation can't be done with the key at the given time. Keys can't be used
+// before NotBefore. After NotAfter only verification operations are allowed and only within the grace period.
+func (m *keyMeta) checkKeyValidity(operation string, gracePeriod time.Duration, now time.Time) error {
+	if m.NotBefore != nil && now.Before(*m.NotBefore) {
+		return fmt.Errorf("%w: key is not yet valid", errors.ErrConflict)
+	}
+
+	if m.NotAfter == nil || !now.After(*m.NotAfter) {
+		return nil
+	}
+
+	if containsString(verificationOperations, operation) && !now.After(m.NotAfter.Add(gracePeriod)) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: key has expired", errors.ErrConflict)
+}
+
+// validateKeyValidity checks that the key validity window is not empty.
+func validateKeyValidity(notBefore, notAfter *time.Time) error {
+	if notBefore != nil && notAfter != nil && !notAfter.After(*notBefore) {
+		return fmt.Errorf("%w: not_after must be after not_before", errors.ErrValidation)
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	keyVersionStatusEnabled   = "enabled"
+	keyVersionStatusDisabled  = "disabled"
+	keyVersionStatusDestroyed = "destroyed"
+)
+
+// keyVersion is a version of a key. Versions of a key are keys of the same Tink keyset: rotation adds a new key to
+// the keyset and makes it primary, previous versions stay in the keyset.
+type keyVersion struct {
+	KeysetKeyID uint32    `json:"keyset_key_id"`
+	KeyID       string    `json:"key_id"` // key ID the version was created with
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListKeyVersions lists versions of a key from the oldest to the newest.
+func (c *Command) ListKeyVersions(w io.Writer, r io.Reader) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
+	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
+	if err != nil {
+		return fmt.Errorf("resolve key store: %w", err)
+	}
+
+	kh, err := ks.Get(wr.KeyID)
+	if err != nil {
+		return fmt.Errorf("get key: %w", err)
+	}
+
+	handle, ok := kh.(*keyset.Handle)
+	if !ok || handle == nil {
+		return fmt.Errorf("%w: key doesn't support versions", errors.ErrBadRequest)
+	}
+
+	meta, err := c.getKeyMeta(wr.KeyStoreID, wr.KeyID)
+	if err != nil && !goerrors.Is(err, storage.ErrDataNotFound) { // keys created before key metadata was introduced
+		return fmt.Errorf("get key metadata: %w", err)
+	}
+
+	info := handle.KeysetInfo()
+
+	resp := ListKeyVersionsResponse{Versions: make([]KeyVersionInfo, 0, len(info.KeyInfo))}
+
+	for i, ki := range info.KeyInfo {
+		v := KeyVersionInfo{
+			Version: i + 1,
+			Status:  keyVersionStatus(ki.Status),
+			Primary: ki.KeyId == info.PrimaryKeyId,
+		}
+
+		if kv := meta.version(ki.KeyId); kv != nil {
+			createdAt := kv.CreatedAt
+
+			v.KeyID = kv.KeyID
+			v.CreatedAt = &createdAt
+		}
+
+		resp.Versions = append(resp.Versions, v)
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// version returns the key version with the given keyset key ID or nil if the version is unknown.
+func (m *keyMeta) version(keysetKeyID uint32) *keyVersion {
+	if m == nil {
+		return nil
+	}
+
+	for i := range m.Versions {
+		if m.Versions[i].KeysetKeyID == keysetKeyID {
+			return &m.Versions[i]
+		}
+	}
+
+	return nil
+}
+
+// newKeyVersions returns versions for the primary key of the key handle. Returns nil for non-keyset key handles.
+func newKeyVersions(keyID string, kh interface{}, createdAt time.Time) []keyVersion {
+	handle, ok := kh.(*keyset.Handle)
+	if !ok || handle == nil {
+		return nil
+	}
+
+	return []keyVersion{{
+		KeysetKeyID: handle.KeysetInfo().PrimaryKeyId,
+		KeyID:       keyID,
+		CreatedAt:   createdAt,
+	}}
+}
+
+func keyVersionStatus(status tinkpb.KeyStatusType) string {
+	switch status { //nolint:exhaustive
+	case tinkpb.KeyStatusType_ENABLED:
+		return keyVersionStatusEnabled
+	case tinkpb.KeyStatusType_DESTROYED:
+		return keyVersionStatusDestroyed
+	default:
+		return keyVersionStatusDisabled
+	}
+}
+
+// keyVersionHandles returns key handles for enabled versions of the key, the primary version first and then previous
+// versions from the newest to the oldest. Each handle contains a single key, as some primitives (e.g. key unwrapping)
+// use the first key of a keyset instead of the primary one. A key handle of a single key is returned as is.
+func keyVersionHandles(kh interface{}) ([]interface{}, error) {
+	handle, ok := kh.(*keyset.Handle)
+	if !ok || handle == nil || len(handle.KeysetInfo().KeyInfo) <= 1 {
+		return []interface{}{kh}, nil
+	}
+
+	ks := insecurecleartextkeyset.KeysetMaterial(handle)
+
+	var primary *tinkpb.Keyset_Key
+
+	previous := make([]*tinkpb.Keyset_Key, 0, len(ks.Key))
+
+	for i := len(ks.Key) - 1; i >= 0; i-- {
+		switch {
+		case ks.Key[i].Status != tinkpb.KeyStatusType_ENABLED:
+			continue
+		case ks.Key[i].KeyId == ks.PrimaryKeyId:
+			primary = ks.Key[i]
+		default:
+			previous = append(previous, ks.Key[i])
+		}
+	}
+
+	keys := previous
+
+	if primary != nil {
+		keys = append([]*tinkpb.Keyset_Key{primary}, previous...)
+	}
+
+	handles := make([]interface{}, 0, len(keys))
+
+	for _, key := range keys {
+		h, err := insecurecleartextkeyset.Read(&keyset.MemReaderWriter{
+			Keyset: &tinkpb.Keyset{PrimaryKeyId: key.KeyId, Key: []*tinkpb.Keyset_Key{key}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read keyset of version %d: %w", key.KeyId, err)
+		}
+
+		handles = append(handles, h)
+	}
+
+	return handles, nil
+}
+
+// unwrapKey unwraps a key with the primary version of the recipient key and, if it fails, with previous enabled
+// versions. Other primitives (verify, decrypt, verify MAC) don't need it as Tink tries all enabled keys of a keyset.
+func (c *Command) unwrapKey(wk *crypto.RecipientWrappedKey, kh interface{},
+	opts ...crypto.WrapKeyOpts) ([]byte, error) {
+	handles, err := keyVersionHandles(kh)
+	if err != nil {
+		return nil, fmt.Errorf("get key versions: %w", err)
+	}
+
+	if len(handles) == 0 {
+		return nil, fmt.Errorf("%w: key has no enabled versions", errors.ErrConflict)
+	}
+
+	var unwrapErr error
+
+	for _, h := range handles {
+		key, e := c.crypto.UnwrapKey(wk, h, opts...)
+		if e == nil {
+			return key, nil
+		}
+
+		if unwrapErr == nil { // report the error of the primary version
+			unwrapErr = e
+		}
+	}
+
+	return nil, unwrapErr
+}
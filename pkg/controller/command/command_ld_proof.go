@@ -0,0 +1,249 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/proof"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/signer"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/bbsblssignature2020"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2020"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/jsonwebsignature2020"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// JSONWebSignature2020Type is the linked data proof signature type not exported by its signature suite.
+const JSONWebSignature2020Type = "JsonWebSignature2020"
+
+const (
+	verifiableCredentialType   = "VerifiableCredential"
+	verifiablePresentationType = "VerifiablePresentation"
+	assertionMethodPurpose     = "assertionMethod"
+	authenticationPurpose      = "authentication"
+)
+
+// ldProofSuite describes a linked data proof signature suite.
+type ldProofSuite struct {
+	keyTypes       []kms.KeyType
+	representation proof.SignatureRepresentation
+	newSuite       func(opts ...suite.Opt) signer.SignatureSuite
+}
+
+// ldProofSuites are supported linked data proof suites by signature type.
+//
+//nolint:gochecknoglobals
+var ldProofSuites = map[string]ldProofSuite{
+	ed25519signature2018.SignatureType: {
+		keyTypes:       []kms.KeyType{kms.ED25519Type},
+		representation: proof.SignatureJWS,
+		newSuite:       func(opts ...suite.Opt) signer.SignatureSuite { return ed25519signature2018.New(opts...) },
+	},
+	ed25519signature2020.SignatureType: {
+		keyTypes:       []kms.KeyType{kms.ED25519Type},
+		representation: proof.SignatureProofValue,
+		newSuite:       func(opts ...suite.Opt) signer.SignatureSuite { return ed25519signature2020.New(opts...) },
+	},
+	JSONWebSignature2020Type: {
+		keyTypes: []kms.KeyType{
+			kms.ED25519Type,
+			kms.ECDSAP256TypeDER, kms.ECDSAP256TypeIEEEP1363,
+			kms.ECDSAP384TypeDER, kms.ECDSAP384TypeIEEEP1363,
+			kms.ECDSAP521TypeDER, kms.ECDSAP521TypeIEEEP1363,
+		},
+		representation: proof.SignatureJWS,
+		newSuite:       func(opts ...suite.Opt) signer.SignatureSuite { return jsonwebsignature2020.New(opts...) },
+	},
+	bbsblssignature2020.SignatureType: {
+		keyTypes:       []kms.KeyType{kms.BLS12381G2Type},
+		representation: proof.SignatureProofValue,
+		newSuite:       func(opts ...suite.Opt) signer.SignatureSuite { return bbsblssignature2020.New(opts...) },
+	},
+}
+
+// SignLDProof adds a linked data proof created with the key to a verifiable credential or presentation. JSON-LD
+// contexts are resolved with the server's document loader, the same one used for ZCAPs. Proof purpose defaults to
+// assertionMethod for credentials and authentication for presentations.
+func (c *Command) SignLDProof(w io.Writer, r io.Reader) error {
+	var req SignLDProofRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	purpose, err := defaultProofPurpose(req.Document)
+	if err != nil {
+		return err
+	}
+
+	if req.ProofPurpose != "" {
+		purpose = req.ProofPurpose
+	}
+
+	ldSuite := ldProofSuites[req.SignatureType]
+
+	kh, keyType, err := c.getLDProofKey(wr)
+	if err != nil {
+		return err
+	}
+
+	if !containsKeyType(ldSuite.keyTypes, keyType) {
+		return fmt.Errorf("%w: key of type %s can't be used for %s proof", errors.ErrBadRequest, keyType,
+			req.SignatureType)
+	}
+
+	created := time.Now()
+	if req.Created != nil {
+		created = *req.Created
+	}
+
+	docSigner := signer.New(ldSuite.newSuite(suite.WithSigner(&ldProofSigner{cmd: c, kh: kh, keyType: keyType})))
+
+	doc, err := docSigner.Sign(&signer.Context{
+		SignatureType:           req.SignatureType,
+		SignatureRepresentation: ldSuite.representation,
+		Created:                 &created,
+		VerificationMethod:      req.VerificationMethod,
+		Purpose:                 purpose,
+		Domain:                  req.Domain,
+		Challenge:               req.Challenge,
+	}, req.Document, jsonld.WithDocumentLoader(c.documentLoader))
+	if err != nil {
+		return fmt.Errorf("%w: add proof: %s", errors.ErrBadRequest, err)
+	}
+
+	return json.NewEncoder(w).Encode(SignLDProofResponse{Document: doc})
+}
+
+// getLDProofKey returns a handle and type of the key. BBS+ keys sign with multiple messages, so the operation checked
+// against the key's allowed operations depends on the key type.
+func (c *Command) getLDProofKey(wr *WrappedRequest) (interface{}, kms.KeyType, error) {
+	if err := c.resolveKeyID(wr); err != nil {
+		return nil, "", err
+	}
+
+	keyType, err := c.getKeyType(wr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	operation := ActionSign
+	if keyType == kms.BLS12381G2Type {
+		operation = ActionSignMulti
+	}
+
+	kh, err := c.getKeyHandleFromRequest(wr, operation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return kh, keyType, nil
+}
+
+// defaultProofPurpose returns the default proof purpose for the document type. Only verifiable credentials and
+// presentations are accepted.
+func defaultProofPurpose(document json.RawMessage) (string, error) {
+	var doc struct {
+		Type interface{} `json:"type"`
+	}
+
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return "", fmt.Errorf("%w: document must be a json object", errors.ErrBadRequest)
+	}
+
+	var types []interface{}
+
+	switch v := doc.Type.(type) {
+	case string:
+		types = []interface{}{v}
+	case []interface{}:
+		types = v
+	}
+
+	for _, t := range types {
+		switch t {
+		case verifiableCredentialType:
+			return assertionMethodPurpose, nil
+		case verifiablePresentationType:
+			return authenticationPurpose, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: document is not a verifiable credential or presentation", errors.ErrBadRequest)
+}
+
+func containsKeyType(keyTypes []kms.KeyType, keyType kms.KeyType) bool {
+	for _, kt := range keyTypes {
+		if kt == keyType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ldProofSigner signs linked data proofs with the key store's key. ECDSA signatures are converted to the format
+// required by JWS, BBS+ signs each statement of the canonical document as a separate message.
+type ldProofSigner struct {
+	cmd     *Command
+	kh      interface{}
+	keyType kms.KeyType
+}
+
+func (s *ldProofSigner) Sign(data []byte) ([]byte, error) {
+	if s.keyType == kms.BLS12381G2Type {
+		resp, err := s.cmd.signMulti(s.kh, &SignMultiRequest{Messages: splitStatements(data)})
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.Signature, nil
+	}
+
+	resp, err := s.cmd.sign(s.kh, &SignRequest{Message: data})
+	if err != nil {
+		return nil, err
+	}
+
+	if size, ok := ecdsaDERIntegerSizes[s.keyType]; ok {
+		return ecdsaDERToP1363(resp.Signature, size)
+	}
+
+	return resp.Signature, nil
+}
+
+func (s *ldProofSigner) Alg() string {
+	return jwsAlgorithms[s.keyType]
+}
+
+// splitStatements splits canonical N-Quads into statements, skipping empty lines.
+func splitStatements(data []byte) [][]byte {
+	lines := strings.Split(string(data), "\n")
+	statements := make([][]byte, 0, len(lines))
+
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			statements = append(statements, []byte(l))
+		}
+	}
+
+	return statements
+}
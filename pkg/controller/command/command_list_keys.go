@@ -0,0 +1,361 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	keysStoreName        = "keys"
+	keyStoreIDTagName    = "keyStoreID"
+	defaultListKeysLimit = 100
+	maxListKeysLimit     = 1000
+)
+
+// keyStatus is a status of a key in user's key store.
+type keyStatus string
+
+const (
+	keyStatusEnabled         keyStatus = "enabled"
+	keyStatusDisabled        keyStatus = "disabled"
+	keyStatusPendingDeletion keyStatus = "pending_deletion"
+)
+
+// keyMeta is metadata about a key in user's key store saved in the underlying storage.
+type keyMeta struct {
+	ID                string            `json:"id"`
+	KeyStoreID        string            `json:"key_store_id"`
+	KeyType           kms.KeyType       `json:"key_type"`
+	Status            keyStatus         `json:"status,omitempty"`
+	DeletionTime      *time.Time        `json:"deletion_time,omitempty"`
+	Label             string            `json:"label,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	Versions          []keyVersion      `json:"versions,omitempty"`
+	AllowedOperations []string          `json:"allowed_operations,omitempty"` // empty means all operations of the key type
+	NotBefore         *time.Time        `json:"not_before,omitempty"`
+	NotAfter          *time.Time        `json:"not_after,omitempty"`
+	RotationPolicy    *RotationPolicy   `json:"rotation_policy,omitempty"`
+	LastRotatedAt     *time.Time        `json:"last_rotated_at,omitempty"`
+	NextRotationAt    *time.Time        `json:"next_rotation_at,omitempty"`
+	PublicKey         []byte            `json:"public_key,omitempty"` // set for imported public keys only
+	Published         bool              `json:"published,omitempty"`
+	PublishedJWK      json.RawMessage   `json:"published_jwk,omitempty"`
+	RetiredJWKs       []retiredJWK      `json:"retired_jwks,omitempty"` // JWKs of versions replaced by rotation
+	Exportable        bool              `json:"exportable,omitempty"`   // private keyset can be exported
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+func (m *keyMeta) status() keyStatus {
+	if m.Status == "" {
+		return keyStatusEnabled
+	}
+
+	return m.Status
+}
+
+// ListKeys lists keys in the key store.
+func (c *Command) ListKeys(w io.Writer, r io.Reader) error {
+	var req ListKeysRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
+	if err != nil {
+		return fmt.Errorf("resolve key store: %w", err)
+	}
+
+	var metas []*keyMeta
+
+	if req.Tag != "" {
+		metas, err = c.listKeyMetaByTag(wr.KeyStoreID, req.Tag)
+	} else {
+		metas, err = c.listKeyMeta(wr.KeyStoreID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("list key metadata: %w", err)
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultListKeysLimit
+	}
+
+	keys := make([]KeyInfo, 0, limit)
+
+	for i := req.Offset; i < len(metas) && len(keys) < limit; i++ {
+		pub := metas[i].PublicKey
+
+		if pub == nil {
+			var exportErr error
+
+			pub, _, exportErr = ks.ExportPubKeyBytes(metas[i].ID)
+			if exportErr != nil {
+				if !strings.Contains(exportErr.Error(), "failed to get public keyset handle") {
+					return fmt.Errorf("export public key bytes: %w", exportErr)
+				}
+			}
+		}
+
+		keys = append(keys, KeyInfo{
+			KeyID:             metas[i].ID,
+			KeyURL:            c.keyURL(wr.KeyStoreID, metas[i].ID),
+			KeyType:           metas[i].KeyType,
+			Status:            string(metas[i].status()),
+			DeletionTime:      metas[i].DeletionTime,
+			CreatedAt:         metas[i].CreatedAt,
+			PublicKey:         pub,
+			Label:             metas[i].Label,
+			Description:       metas[i].Description,
+			Tags:              metas[i].Tags,
+			AllowedOperations: metas[i].allowedOperations(),
+			NotBefore:         metas[i].NotBefore,
+			NotAfter:          metas[i].NotAfter,
+			RotationPolicy:    metas[i].RotationPolicy,
+			LastRotatedAt:     metas[i].LastRotatedAt,
+			NextRotationAt:    metas[i].NextRotationAt,
+			Published:         metas[i].Published,
+			Exportable:        metas[i].Exportable,
+		})
+	}
+
+	return json.NewEncoder(w).Encode(ListKeysResponse{
+		Keys:  keys,
+		Total: len(metas),
+	})
+}
+
+func (c *Command) keyURL(keyStoreID, keyID string) string {
+	return fmt.Sprintf("%s/%s/keys/%s", c.baseKeyStoreURL, keyStoreID, keyID)
+}
+
+func keyMetaID(keyStoreID, keyID string) string {
+	return keyStoreID + "_" + keyID
+}
+
+func (c *Command) saveKeyMeta(meta *keyMeta) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	tags := []storage.Tag{{Name: keyStoreIDTagName, Value: meta.KeyStoreID}}
+
+	if meta.status() == keyStatusPendingDeletion {
+		tags = append(tags, storage.Tag{Name: pendingDeletionTagName})
+	}
+
+	if meta.RotationPolicy != nil {
+		tags = append(tags, storage.Tag{Name: rotationPolicyTagName})
+	}
+
+	if meta.Published || len(meta.RetiredJWKs) > 0 {
+		tags = append(tags, storage.Tag{Name: publishedTagName, Value: meta.KeyStoreID})
+	}
+
+	var tagNames []string
+
+	for name, value := range meta.Tags {
+		tags = append(tags,
+			storage.Tag{Name: keyTagPrefix + name, Value: meta.KeyStoreID + "=" + value},
+			storage.Tag{Name: keyTagNamePrefix + name, Value: meta.KeyStoreID},
+		)
+		tagNames = append(tagNames, keyTagPrefix+name, keyTagNamePrefix+name)
+	}
+
+	if err = c.registerKeyTagNames(tagNames); err != nil {
+		return err
+	}
+
+	err = c.keyMetaStore.Put(keyMetaID(meta.KeyStoreID, meta.ID), b, tags...)
+	if err != nil {
+		return fmt.Errorf("put: %w", err)
+	}
+
+	return nil
+}
+
+// getKeyMeta returns metadata of the key. Returns storage.ErrDataNotFound if there is no metadata for the key.
+func (c *Command) getKeyMeta(keyStoreID, keyID string) (*keyMeta, error) {
+	b, err := c.keyMetaStore.Get(keyMetaID(keyStoreID, keyID))
+	if err != nil {
+		return nil, err
+	}
+
+	var meta keyMeta
+
+	if err = json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("unmarshal key meta: %w", err)
+	}
+
+	return &meta, nil
+}
+
+// findKeyMeta returns metadata of the key or ErrNotFound if there is no metadata for the key.
+func (c *Command) findKeyMeta(keyStoreID, keyID string) (*keyMeta, error) {
+	meta, err := c.getKeyMeta(keyStoreID, keyID)
+	if err != nil {
+		if goerrors.Is(err, storage.ErrDataNotFound) {
+			return nil, fmt.Errorf("%w: key not found", errors.ErrNotFound)
+		}
+
+		return nil, fmt.Errorf("get key metadata: %w", err)
+	}
+
+	return meta, nil
+}
+
+// backfillKeyMeta saves metadata for the key created before key metadata was introduced, so that the key is listed
+// and migrated with the key store. Key type is known for asymmetric keys only; keys of unknown type are not restricted
+// to operations of the type.
+func (c *Command) backfillKeyMeta(ks kms.KeyManager, keyStoreID, keyID string, kh interface{}) error {
+	_, keyType, err := ks.ExportPubKeyBytes(keyID)
+	if err != nil {
+		keyType = ""
+	}
+
+	createdAt := time.Now().UTC()
+
+	meta := &keyMeta{
+		ID:         keyID,
+		KeyStoreID: keyStoreID,
+		KeyType:    keyType,
+		Status:     keyStatusEnabled,
+		Versions:   newKeyVersions(keyID, kh, createdAt),
+		CreatedAt:  createdAt,
+	}
+
+	if err = c.saveKeyMeta(meta); err != nil {
+		return fmt.Errorf("save key metadata: %w", err)
+	}
+
+	return nil
+}
+
+// listKeyMeta returns metadata of all keys in the key store sorted by creation time.
+func (c *Command) listKeyMeta(keyStoreID string) ([]*keyMeta, error) {
+	return c.queryKeyMeta(fmt.Sprintf("%s:%s", keyStoreIDTagName, keyStoreID))
+}
+
+// listKeyMetaByTag returns metadata of keys in the key store that have the tag. Tag is in "name" or "name=value"
+// format. User's key tags are saved as storage tags with the key store ID in the value, so that only keys of the key
+// store are queried.
+func (c *Command) listKeyMetaByTag(keyStoreID, tag string) ([]*keyMeta, error) {
+	name, value, hasValue := strings.Cut(tag, "=")
+	if hasValue {
+		return c.queryKeyMeta(fmt.Sprintf("%s%s:%s=%s", keyTagPrefix, name, keyStoreID, value))
+	}
+
+	return c.queryKeyMeta(fmt.Sprintf("%s%s:%s", keyTagNamePrefix, name, keyStoreID))
+}
+
+// registerKeyTagNames adds storage tag names of user's key tags to the keys db configuration, as storage backends that
+// index tags (e.g. MongoDB) only index tag names from the configuration and drop indexes of names missing in it.
+// Each name is registered once per server instance.
+func (c *Command) registerKeyTagNames(names []string) error {
+	c.keyTagNamesMutex.Lock()
+	defer c.keyTagNamesMutex.Unlock()
+
+	var missing []string
+
+	for _, name := range names {
+		if _, ok := c.keyTagNames[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	config, err := c.storageProvider.GetStoreConfig(keysStoreName)
+	if err != nil {
+		return fmt.Errorf("get keys db config: %w", err)
+	}
+
+	for _, name := range missing {
+		if !containsString(config.TagNames, name) {
+			config.TagNames = append(config.TagNames, name)
+		}
+	}
+
+	if err = c.storageProvider.SetStoreConfig(keysStoreName, config); err != nil {
+		return fmt.Errorf("set keys db config: %w", err)
+	}
+
+	for _, name := range missing {
+		c.keyTagNames[name] = struct{}{}
+	}
+
+	return nil
+}
+
+// queryKeyMeta returns metadata of keys that satisfy the expression sorted by creation time.
+func (c *Command) queryKeyMeta(expression string) ([]*keyMeta, error) {
+	iter, err := c.keyMetaStore.Query(expression)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer iter.Close() // nolint: errcheck
+
+	var metas []*keyMeta
+
+	for {
+		ok, nextErr := iter.Next()
+		if nextErr != nil {
+			return nil, fmt.Errorf("iterator next: %w", nextErr)
+		}
+
+		if !ok {
+			break
+		}
+
+		b, valueErr := iter.Value()
+		if valueErr != nil {
+			return nil, fmt.Errorf("iterator value: %w", valueErr)
+		}
+
+		var meta keyMeta
+
+		if err = json.Unmarshal(b, &meta); err != nil {
+			return nil, fmt.Errorf("unmarshal key meta: %w", err)
+		}
+
+		metas = append(metas, &meta)
+	}
+
+	sort.Slice(metas, func(i, j int) bool {
+		if metas[i].CreatedAt.Equal(metas[j].CreatedAt) {
+			return metas[i].ID < metas[j].ID
+		}
+
+		return metas[i].CreatedAt.Before(metas[j].CreatedAt)
+	})
+
+	return metas, nil
+}
@@ -0,0 +1,287 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+	"github.com/trustbloc/kms/pkg/storage/metrics"
+)
+
+var logger = log.New("kms/command")
+
+// MigrateKeyStore moves user's keysets of the key store between the server's local storage and EDV (or another EDV
+// vault). Keysets are copied to the new backend and each key is checked to be readable there before key store metadata
+// is switched with a single write. Keysets created while copying are caught up after the switch; if that fails, key
+// store metadata is switched back and the copies are removed. Keys without metadata are migrated only if listed in
+// the request. Old copies and EDV keys of the old vault are deleted last; failures to delete them are logged and
+// don't fail the migration. EDV keys of the old vault are kept if the key store may have keys without metadata, as
+// keysets of such keys can't be enumerated and unlisted ones stay in the old vault.
+func (c *Command) MigrateKeyStore(w io.Writer, r io.Reader) error { //nolint:funlen
+	var req MigrateKeyStoreRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	meta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	if req.EDV == nil && meta.EDV.VaultURL == "" {
+		return fmt.Errorf("%w: key store already uses local storage", errors.ErrConflict)
+	}
+
+	if req.EDV != nil && req.EDV.VaultURL == meta.EDV.VaultURL {
+		return fmt.Errorf("%w: key store already uses edv vault %s", errors.ErrConflict, req.EDV.VaultURL)
+	}
+
+	keyURI, p, err := c.resolveKeyStoreProvider(wr.KeyStoreID, wr.User, wr.SecretShare)
+	if err != nil {
+		return err
+	}
+
+	if err = c.backfillLegacyKeys(keyURI, p, meta.ID, req.KeyIDs); err != nil {
+		return err
+	}
+
+	srcStore, err := c.openBackendKMSStore(meta.EDV)
+	if err != nil {
+		return err
+	}
+
+	dstProvider, edvParams, err := c.prepareMigrationTarget(req.EDV)
+	if err != nil {
+		return err
+	}
+
+	// TODO (#327): Create our own implementation of the KMS storage interface and pass it in here instead of wrapping
+	//  the Aries storage provider.
+	dstStore, err := kms.NewAriesProviderWrapper(dstProvider)
+	if err != nil {
+		return err
+	}
+
+	m := &keyStoreMigration{
+		src:      srcStore,
+		dst:      dstStore,
+		copied:   make(map[string][]byte),
+		keyURI:   keyURI,
+		provider: &keyStoreProvider{storageProvider: dstStore, secretLock: p.secretLock},
+	}
+
+	if err = c.copyKeysets(m, meta.ID); err != nil {
+		m.rollback(c, edvParams)
+
+		return err
+	}
+
+	oldEDV := meta.EDV
+	meta.EDV = edvParams
+
+	if err = c.save(meta); err != nil {
+		m.rollback(c, edvParams)
+
+		return fmt.Errorf("save key store metadata: %w", err)
+	}
+
+	// key manager cached for the old backend must not be used after the switch
+	defer c.invalidateKeyManager(wr.KeyStoreID, wr.User, wr.SecretShare)
+
+	// keysets created in the old backend before the switch
+	if err = c.copyKeysets(m, meta.ID); err != nil {
+		meta.EDV = oldEDV
+
+		// copies are kept if the key store can't be switched back, as the key store uses them
+		if saveErr := c.save(meta); saveErr != nil {
+			logger.Errorf("migrate key store: restore key store metadata: %v", saveErr)
+		} else {
+			m.rollback(c, edvParams)
+		}
+
+		return fmt.Errorf("copy keysets created during migration: %w", err)
+	}
+
+	m.cleanup(c, oldEDV, !meta.mayHaveKeysWithoutMeta())
+
+	return json.NewEncoder(w).Encode(MigrateKeyStoreResponse{MigratedKeys: len(m.copied)})
+}
+
+// backfillLegacyKeys saves metadata for keys created before key metadata was introduced, so that they are migrated.
+func (c *Command) backfillLegacyKeys(keyURI string, p *keyStoreProvider, keyStoreID string, keyIDs []string) error {
+	if len(keyIDs) == 0 {
+		return nil
+	}
+
+	ks, err := c.keyStoreCreator.Create(keyURI, p)
+	if err != nil {
+		return fmt.Errorf("create key store: %w", err)
+	}
+
+	for _, kid := range keyIDs {
+		_, err = c.getKeyMeta(keyStoreID, kid)
+		if err == nil {
+			continue
+		}
+
+		if !goerrors.Is(err, storage.ErrDataNotFound) {
+			return fmt.Errorf("get key metadata: %w", err)
+		}
+
+		kh, getErr := ks.Get(kid)
+		if getErr != nil {
+			return fmt.Errorf("%w: get key %s: %v", errors.ErrBadRequest, kid, getErr)
+		}
+
+		if err = c.backfillKeyMeta(ks, keyStoreID, kid, kh); err != nil {
+			return fmt.Errorf("backfill metadata of key %s: %w", kid, err)
+		}
+	}
+
+	return nil
+}
+
+// keyStoreMigration tracks keysets copied from the source to the destination store during key store migration.
+type keyStoreMigration struct {
+	src      kms.Store
+	dst      kms.Store
+	copied   map[string][]byte // keysets by key ID as read from the source
+	keyURI   string
+	provider *keyStoreProvider // provider of the key store in the destination
+}
+
+// openBackendKMSStore opens the store with user's keysets in the given backend bypassing the cache.
+func (c *Command) openBackendKMSStore(edvParams edvParameters) (kms.Store, error) {
+	storageProvider, err := c.getBackendStorageProvider(edvParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO (#327): Create our own implementation of the KMS storage interface and pass it in here instead of wrapping
+	//  the Aries storage provider.
+	kmsStore, err := kms.NewAriesProviderWrapper(storageProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return kmsStore, nil
+}
+
+// prepareMigrationTarget returns the storage provider for the new backend. New EDV keys are created for the vault.
+func (c *Command) prepareMigrationTarget(edvOpts *EDVOptions) (storage.Provider, edvParameters, error) {
+	if edvOpts == nil {
+		return c.keyStorageProvider, edvParameters{}, nil
+	}
+
+	edvProvider, edvParams, err := c.prepareEDVProvider(edvOpts.VaultURL, edvOpts.Capability)
+	if err != nil {
+		return nil, edvParameters{}, fmt.Errorf("prepare edv provider: %w", err)
+	}
+
+	return metrics.Wrap(edvProvider, "EDV"), edvParams, nil
+}
+
+// copyKeysets copies keysets of the key store that are not yet copied or changed since, and checks that the copied
+// keys can be read from the destination. Imported public keys have no keyset.
+func (c *Command) copyKeysets(m *keyStoreMigration, keyStoreID string) error {
+	metas, err := c.listKeyMeta(keyStoreID)
+	if err != nil {
+		return fmt.Errorf("list key metadata: %w", err)
+	}
+
+	var kids []string
+
+	for _, km := range metas {
+		if km.PublicKey != nil {
+			continue
+		}
+
+		b, getErr := m.src.Get(km.ID)
+		if getErr != nil {
+			return fmt.Errorf("get keyset %s: %w", km.ID, getErr)
+		}
+
+		if prev, ok := m.copied[km.ID]; ok && bytes.Equal(prev, b) {
+			continue
+		}
+
+		if err = m.dst.Put(km.ID, b); err != nil {
+			return fmt.Errorf("put keyset %s: %w", km.ID, err)
+		}
+
+		m.copied[km.ID] = b
+		kids = append(kids, km.ID)
+	}
+
+	if len(kids) == 0 {
+		return nil
+	}
+
+	ks, err := c.keyStoreCreator.Create(m.keyURI, m.provider)
+	if err != nil {
+		return fmt.Errorf("create key store: %w", err)
+	}
+
+	for _, kid := range kids {
+		if _, err = ks.Get(kid); err != nil {
+			return fmt.Errorf("verify migrated key %s: %w", kid, err)
+		}
+	}
+
+	return nil
+}
+
+// rollback removes keysets copied to the destination and EDV keys created for the new vault.
+func (m *keyStoreMigration) rollback(c *Command, edvParams edvParameters) {
+	for kid := range m.copied {
+		if err := m.dst.Delete(kid); err != nil {
+			logger.Warnf("migrate key store: delete copied keyset %s: %v", kid, err)
+		}
+	}
+
+	if err := c.deleteServerKeys(edvParams.RecipientKeyID, edvParams.MACKeyID); err != nil {
+		logger.Warnf("migrate key store: delete edv keys: %v", err)
+	}
+}
+
+// cleanup removes migrated keysets from the source and, if all keysets of the key store were migrated, EDV keys of
+// the old vault. Keysets left in the old vault can't be decrypted without its EDV keys.
+func (m *keyStoreMigration) cleanup(c *Command, oldEDV edvParameters, allMigrated bool) {
+	for kid := range m.copied {
+		if err := m.src.Delete(kid); err != nil {
+			logger.Warnf("migrate key store: delete old keyset %s: %v", kid, err)
+		}
+	}
+
+	if !allMigrated {
+		if oldEDV.VaultURL != "" {
+			logger.Warnf("migrate key store: edv keys of vault %s are kept as keys without metadata may be left there",
+				oldEDV.VaultURL)
+		}
+
+		return
+	}
+
+	if err := c.deleteServerKeys(oldEDV.RecipientKeyID, oldEDV.MACKeyID); err != nil {
+		logger.Warnf("migrate key store: delete old edv keys: %v", err)
+	}
+}
@@ -12,31 +12,56 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/insecurecleartextkeyset"
 	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/signature"
+	"github.com/google/tink/go/subtle/random"
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdh"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose/jwk"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/ld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	mockcrypto "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockldstore "github.com/hyperledger/aries-framework-go/pkg/mock/ld"
 	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	ldstore "github.com/hyperledger/aries-framework-go/pkg/store/ld"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"golang.org/x/crypto/curve25519"
 
 	. "github.com/trustbloc/kms/pkg/controller/command"
 )
@@ -620,6 +645,199 @@ func TestCommand_CreateKey(t *testing.T) {
 		require.Equal(t, "/key_store_id/keys/key_id", resp.KeyURL)
 	})
 
+	t.Run("Success with label, description and tags", func(t *testing.T) {
+		p := newStoreConfigProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			CreateKeyID: "key_id",
+		}))
+
+		req, err := json.Marshal(CreateKeyRequest{
+			KeyType:     kms.ED25519,
+			Label:       "Issuer key",
+			Description: "Key for signing credentials",
+			Tags:        map[string]string{"purpose": "vc-issuance", "env": "prod"},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		entry := p.Store.Store["key_store_id_key_id"]
+		require.Contains(t, entry.Tags, storage.Tag{Name: "tag_purpose", Value: "key_store_id=vc-issuance"})
+		require.Contains(t, entry.Tags, storage.Tag{Name: "tagname_purpose", Value: "key_store_id"})
+		require.Contains(t, entry.Tags, storage.Tag{Name: "tag_env", Value: "key_store_id=prod"})
+		require.Contains(t, entry.Tags, storage.Tag{Name: "tagname_env", Value: "key_store_id"})
+		require.Subset(t, p.configs["keys"].TagNames,
+			[]string{"keyStoreID", "published", "tag_purpose", "tagname_purpose", "tag_env", "tagname_env"})
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(entry.Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, "Issuer key", meta["label"])
+		require.Equal(t, "Key for signing credentials", meta["description"])
+	})
+
+	t.Run("Success with allowed operations", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			CreateKeyID: "key_id",
+		}))
+
+		req, err := json.Marshal(CreateKeyRequest{
+			KeyType:           kms.ED25519,
+			AllowedOperations: []string{"verify"},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id_key_id"].Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{"verify"}, meta["allowed_operations"])
+	})
+
+	t.Run("Success with validity window", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			CreateKeyID: "key_id",
+		}))
+
+		notBefore := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		notAfter := notBefore.Add(24 * time.Hour)
+
+		req, err := json.Marshal(CreateKeyRequest{
+			KeyType:   kms.ED25519,
+			NotBefore: &notBefore,
+			NotAfter:  &notAfter,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id_key_id"].Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, "2030-01-01T00:00:00Z", meta["not_before"])
+		require.Equal(t, "2030-01-02T00:00:00Z", meta["not_after"])
+	})
+
+	t.Run("Fail with invalid rotation policy", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(CreateKeyRequest{
+			KeyType:        kms.AES256GCM,
+			RotationPolicy: &RotationPolicy{IntervalDays: -1},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			"validate request: validation failed: rotation interval must be between 0 and 3650 days")
+	})
+
+	t.Run("Fail with empty validity window", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		notBefore := time.Now()
+		notAfter := notBefore.Add(-time.Hour)
+
+		req, err := json.Marshal(CreateKeyRequest{
+			KeyType:   kms.ED25519,
+			NotBefore: &notBefore,
+			NotAfter:  &notAfter,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "validate request: validation failed: not_after must be after not_before")
+	})
+
+	t.Run("Fail with operation not supported by key type", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(CreateKeyRequest{
+			KeyType:           kms.ED25519,
+			AllowedOperations: []string{"sign", "encrypt"},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			`validate request: validation failed: operation "encrypt" is not supported by key type ED25519`)
+	})
+
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(CreateKeyRequest{
+			KeyType: kms.ED25519,
+			Tags:    map[string]string{"purpose": "vc:issuance"},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			`validate request: validation failed: tag "purpose" must not contain ":&" characters`)
+	})
+
 	t.Run("Success with EDV storage and Shamir secret lock", func(t *testing.T) {
 		keyStoreData := []byte(`{
 		  "id": "key_store_id",
@@ -674,6 +892,29 @@ func TestCommand_CreateKey(t *testing.T) {
 		require.Equal(t, "/key_store_id/keys/key_id", resp.KeyURL)
 	})
 
+	t.Run("Fail to set rotation policy with shamir secret lock", func(t *testing.T) {
+		cmd, err := New(&Config{
+			StorageProvider: mockstorage.NewMockStoreProvider(),
+			ShamirProvider:  NewMockShamirProvider(gomock.NewController(t)),
+		})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(CreateKeyRequest{
+			KeyType:        kms.ED25519,
+			RotationPolicy: &RotationPolicy{IntervalDays: 30},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: scheduled rotation is not supported with shamir secret lock")
+	})
+
 	t.Run("Fail to decode wrapped request", func(t *testing.T) {
 		cmd, err := New(&Config{
 			StorageProvider: mockstorage.NewMockStoreProvider(),
@@ -857,6 +1098,42 @@ func TestCommand_ExportKey(t *testing.T) {
 		require.Equal(t, "key_type", resp.KeyType)
 	})
 
+	t.Run("Success with label, description and tags", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withMemStorage(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{
+			KeyType:     kms.ED25519Type,
+			Label:       "label",
+			Description: "description",
+			Tags:        map[string]string{"purpose": "vc-issuance"},
+		})
+
+		resp, err := execute[ExportKeyResponse](cmd.ExportKey, keyID, nil)
+		require.NoError(t, err)
+		require.Equal(t, "label", resp.Label)
+		require.Equal(t, "description", resp.Description)
+		require.Equal(t, map[string]string{"purpose": "vc-issuance"}, resp.Tags)
+		require.Equal(t, []string{"sign", "verify"}, resp.AllowedOperations)
+	})
+
+	t.Run("Success with validity window", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		notBefore := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		notAfter := notBefore.Add(24 * time.Hour)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{
+			KeyType:   kms.ED25519Type,
+			NotBefore: &notBefore,
+			NotAfter:  &notAfter,
+		})
+
+		resp, err := execute[ExportKeyResponse](cmd.ExportKey, keyID, nil)
+		require.NoError(t, err)
+		require.Equal(t, notBefore, *resp.NotBefore)
+		require.Equal(t, notAfter, *resp.NotAfter)
+	})
+
 	t.Run("Fail to export public key bytes", func(t *testing.T) {
 		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
 			ExportPubKeyBytesErr: errors.New("export key error"),
@@ -873,710 +1150,927 @@ func TestCommand_ExportKey(t *testing.T) {
 		err = cmd.ExportKey(&buf, bytes.NewBuffer(wr))
 		require.EqualError(t, err, "export public key bytes: export key error")
 	})
-}
 
-func TestCommand_ImportKey(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		tests := []struct {
-			kt kms.KeyType
-		}{
-			{kms.ED25519},
-			{kms.ECDSAP256TypeDER},
-			{kms.ECDSAP384TypeDER},
-			{kms.ECDSAP521TypeDER},
-			{kms.ECDSAP256TypeIEEEP1363},
-			{kms.ECDSAP384TypeIEEEP1363},
-			{kms.ECDSAP521TypeIEEEP1363},
-		}
+	t.Run("Success with format", func(t *testing.T) {
+		edPub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
 
-		for _, tt := range tests {
-			t.Run(fmt.Sprintf("import_%s", tt.kt), func(t *testing.T) {
-				cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
-					ImportPrivateKeyID: "key_id",
-				}))
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
 
-				pk := createPrivateKey(t, tt.kt)
+		spki, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+		require.NoError(t, err)
 
-				der, err := x509.MarshalPKCS8PrivateKey(pk)
-				require.NoError(t, err)
+		x25519Pub := random.GetRandomBytes(32)
 
-				req, err := json.Marshal(ImportKeyRequest{
-					KeyID:   "key_id",
-					KeyType: tt.kt,
-					Key:     der,
-				})
-				require.NoError(t, err)
+		cmd := createCmdWithLocalKMS(t)
 
-				wr, err := json.Marshal(WrappedRequest{
-					KeyStoreID: "key_store_id",
-					Request:    req,
-				})
-				require.NoError(t, err)
+		edKeyID := importPublicKey(t, cmd, kms.ED25519Type, edPub)
+		ecKeyID := importPublicKey(t, cmd, kms.ECDSAP256TypeDER, spki)
+		x25519KeyID := importPublicKey(t, cmd, kms.X25519ECDHKWType, x25519Pub)
 
-				var buf bytes.Buffer
+		resp := exportKey(t, cmd, edKeyID, FormatJWK)
+		require.Equal(t, FormatJWK, resp.Format)
+		require.Equal(t, edKeyID, resp.JWK.KeyID)
+		require.Equal(t, "EdDSA", resp.JWK.Algorithm)
+		require.Equal(t, "Ed25519", resp.JWK.Crv)
+		require.Equal(t, edPub, resp.JWK.Key)
 
-				err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
-				require.NoError(t, err)
+		resp = exportKey(t, cmd, ecKeyID, FormatJWK)
+		require.Equal(t, "ES256", resp.JWK.Algorithm)
+		require.Equal(t, "P-256", resp.JWK.Crv)
+		require.True(t, ecKey.PublicKey.Equal(resp.JWK.Key))
 
-				var resp ImportKeyResponse
+		resp = exportKey(t, cmd, x25519KeyID, FormatJWK)
+		require.Equal(t, "ECDH-ES+A256KW", resp.JWK.Algorithm)
+		require.Equal(t, "X25519", resp.JWK.Crv)
 
-				err = json.Unmarshal(buf.Bytes(), &resp)
-				require.NoError(t, err)
-				require.Equal(t, "/key_store_id/keys/key_id", resp.KeyURL)
-			})
-		}
-	})
+		resp = exportKey(t, cmd, edKeyID, FormatPEM)
 
-	t.Run("Fail to parse private key", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t))
+		block, _ := pem.Decode([]byte(resp.PEM))
+		require.NotNil(t, block)
+		require.Equal(t, "PUBLIC KEY", block.Type)
 
-		req, err := json.Marshal(ImportKeyRequest{
-			KeyID:   "key_id",
-			KeyType: kms.ED25519,
-			Key:     nil,
-		})
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 		require.NoError(t, err)
+		require.Equal(t, edPub, pub)
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			Request:    req,
-		})
-		require.NoError(t, err)
+		resp = exportKey(t, cmd, ecKeyID, FormatPEM)
 
-		var buf bytes.Buffer
+		block, _ = pem.Decode([]byte(resp.PEM))
+		require.NotNil(t, block)
+		require.Equal(t, spki, block.Bytes)
 
-		err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
-		require.Contains(t, err.Error(), "parse private key")
+		resp = exportKey(t, cmd, x25519KeyID, FormatPEM)
+
+		block, _ = pem.Decode([]byte(resp.PEM))
+		require.NotNil(t, block)
+		require.Equal(t, x25519Pub, block.Bytes[len(block.Bytes)-32:])
+
+		resp = exportKey(t, cmd, edKeyID, FormatMultibase)
+		require.True(t, strings.HasPrefix(resp.Multibase, "z6Mk"))
+
+		resp = exportKey(t, cmd, edKeyID, FormatDIDKey)
+		require.Equal(t, "did:key:"+exportKey(t, cmd, edKeyID, FormatMultibase).Multibase, resp.DIDKey)
+
+		resp = exportKey(t, cmd, ecKeyID, FormatDIDKey)
+		require.True(t, strings.HasPrefix(resp.DIDKey, "did:key:zDn"))
+
+		resp = exportKey(t, cmd, x25519KeyID, FormatDIDKey)
+		require.True(t, strings.HasPrefix(resp.DIDKey, "did:key:z6LS"))
 	})
 
-	t.Run("Not supported key type", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t))
+	t.Run("Fail to export BLS12381G2 key as PEM", func(t *testing.T) {
+		bbsPub, _, err := bbs12381g2pub.GenerateKeyPair(sha256.New, nil)
+		require.NoError(t, err)
 
-		req, err := json.Marshal(ImportKeyRequest{
-			KeyID:   "key_id",
-			KeyType: "invalid",
-		})
+		bbsKey, err := bbsPub.Marshal()
+		require.NoError(t, err)
+
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := importPublicKey(t, cmd, kms.BLS12381G2Type, bbsKey)
+
+		require.True(t, strings.HasPrefix(exportKey(t, cmd, keyID, FormatDIDKey).DIDKey, "did:key:zUC7"))
+
+		req, err := json.Marshal(ExportKeyRequest{Format: FormatPEM})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
+			KeyID:      keyID,
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
-
-		err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "not supported key type: invalid")
+		err = cmd.ExportKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "format public key: bad request: pem format is not supported for BLS12381_G2 keys")
 	})
 
-	t.Run("Fail to import private key", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
-			ImportPrivateKeyErr: errors.New("import private key error"),
-		}))
-
-		pk := createPrivateKey(t, kms.ED25519)
-
-		der, err := x509.MarshalPKCS8PrivateKey(pk)
+	t.Run("Invalid format", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
 		require.NoError(t, err)
 
-		req, err := json.Marshal(ImportKeyRequest{
-			KeyID:   "key_id",
-			KeyType: kms.ED25519,
-			Key:     der,
-		})
+		req, err := json.Marshal(ExportKeyRequest{Format: "x509"})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
-
-		err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "import private key: import private key error")
+		err = cmd.ExportKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			"validate request: validation failed: format must be one of raw, jwk, pem, multibase, didkey")
 	})
 }
 
-func TestCommand_RotateKey(t *testing.T) {
+func TestCommand_ListKeys(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
-			RotateKeyID: "rotate_key_id",
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		now := time.Now()
+
+		for i, kid := range []string{"key1", "key2", "key3"} {
+			putKeyMeta(t, p, "key_store_id", kid, keyCreatedAt(now.Add(time.Duration(i)*time.Second)))
+		}
+
+		putKeyMeta(t, p, "other_key_store_id", "key4", keyCreatedAt(now))
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			ExportPubKeyBytesValue: []byte("public key bytes"),
 		}))
 
-		req, err := json.Marshal(RotateKeyRequest{
-			KeyType: kms.ED25519,
+		req, err := json.Marshal(ListKeysRequest{
+			Limit:  2,
+			Offset: 1,
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
 
-		err = cmd.RotateKey(&buf, bytes.NewBuffer(wr))
+		err = cmd.ListKeys(&buf, bytes.NewBuffer(wr))
 		require.NoError(t, err)
 
-		var resp RotateKeyResponse
+		var resp ListKeysResponse
 
 		err = json.Unmarshal(buf.Bytes(), &resp)
 		require.NoError(t, err)
-		require.Contains(t, resp.KeyURL, "rotate_key_id")
+		require.Equal(t, 3, resp.Total)
+		require.Len(t, resp.Keys, 2)
+		require.Equal(t, "key2", resp.Keys[0].KeyID)
+		require.Equal(t, "key3", resp.Keys[1].KeyID)
+		require.Equal(t, "/key_store_id/keys/key3", resp.Keys[1].KeyURL)
+		require.Equal(t, kms.ED25519Type, resp.Keys[1].KeyType)
+		require.Equal(t, []byte("public key bytes"), resp.Keys[1].PublicKey)
+		require.Equal(t, "enabled", resp.Keys[1].Status)
 	})
 
-	t.Run("Fail to decode wrapped request", func(t *testing.T) {
-		cmd, err := New(&Config{
-			StorageProvider: mockstorage.NewMockStoreProvider(),
-		})
-		require.NoError(t, err)
-		require.NotNil(t, cmd)
-
-		err = cmd.RotateKey(nil, bytes.NewBuffer(nil))
-		require.EqualError(t, err, "unwrap request: internal error: decode wrapped request")
-	})
+	t.Run("Key without metadata is listed after use", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
 
-	t.Run("Fail to get a key store meta data", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
+		cmd := createCmdWithLocalKMS(t, withStorageProvider(p))
 
-		metrics := NewMockMetricsProvider(ctrl)
-		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).Times(1)
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
 
-		cmd, err := New(&Config{
-			StorageProvider: mockstorage.NewMockStoreProvider(),
-			MetricsProvider: metrics,
-		})
-		require.NoError(t, err)
-		require.NotNil(t, cmd)
+		// key created before key metadata was introduced
+		require.Contains(t, p.Store.Store, "key_store_id_"+keyID)
+		delete(p.Store.Store, "key_store_id_"+keyID)
 
-		req, err := json.Marshal(RotateKeyRequest{
-			KeyType: kms.ED25519,
-		})
+		req, err := json.Marshal(SignRequest{Message: []byte("test message")})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
+			KeyID:      keyID,
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
-
-		err = cmd.RotateKey(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "resolve key store: get key store meta: data not found")
-	})
-
-	t.Run("Fail to rotate a key", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
-			RotateKeyErr: errors.New("rotate key error"),
-		}))
-
-		req, err := json.Marshal(RotateKeyRequest{
-			KeyType: kms.ED25519,
-		})
+		err = cmd.Sign(&bytes.Buffer{}, bytes.NewBuffer(wr))
 		require.NoError(t, err)
 
-		wr, err := json.Marshal(WrappedRequest{
+		wr, err = json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
+			Request:    []byte("{}"),
 		})
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
 
-		err = cmd.RotateKey(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "rotate key: rotate key error")
-	})
-}
+		err = cmd.ListKeys(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
 
-func TestCommand_Sign(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			SignValue: []byte("signature"),
-		}))
+		var resp ListKeysResponse
 
-		req, err := json.Marshal(SignRequest{
-			Message: []byte("test message"),
-		})
+		err = json.Unmarshal(buf.Bytes(), &resp)
 		require.NoError(t, err)
+		require.Equal(t, 1, resp.Total)
+		require.Equal(t, keyID, resp.Keys[0].KeyID)
+		require.Equal(t, kms.ED25519Type, resp.Keys[0].KeyType)
+		require.Equal(t, "enabled", resp.Keys[0].Status)
+	})
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
+	t.Run("Success with tag filter", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withMemStorage(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{
+			KeyType:     kms.ED25519Type,
+			Label:       "label",
+			Description: "description",
+			Tags:        map[string]string{"purpose": "vc-issuance"},
 		})
+		createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type, Tags: map[string]string{"purpose": "encryption"}})
+		createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		resp, err := execute[ListKeysResponse](cmd.ListKeys, "", &ListKeysRequest{Tag: "purpose=vc-issuance"})
 		require.NoError(t, err)
+		require.Equal(t, 1, resp.Total)
+		require.Equal(t, keyID, resp.Keys[0].KeyID)
+		require.Equal(t, "label", resp.Keys[0].Label)
+		require.Equal(t, "description", resp.Keys[0].Description)
+		require.Equal(t, map[string]string{"purpose": "vc-issuance"}, resp.Keys[0].Tags)
+	})
 
-		var buf bytes.Buffer
+	t.Run("Tag filter doesn't match keys of other key stores", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
 
-		err = cmd.Sign(&buf, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+		putKeyMeta(t, p, "other_key_store_id", "key_id",
+			keyAttributes("label", "description", map[string]string{"purpose": "vc-issuance"}))
 
-		var resp SignResponse
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p))
 
-		err = json.Unmarshal(buf.Bytes(), &resp)
+		resp, err := execute[ListKeysResponse](cmd.ListKeys, "", &ListKeysRequest{Tag: "purpose=vc-issuance"})
 		require.NoError(t, err)
-		require.Equal(t, []byte("signature"), resp.Signature)
+		require.Zero(t, resp.Total)
 	})
 
-	t.Run("Fail to sign", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			SignErr: errors.New("sign error"),
-		}))
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{
+			StorageProvider: mockstorage.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
 
-		req, err := json.Marshal(SignRequest{
-			Message: []byte("test message"),
+		req, err := json.Marshal(ListKeysRequest{
+			Offset: -1,
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
-
-		err = cmd.Sign(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "sign: sign error")
+		err = cmd.ListKeys(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "validate request: validation failed: offset must be non-negative")
 	})
-}
-
-func TestCommand_Verify(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
-		require.NoError(t, err)
 
-		cmd := createCmd(t, gomock.NewController(t),
-			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
-			withCrypto(&mockcrypto.Crypto{}),
-		)
+	t.Run("Fail to query key metadata", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.ErrQuery = errors.New("query error")
 
-		req, err := json.Marshal(VerifyRequest{
-			Signature: []byte("signature"),
-			Message:   []byte("test message"),
-		})
-		require.NoError(t, err)
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p))
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
+			Request:    []byte("{}"),
 		})
 		require.NoError(t, err)
 
-		err = cmd.Verify(nil, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+		err = cmd.ListKeys(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "list key metadata: query: query error")
 	})
 
-	t.Run("Fail to get public key from handle", func(t *testing.T) {
-		badKH, err := keyset.NewHandle(aead.KMSEnvelopeAEADKeyTemplate("badUrl", nil))
-		require.NoError(t, err)
+	t.Run("Fail to export public key bytes", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
 
-		cmd := createCmd(t, gomock.NewController(t),
-			withKeyManager(&mockkms.KeyManager{GetKeyValue: badKH}),
-			withCrypto(&mockcrypto.Crypto{}),
-		)
+		putKeyMeta(t, p, "key_store_id", "key1")
 
-		req, err := json.Marshal(VerifyRequest{
-			Signature: []byte("signature"),
-			Message:   []byte("test message"),
-		})
-		require.NoError(t, err)
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			ExportPubKeyBytesErr: errors.New("export key error"),
+		}))
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
+			Request:    []byte("{}"),
 		})
 		require.NoError(t, err)
 
-		err = cmd.Verify(nil, bytes.NewBuffer(wr))
-		require.Error(t, err)
+		err = cmd.ListKeys(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "export public key bytes: export key error")
 	})
+}
 
-	t.Run("Fail to verify", func(t *testing.T) {
-		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
-		require.NoError(t, err)
+func TestCommand_ImportKey(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		tests := []struct {
+			kt kms.KeyType
+		}{
+			{kms.ED25519},
+			{kms.ECDSAP256TypeDER},
+			{kms.ECDSAP384TypeDER},
+			{kms.ECDSAP521TypeDER},
+			{kms.ECDSAP256TypeIEEEP1363},
+			{kms.ECDSAP384TypeIEEEP1363},
+			{kms.ECDSAP521TypeIEEEP1363},
+			{kms.NISTP256ECDHKWType},
+			{kms.NISTP384ECDHKWType},
+			{kms.NISTP521ECDHKWType},
+		}
 
-		cmd := createCmd(t, gomock.NewController(t),
-			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
-			withCrypto(&mockcrypto.Crypto{VerifyErr: errors.New("verify error")}),
-		)
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("import_%s", tt.kt), func(t *testing.T) {
+				cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
+					ImportPrivateKeyID: "key_id",
+				}))
 
-		req, err := json.Marshal(VerifyRequest{
-			Signature: []byte("signature"),
-			Message:   []byte("test message"),
-		})
-		require.NoError(t, err)
+				pk := createPrivateKey(t, tt.kt)
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
-		})
-		require.NoError(t, err)
+				der, err := x509.MarshalPKCS8PrivateKey(pk)
+				require.NoError(t, err)
 
-		err = cmd.Verify(nil, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "verify: verify error")
+				req, err := json.Marshal(ImportKeyRequest{
+					KeyID:   "key_id",
+					KeyType: tt.kt,
+					Key:     der,
+				})
+				require.NoError(t, err)
+
+				wr, err := json.Marshal(WrappedRequest{
+					KeyStoreID: "key_store_id",
+					Request:    req,
+				})
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+
+				err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
+				require.NoError(t, err)
+
+				var resp ImportKeyResponse
+
+				err = json.Unmarshal(buf.Bytes(), &resp)
+				require.NoError(t, err)
+				require.Equal(t, "/key_store_id/keys/key_id", resp.KeyURL)
+			})
+		}
 	})
-}
 
-func TestCommand_Encrypt(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			EncryptValue:      []byte("ciphertext"),
-			EncryptNonceValue: []byte("nonce"),
-		}))
+	t.Run("Fail to parse private key", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t))
 
-		req, err := json.Marshal(EncryptRequest{
-			Message: []byte("test message"),
+		req, err := json.Marshal(ImportKeyRequest{
+			KeyID:   "key_id",
+			KeyType: kms.ED25519,
+			Key:     nil,
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
 
-		err = cmd.Encrypt(&buf, bytes.NewBuffer(wr))
-		require.NoError(t, err)
-
-		var resp EncryptResponse
-
-		err = json.Unmarshal(buf.Bytes(), &resp)
-		require.NoError(t, err)
-		require.Equal(t, []byte("ciphertext"), resp.Ciphertext)
-		require.Equal(t, []byte("nonce"), resp.Nonce)
+		err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
+		require.Contains(t, err.Error(), "parse private key")
 	})
 
-	t.Run("Fail to encrypt", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			EncryptErr: errors.New("encrypt error"),
-		}))
+	t.Run("Not supported key type", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t))
 
-		req, err := json.Marshal(EncryptRequest{
-			Message: []byte("test message"),
+		req, err := json.Marshal(ImportKeyRequest{
+			KeyID:   "key_id",
+			KeyType: "invalid",
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
 
-		err = cmd.Encrypt(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "encrypt: encrypt error")
+		err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not supported key type: invalid")
 	})
-}
 
-func TestCommand_Decrypt(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			DecryptValue: []byte("plaintext"),
-		}))
+	t.Run("Fail to import private key", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
+			ImportPrivateKeyErr: errors.New("import private key error"),
+		}))
 
-		req, err := json.Marshal(DecryptRequest{
-			Ciphertext:     []byte("ciphertext"),
-			AssociatedData: []byte("ad"),
-			Nonce:          []byte("nonce"),
+		pk := createPrivateKey(t, kms.ED25519)
+
+		der, err := x509.MarshalPKCS8PrivateKey(pk)
+		require.NoError(t, err)
+
+		req, err := json.Marshal(ImportKeyRequest{
+			KeyID:   "key_id",
+			KeyType: kms.ED25519,
+			Key:     der,
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
 
-		err = cmd.Decrypt(&buf, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+		err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "import private key: import private key error")
+	})
 
-		var resp DecryptResponse
+	t.Run("Success with JWK", func(t *testing.T) {
+		tests := []struct {
+			name string
+			kt   kms.KeyType
+			jwk  string
+		}{
+			{
+				name: "Ed25519",
+				kt:   kms.ED25519Type,
+				jwk: `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",` +
+					`"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`,
+			},
+			{
+				name: "P-256",
+				kt:   kms.NISTP256ECDHKWType,
+				jwk: `{"kty":"EC","crv":"P-256","x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",` +
+					`"y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",` +
+					`"d":"jpsQnnGQmL-YBIffH1136cspYG6-0iY7X1fCE9-E9LI"}`,
+			},
+		}
 
-		err = json.Unmarshal(buf.Bytes(), &resp)
-		require.NoError(t, err)
-		require.Equal(t, []byte("plaintext"), resp.Plaintext)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
+					ImportPrivateKeyID: "key_id",
+				}))
+
+				req, err := json.Marshal(ImportKeyRequest{
+					KeyType: tt.kt,
+					Key:     []byte(tt.jwk),
+				})
+				require.NoError(t, err)
+
+				wr, err := json.Marshal(WrappedRequest{
+					KeyStoreID: "key_store_id",
+					Request:    req,
+				})
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+
+				err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
+				require.NoError(t, err)
+				require.Contains(t, buf.String(), "/key_store_id/keys/key_id")
+			})
+		}
 	})
 
-	t.Run("Fail to decrypt", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			DecryptErr: errors.New("decrypt error"),
+	t.Run("Success with BLS12381G2 key", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
+			ImportPrivateKeyID: "key_id",
 		}))
 
-		req, err := json.Marshal(DecryptRequest{
-			Ciphertext:     []byte("ciphertext"),
-			AssociatedData: []byte("ad"),
-			Nonce:          []byte("nonce"),
+		_, pk, err := bbs12381g2pub.GenerateKeyPair(sha256.New, nil)
+		require.NoError(t, err)
+
+		key, err := pk.Marshal()
+		require.NoError(t, err)
+
+		req, err := json.Marshal(ImportKeyRequest{
+			KeyType: kms.BLS12381G2Type,
+			Key:     key,
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
 
-		err = cmd.Decrypt(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "decrypt: decrypt error")
+		err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
 	})
-}
 
-func TestCommand_ComputeMAC(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			ComputeMACValue: []byte("mac"),
-		}))
+	t.Run("Success with key material", func(t *testing.T) {
+		hmacKey := random.GetRandomBytes(32)
 
-		req, err := json.Marshal(ComputeMACRequest{
-			Data: []byte("data"),
-		})
-		require.NoError(t, err)
+		x25519Key := random.GetRandomBytes(32)
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
-		})
+		x25519Pub, err := curve25519.X25519(x25519Key, curve25519.Basepoint)
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
+		tests := []struct {
+			name   string
+			kt     kms.KeyType
+			key    []byte
+			verify func(t *testing.T, cmd *Command, wr *WrappedRequest)
+		}{
+			{
+				name: "AES256GCM",
+				kt:   kms.AES256GCMType,
+				key:  random.GetRandomBytes(32),
+				verify: func(t *testing.T, cmd *Command, wr *WrappedRequest) {
+					t.Helper()
 
-		err = cmd.ComputeMAC(&buf, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+					wr.Request = []byte(`{"message":"dGVzdA=="}`)
 
-		var resp ComputeMACResponse
+					b, err := json.Marshal(wr)
+					require.NoError(t, err)
 
-		err = json.Unmarshal(buf.Bytes(), &resp)
-		require.NoError(t, err)
-		require.Equal(t, []byte("mac"), resp.MAC)
-	})
+					require.NoError(t, cmd.Encrypt(&bytes.Buffer{}, bytes.NewBuffer(b)))
+				},
+			},
+			{
+				name: "AES128GCM JWK",
+				kt:   kms.AES128GCMType,
+				key:  []byte(`{"kty":"oct","k":"GawgguFyGrWKav7AX4VKUg"}`),
+			},
+			{
+				name: "HMACSHA256Tag256",
+				kt:   kms.HMACSHA256Tag256Type,
+				key:  hmacKey,
+				verify: func(t *testing.T, cmd *Command, wr *WrappedRequest) {
+					t.Helper()
 
-	t.Run("Fail to compute MAC", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			ComputeMACErr: errors.New("compute mac error"),
-		}))
+					data := []byte("test data")
 
-		req, err := json.Marshal(ComputeMACRequest{
-			Data: []byte("data"),
-		})
-		require.NoError(t, err)
+					wr.Request, err = json.Marshal(ComputeMACRequest{Data: data})
+					require.NoError(t, err)
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
-		})
-		require.NoError(t, err)
+					b, err := json.Marshal(wr)
+					require.NoError(t, err)
 
-		var buf bytes.Buffer
+					var buf bytes.Buffer
 
-		err = cmd.ComputeMAC(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "compute mac: compute mac error")
+					require.NoError(t, cmd.ComputeMAC(&buf, bytes.NewBuffer(b)))
+
+					var resp ComputeMACResponse
+
+					require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+
+					mac := hmac.New(sha256.New, hmacKey)
+					mac.Write(data)
+
+					// tink MAC is prefixed with the output prefix of the key
+					require.True(t, bytes.HasSuffix(resp.MAC, mac.Sum(nil)))
+				},
+			},
+			{
+				name: "X25519ECDHKW",
+				kt:   kms.X25519ECDHKWType,
+				key:  x25519Key,
+				verify: func(t *testing.T, cmd *Command, wr *WrappedRequest) {
+					t.Helper()
+
+					b, err := json.Marshal(wr)
+					require.NoError(t, err)
+
+					var buf bytes.Buffer
+
+					require.NoError(t, cmd.ExportKey(&buf, bytes.NewBuffer(b)))
+
+					var resp ExportKeyResponse
+
+					require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+
+					var pub crypto.PublicKey
+
+					require.NoError(t, json.Unmarshal(resp.PublicKey, &pub))
+					require.Equal(t, x25519Pub, pub.X)
+				},
+			},
+			{
+				name: "X25519ECDHKW JWK",
+				kt:   kms.X25519ECDHKWType,
+				key: []byte(fmt.Sprintf(`{"kty":"OKP","crv":"X25519","x":"%s","d":"%s"}`,
+					base64.RawURLEncoding.EncodeToString(x25519Pub), base64.RawURLEncoding.EncodeToString(x25519Key))),
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cmd := createCmdWithLocalKMS(t)
+
+				req, err := json.Marshal(ImportKeyRequest{
+					KeyType: tt.kt,
+					Key:     tt.key,
+				})
+				require.NoError(t, err)
+
+				wr, err := json.Marshal(WrappedRequest{
+					KeyStoreID: "key_store_id",
+					Request:    req,
+				})
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+
+				err = cmd.ImportKey(&buf, bytes.NewBuffer(wr))
+				require.NoError(t, err)
+
+				var resp ImportKeyResponse
+
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+
+				keyID := resp.KeyURL[strings.LastIndex(resp.KeyURL, "/")+1:]
+				require.NotEmpty(t, keyID)
+
+				if tt.verify != nil {
+					tt.verify(t, cmd, &WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID})
+				}
+			})
+		}
 	})
-}
 
-func TestCommand_VerifyMAC(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{}))
+	t.Run("Invalid key material", func(t *testing.T) {
+		tests := []struct {
+			name string
+			kt   kms.KeyType
+			key  []byte
+			err  string
+		}{
+			{
+				name: "invalid AES key size",
+				kt:   kms.AES256GCMType,
+				key:  random.GetRandomBytes(16),
+				err:  "AES key must be 32 bytes",
+			},
+			{
+				name: "short HMAC key",
+				kt:   kms.HMACSHA256Tag256Type,
+				key:  random.GetRandomBytes(8),
+				err:  "HMAC key must be at least 16 bytes",
+			},
+			{
+				name: "invalid X25519 key size",
+				kt:   kms.X25519ECDHKWType,
+				key:  random.GetRandomBytes(16),
+				err:  "invalid X25519 private key size",
+			},
+			{
+				name: "key doesn't match key type",
+				kt:   kms.ED25519Type,
+				key:  []byte(`{"kty":"oct","k":"GawgguFyGrWKav7AX4VKUg"}`),
+				err:  "key doesn't match key type ED25519",
+			},
+			{
+				name: "public JWK",
+				kt:   kms.ED25519Type,
+				key:  []byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`),
+				err:  "jwk is not a private key",
+			},
+		}
 
-		req, err := json.Marshal(VerifyMACRequest{
-			Data: []byte("data"),
-			MAC:  []byte("mac"),
-		})
-		require.NoError(t, err)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cmd := createCmdWithLocalKMS(t)
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
-		})
-		require.NoError(t, err)
+				req, err := json.Marshal(ImportKeyRequest{
+					KeyType: tt.kt,
+					Key:     tt.key,
+				})
+				require.NoError(t, err)
 
-		err = cmd.VerifyMAC(nil, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+				wr, err := json.Marshal(WrappedRequest{
+					KeyStoreID: "key_store_id",
+					Request:    req,
+				})
+				require.NoError(t, err)
+
+				err = cmd.ImportKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+				require.Contains(t, err.Error(), tt.err)
+			})
+		}
 	})
 
-	t.Run("Fail to verify MAC", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			VerifyMACErr: errors.New("verify mac error"),
-		}))
+	t.Run("Secp256k1 key is not supported", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t))
 
-		req, err := json.Marshal(VerifyMACRequest{
-			Data: []byte("data"),
-			MAC:  []byte("mac"),
+		req, err := json.Marshal(ImportKeyRequest{
+			KeyType: kms.ECDSASecp256k1TypeIEEEP1363,
+			Key:     []byte("key"),
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		err = cmd.VerifyMAC(nil, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "verify mac: verify mac error")
+		err = cmd.ImportKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not supported key type: ECDSASecp256k1IEEEP1363")
 	})
-}
 
-func TestCommand_SignMulti(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			BBSSignValue: []byte("signature"),
-		}))
+	t.Run("Key store can't read imported keyset", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
 
-		req, err := json.Marshal(SignMultiRequest{
-			Messages: [][]byte{
-				[]byte("test message 1"),
-				[]byte("test message 2"),
-			},
+		creator := NewMockKeyStoreCreator(gomock.NewController(t))
+		creator.EXPECT().Create(gomock.Any(), gomock.Any()).
+			Return(&mockkms.KeyManager{GetKeyErr: errors.New("get key error")}, nil).
+			Times(1)
+
+		cmd := createCmdWithLocalKMS(t, func(c *Config) {
+			c.KeyStorageProvider = p
+			c.KeyStoreCreator = creator
+		})
+
+		req, err := json.Marshal(ImportKeyRequest{
+			KeyType: kms.AES256GCMType,
+			Key:     random.GetRandomBytes(32),
+			KeyID:   "imported_key_id",
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
-
-		err = cmd.SignMulti(&buf, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+		err = cmd.ImportKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "import private key: read imported keyset: get key error")
 
-		var resp SignMultiResponse
+		for k := range p.Store.Store {
+			require.NotContains(t, k, "imported_key_id")
+		}
+	})
 
-		err = json.Unmarshal(buf.Bytes(), &resp)
+	t.Run("Success with public key", func(t *testing.T) {
+		edPub, _, err := ed25519.GenerateKey(rand.Reader)
 		require.NoError(t, err)
-		require.Equal(t, []byte("signature"), resp.Signature)
-	})
 
-	t.Run("Fail to sign multi", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			BBSSignErr: errors.New("sign error"),
-		}))
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
 
-		req, err := json.Marshal(SignMultiRequest{
-			Messages: [][]byte{
-				[]byte("test message 1"),
-				[]byte("test message 2"),
-			},
-		})
+		spki, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
 		require.NoError(t, err)
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
+		ecJWK, err := json.Marshal(map[string]string{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
+		bbsPub, _, err := bbs12381g2pub.GenerateKeyPair(sha256.New, nil)
+		require.NoError(t, err)
 
-		err = cmd.SignMulti(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "sign multi: sign error")
-	})
-}
+		bbsKey, err := bbsPub.Marshal()
+		require.NoError(t, err)
 
-func TestCommand_VerifyMulti(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
-		require.NoError(t, err)
+		tests := []struct {
+			name string
+			kt   kms.KeyType
+			key  []byte
+			ops  []string
+		}{
+			{"ED25519 raw", kms.ED25519Type, edPub, []string{ActionVerify}},
+			{"ECDSA DER PEM", kms.ECDSAP256TypeDER, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki}),
+				[]string{ActionVerify}},
+			{"ECDSA IEEE P1363 JWK", kms.ECDSAP256TypeIEEEP1363, ecJWK, []string{ActionVerify}},
+			{"BLS12381G2 raw", kms.BLS12381G2Type, bbsKey, []string{ActionVerifyMulti, ActionVerifyProof}},
+			{"NIST P-256 ECDH-KW SPKI", kms.NISTP256ECDHKWType, spki, []string{ActionWrap}},
+			{"X25519 raw", kms.X25519ECDHKWType, random.GetRandomBytes(32), []string{ActionWrap}},
+		}
 
-		cmd := createCmd(t, gomock.NewController(t),
-			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
-			withCrypto(&mockcrypto.Crypto{}),
-		)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cmd := createCmdWithLocalKMS(t)
 
-		req, err := json.Marshal(VerifyMultiRequest{
-			Messages: [][]byte{
-				[]byte("test message 1"),
-				[]byte("test message 2"),
+				keyID := importPublicKey(t, cmd, tt.kt, tt.key)
+
+				wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID})
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+
+				require.NoError(t, cmd.ExportKey(&buf, bytes.NewBuffer(wr)))
+
+				var resp ExportKeyResponse
+
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+				require.Equal(t, string(tt.kt), resp.KeyType)
+				require.NotEmpty(t, resp.PublicKey)
+				require.Equal(t, tt.ops, resp.AllowedOperations)
+			})
+		}
+	})
+
+	t.Run("Invalid public key", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  ImportKeyRequest
+			err  string
+		}{
+			{
+				name: "symmetric key type",
+				req:  ImportKeyRequest{KeyType: kms.AES256GCMType, Key: random.GetRandomBytes(32), Public: true},
+				err:  "public key of type AES256GCM can't be imported",
 			},
-			Signature: []byte("signature"),
-		})
+			{
+				name: "operation not supported by public key",
+				req: ImportKeyRequest{KeyType: kms.ED25519Type, Key: random.GetRandomBytes(32), Public: true,
+					AllowedOperations: []string{ActionSign}},
+				err: `operation "sign" is not supported by public key`,
+			},
+			{
+				name: "rotation policy",
+				req: ImportKeyRequest{KeyType: kms.ED25519Type, Key: random.GetRandomBytes(32), Public: true,
+					RotationPolicy: &RotationPolicy{IntervalDays: 30}},
+				err: "rotation policy can't be set for public key",
+			},
+			{
+				name: "invalid key size",
+				req:  ImportKeyRequest{KeyType: kms.ED25519Type, Key: random.GetRandomBytes(16), Public: true},
+				err:  "invalid ED25519 public key size",
+			},
+			{
+				name: "key doesn't match key type",
+				req: ImportKeyRequest{KeyType: kms.ECDSAP384TypeDER, Public: true,
+					Key: []byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`)},
+				err: "key doesn't match key type ECDSAP384DER",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cmd := createCmdWithLocalKMS(t)
+
+				req, err := json.Marshal(tt.req)
+				require.NoError(t, err)
+
+				wr, err := json.Marshal(WrappedRequest{
+					KeyStoreID: "key_store_id",
+					Request:    req,
+				})
+				require.NoError(t, err)
+
+				err = cmd.ImportKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.err)
+			})
+		}
+	})
+
+	t.Run("Public key already exists", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		key := random.GetRandomBytes(32)
+
+		importPublicKey(t, cmd, kms.X25519ECDHKWType, key)
+
+		req, err := json.Marshal(ImportKeyRequest{KeyType: kms.X25519ECDHKWType, Key: key, Public: true})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		err = cmd.VerifyMulti(nil, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+		err = cmd.ImportKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "already exists")
 	})
+}
 
-	t.Run("Fail to verify a signature", func(t *testing.T) {
-		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
-		require.NoError(t, err)
+func TestCommand_RotateKey(t *testing.T) {
+	t.Run("Fail to rotate imported public key", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
 
-		cmd := createCmd(t, gomock.NewController(t),
-			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
-			withCrypto(&mockcrypto.Crypto{
-				BBSVerifyErr: errors.New("verify error"),
-			}),
-		)
+		keyID := importPublicKey(t, cmd, kms.ED25519Type, random.GetRandomBytes(32))
 
-		req, err := json.Marshal(VerifyMultiRequest{
-			Messages: [][]byte{
-				[]byte("test message 1"),
-				[]byte("test message 2"),
-			},
-			Signature: []byte("signature"),
-		})
+		req, err := json.Marshal(RotateKeyRequest{KeyType: kms.ED25519Type})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
+			KeyID:      keyID,
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		err = cmd.VerifyMulti(nil, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "verify multi: verify error")
+		err = cmd.RotateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: imported public key can't be rotated")
 	})
-}
 
-func TestCommand_DeriveProof(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			DeriveProofValue: []byte("proof"),
+		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
+			RotateKeyID: "rotate_key_id",
 		}))
 
-		req, err := json.Marshal(DeriveProofRequest{
-			Messages: [][]byte{
-				[]byte("test message 1"),
-				[]byte("test message 2"),
-			},
-			Signature:       []byte("signature"),
-			Nonce:           []byte("nonce"),
-			RevealedIndexes: []int{1},
+		req, err := json.Marshal(RotateKeyRequest{
+			KeyType: kms.ED25519,
 		})
 		require.NoError(t, err)
 
@@ -1589,30 +2083,29 @@ func TestCommand_DeriveProof(t *testing.T) {
 
 		var buf bytes.Buffer
 
-		err = cmd.DeriveProof(&buf, bytes.NewBuffer(wr))
+		err = cmd.RotateKey(&buf, bytes.NewBuffer(wr))
 		require.NoError(t, err)
 
-		var resp DeriveProofResponse
+		var resp RotateKeyResponse
 
 		err = json.Unmarshal(buf.Bytes(), &resp)
 		require.NoError(t, err)
-		require.Equal(t, []byte("proof"), resp.Proof)
+		require.Contains(t, resp.KeyURL, "rotate_key_id")
 	})
 
-	t.Run("Fail to derive proof", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			DeriveProofError: errors.New("derive proof error"),
+	t.Run("Allowed operations are narrowed to the new key type", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.Store["key_store_id_key_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_id","key_store_id":"key_store_id","key_type":"ED25519",` +
+				`"allowed_operations":["sign","encrypt"]}`),
+		}
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			RotateKeyID: "rotate_key_id",
 		}))
 
-		req, err := json.Marshal(DeriveProofRequest{
-			Messages: [][]byte{
-				[]byte("test message 1"),
-				[]byte("test message 2"),
-			},
-			Signature:       []byte("signature"),
-			Nonce:           []byte("nonce"),
-			RevealedIndexes: []int{1},
-		})
+		req, err := json.Marshal(RotateKeyRequest{KeyType: kms.AES256GCMType})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
@@ -1622,25 +2115,31 @@ func TestCommand_DeriveProof(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
+		err = cmd.RotateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.NoError(t, err)
 
-		err = cmd.DeriveProof(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "derive proof: derive proof error")
+		var meta struct {
+			AllowedOperations []string `json:"allowed_operations"`
+		}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id_rotate_key_id"].Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, []string{"encrypt"}, meta.AllowedOperations)
 	})
-}
 
-func TestCommand_VerifyProof(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{}))
+	t.Run("Fail if no allowed operation is supported by the new key type", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.Store["key_store_id_key_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_id","key_store_id":"key_store_id","key_type":"ED25519",` +
+				`"allowed_operations":["sign"]}`),
+		}
 
-		req, err := json.Marshal(VerifyProofRequest{
-			Messages: [][]byte{
-				[]byte("test message 1"),
-				[]byte("test message 2"),
-			},
-			Proof: []byte("proof"),
-			Nonce: []byte("nonce"),
-		})
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			RotateKeyID: "rotate_key_id",
+		}))
+
+		req, err := json.Marshal(RotateKeyRequest{KeyType: kms.AES256GCMType})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
@@ -1650,22 +2149,33 @@ func TestCommand_VerifyProof(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = cmd.VerifyProof(nil, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+		err = cmd.RotateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			"bad request: none of allowed operations [sign] is supported by key type AES256GCM")
+		require.Contains(t, p.Store.Store, "key_store_id_key_id")
 	})
 
-	t.Run("Fail to verify proof", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			VerifyProofErr: errors.New("verify proof error"),
+	t.Run("Success with version history", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		kh, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		p.Store.Store["key_store_id_key_id"] = mockstorage.DBEntry{
+			Value: []byte(fmt.Sprintf(`{"id":"key_id","key_store_id":"key_store_id","versions":[{"keyset_key_id":%d,`+
+				`"key_id":"key_id","created_at":"2022-01-01T00:00:00Z"}]}`, kh.KeysetInfo().PrimaryKeyId)),
+		}
+
+		rotatedKH := rotateKeyset(t, kh, aead.AES256GCMKeyTemplate())
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			RotateKeyID:    "rotate_key_id",
+			RotateKeyValue: rotatedKH,
 		}))
 
-		req, err := json.Marshal(VerifyProofRequest{
-			Messages: [][]byte{
-				[]byte("test message 1"),
-				[]byte("test message 2"),
-			},
-			Proof: []byte("proof"),
-			Nonce: []byte("nonce"),
+		req, err := json.Marshal(RotateKeyRequest{
+			KeyType: kms.AES256GCMType,
 		})
 		require.NoError(t, err)
 
@@ -1676,105 +2186,137 @@ func TestCommand_VerifyProof(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = cmd.VerifyProof(nil, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "verify proof: verify proof error")
-	})
-}
+		err = cmd.RotateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.NoError(t, err)
 
-func TestCommand_Easy(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
+		var meta struct {
+			Versions []struct {
+				KeysetKeyID uint32 `json:"keyset_key_id"`
+				KeyID       string `json:"key_id"`
+			} `json:"versions"`
+		}
 
-		cryptoBox := NewMockCryptoBox(ctrl)
-		cryptoBox.EXPECT().Easy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return([]byte("ciphertext"), nil).Times(1)
+		err = json.Unmarshal(p.Store.Store["key_store_id_rotate_key_id"].Value, &meta)
+		require.NoError(t, err)
+		require.Len(t, meta.Versions, 2)
+		require.Equal(t, "key_id", meta.Versions[0].KeyID)
+		require.Equal(t, "rotate_key_id", meta.Versions[1].KeyID)
+		require.Equal(t, rotatedKH.KeysetInfo().PrimaryKeyId, meta.Versions[1].KeysetKeyID)
+	})
 
-		creator := NewMockCryptoBoxCreator(ctrl)
-		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+	t.Run("Success with alias", func(t *testing.T) {
+		p := newStoreConfigProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
 
-		cmd := createCmd(t, ctrl, withCryptoBoxCreator(creator))
+		putKeyMeta(t, p.MockStoreProvider, "key_store_id", "key_id",
+			keyAttributes("label", "description", map[string]string{"purpose": "vc-issuance"}))
+		putAlias(t, p.MockStoreProvider, "key_store_id", "issuer", "key_id")
 
-		req, err := json.Marshal(EasyRequest{
-			Payload:  []byte("payload"),
-			Nonce:    []byte("nonce"),
-			TheirPub: []byte("their pub"),
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			RotateKeyID: "rotate_key_id",
+		}))
+
+		req, err := json.Marshal(RotateKeyRequest{
+			KeyType: kms.ED25519,
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
+			KeyID:      "alias:issuer",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
+		err = cmd.RotateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.NoError(t, err)
 
-		err = cmd.WrapKey(&buf, bytes.NewBuffer(wr))
+		require.NotContains(t, p.Store.Store, "key_store_id_key_id")
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id_rotate_key_id"].Value, &meta)
 		require.NoError(t, err)
+		require.Equal(t, "label", meta["label"])
 
-		var resp EasyResponse
+		var alias map[string]interface{}
 
-		err = json.Unmarshal(buf.Bytes(), &resp)
+		err = json.Unmarshal(p.Store.Store["key_store_id_issuer"].Value, &alias)
 		require.NoError(t, err)
-		require.Equal(t, []byte("ciphertext"), resp.Ciphertext)
+		require.Equal(t, "rotate_key_id", alias["key_id"])
 	})
 
-	t.Run("Fail to seal a payload", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
+	t.Run("Fail to decode wrapped request", func(t *testing.T) {
+		cmd, err := New(&Config{
+			StorageProvider: mockstorage.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, cmd)
 
-		cryptoBox := NewMockCryptoBox(ctrl)
-		cryptoBox.EXPECT().Easy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(nil, errors.New("easy error")).Times(1)
+		err = cmd.RotateKey(nil, bytes.NewBuffer(nil))
+		require.EqualError(t, err, "unwrap request: internal error: decode wrapped request")
+	})
 
-		creator := NewMockCryptoBoxCreator(ctrl)
-		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+	t.Run("Fail to get a key store meta data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
 
-		cmd := createCmd(t, ctrl, withCryptoBoxCreator(creator))
+		metrics := NewMockMetricsProvider(ctrl)
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).Times(1)
 
-		req, err := json.Marshal(EasyRequest{
-			Payload:  []byte("payload"),
-			Nonce:    []byte("nonce"),
-			TheirPub: []byte("their pub"),
+		cmd, err := New(&Config{
+			StorageProvider: mockstorage.NewMockStoreProvider(),
+			MetricsProvider: metrics,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, cmd)
+
+		req, err := json.Marshal(RotateKeyRequest{
+			KeyType: kms.ED25519,
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
 
-		err = cmd.WrapKey(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "unwrap wrap request: easy: easy error")
+		err = cmd.RotateKey(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "resolve key store: get key store meta: data not found")
 	})
-}
 
-func TestCommand_EasyOpen(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
+	t.Run("Key keeps metadata if fail to save metadata of the rotated key", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
 
-		cryptoBox := NewMockCryptoBox(ctrl)
-		cryptoBox.EXPECT().EasyOpen(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return([]byte("plaintext"), nil).Times(1)
+		putKeyMeta(t, p, "key_store_id", "key_id")
 
-		creator := NewMockCryptoBoxCreator(ctrl)
-		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			RotateKeyID: "rotate_key_id",
+		}))
 
-		cmd := createCmd(t, gomock.NewController(t), withCryptoBoxCreator(creator))
+		p.Store.ErrPut = errors.New("put error")
 
-		req, err := json.Marshal(EasyOpenRequest{
-			Ciphertext: []byte("payload"),
-			Nonce:      []byte("nonce"),
-			TheirPub:   []byte("their pub"),
-			MyPub:      []byte("my pub"),
-		})
-		require.NoError(t, err)
+		_, err := execute[RotateKeyResponse](cmd.RotateKey, "key_id", &RotateKeyRequest{KeyType: kms.ED25519})
+		require.EqualError(t, err, "save key metadata: put: put error")
 
-		wr, err := json.Marshal(WrappedRequest{
+		require.Contains(t, p.Store.Store, "key_store_id_key_id")
+		require.NotContains(t, p.Store.Store, "key_store_id_rotate_key_id")
+	})
+
+	t.Run("Fail to rotate a key", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
+			RotateKeyErr: errors.New("rotate key error"),
+		}))
+
+		req, err := json.Marshal(RotateKeyRequest{
+			KeyType: kms.ED25519,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
 			KeyID:      "key_id",
 			Request:    req,
@@ -1783,34 +2325,102 @@ func TestCommand_EasyOpen(t *testing.T) {
 
 		var buf bytes.Buffer
 
-		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+		err = cmd.RotateKey(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "rotate key: rotate key error")
+	})
+}
+
+func TestCommand_ListKeyVersions(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		kh := rotateKeyset(t, nil, aead.AES256GCMKeyTemplate())
+		info := kh.KeysetInfo()
+
+		p.Store.Store["key_store_id_key_id"] = mockstorage.DBEntry{
+			Value: []byte(fmt.Sprintf(`{"id":"key_id","key_store_id":"key_store_id","versions":[{"keyset_key_id":%d,`+
+				`"key_id":"key_id","created_at":"2022-01-01T00:00:00Z"}]}`, info.PrimaryKeyId)),
+		}
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyManager(&mockkms.KeyManager{
+			GetKeyValue: kh,
+		}))
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
 		require.NoError(t, err)
 
-		var resp EasyOpenResponse
+		var buf bytes.Buffer
+
+		err = cmd.ListKeyVersions(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp ListKeyVersionsResponse
 
 		err = json.Unmarshal(buf.Bytes(), &resp)
 		require.NoError(t, err)
-		require.Equal(t, []byte("plaintext"), resp.Plaintext)
+		require.Len(t, resp.Versions, 2)
+
+		require.Equal(t, 1, resp.Versions[0].Version)
+		require.Equal(t, "enabled", resp.Versions[0].Status)
+		require.False(t, resp.Versions[0].Primary)
+		require.Empty(t, resp.Versions[0].KeyID)
+		require.Nil(t, resp.Versions[0].CreatedAt)
+
+		require.Equal(t, 2, resp.Versions[1].Version)
+		require.Equal(t, "enabled", resp.Versions[1].Status)
+		require.True(t, resp.Versions[1].Primary)
+		require.Equal(t, "key_id", resp.Versions[1].KeyID)
+		require.NotNil(t, resp.Versions[1].CreatedAt)
 	})
 
-	t.Run("Fail to unseal a ciphertext", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
+	t.Run("Key doesn't support versions", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t))
 
-		cryptoBox := NewMockCryptoBox(ctrl)
-		cryptoBox.EXPECT().SealOpen(gomock.Any(), gomock.Any()).Return(nil, errors.New("seal open error")).Times(1)
-		cryptoBox.EXPECT().EasyOpen(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(nil, errors.New("easy open error")).Times(1)
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
 
-		creator := NewMockCryptoBoxCreator(ctrl)
-		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+		err = cmd.ListKeyVersions(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: key doesn't support versions")
+	})
 
-		cmd := createCmd(t, gomock.NewController(t), withCryptoBoxCreator(creator))
+	t.Run("Fail to get key", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
+			GetKeyErr: errors.New("get key error"),
+		}))
 
-		req, err := json.Marshal(EasyOpenRequest{
-			Ciphertext: []byte("payload"),
-			Nonce:      []byte("nonce"),
-			TheirPub:   []byte("their pub"),
-			MyPub:      []byte("my pub"),
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.ListKeyVersions(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "get key: get key error")
+	})
+}
+
+func TestCommand_UpdateKey(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := newStoreConfigProvider()
+
+		putKeyMeta(t, p.MockStoreProvider, "key_store_id", "key_id",
+			keyAttributes("label", "description", map[string]string{"purpose": "vc-issuance"}))
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		label := "new label"
+
+		req, err := json.Marshal(UpdateKeyRequest{
+			Label: &label,
+			Tags:  map[string]string{"env": "prod"},
 		})
 		require.NoError(t, err)
 
@@ -1821,32 +2431,33 @@ func TestCommand_EasyOpen(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
+		err = cmd.UpdateKey(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
 
-		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
-		// since both easyopen and sealopen are both part of UnwrapKey, if easyopen fails, sealopen will be executed
-		// and will be logged as last error regardless (we try easyopen first then sealopen in this order)
-		require.EqualError(t, err, "unwrapKey cryptobox request invalid: seal open: seal open error")
+		entry := p.Store.Store["key_store_id_key_id"]
+		require.Contains(t, entry.Tags, storage.Tag{Name: "tag_env", Value: "key_store_id=prod"})
+		require.NotContains(t, entry.Tags, storage.Tag{Name: "tag_purpose", Value: "key_store_id=vc-issuance"})
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(entry.Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, "new label", meta["label"])
+		require.Equal(t, "description", meta["description"])
 	})
-}
 
-func TestCommand_SealOpen(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
+	t.Run("Success with rotation policy", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
 
-		cryptoBox := NewMockCryptoBox(ctrl)
-		cryptoBox.EXPECT().SealOpen(gomock.Any(), gomock.Any()).Return([]byte("plaintext"), nil).Times(1)
-		cryptoBox.EXPECT().EasyOpen(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil,
-			errors.New("easy open error")).Times(1)
+		createdAt := time.Now().UTC().Add(-time.Hour)
 
-		creator := NewMockCryptoBoxCreator(ctrl)
-		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+		putKeyMeta(t, p, "key_store_id", "key_id", keyCreatedAt(createdAt))
 
-		cmd := createCmd(t, gomock.NewController(t), withCryptoBoxCreator(creator))
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
 
-		req, err := json.Marshal(SealOpenRequest{
-			Ciphertext: []byte("payload"),
-			MyPub:      []byte("my pub"),
+		req, err := json.Marshal(UpdateKeyRequest{
+			RotationPolicy: &RotationPolicy{IntervalDays: 90},
 		})
 		require.NoError(t, err)
 
@@ -1857,297 +2468,5405 @@ func TestCommand_SealOpen(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
+		err = cmd.UpdateKey(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
 
-		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+		entry := p.Store.Store["key_store_id_key_id"]
+		require.Contains(t, entry.Tags, storage.Tag{Name: "rotationPolicy"})
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(entry.Value, &meta)
 		require.NoError(t, err)
+		require.Equal(t, createdAt.AddDate(0, 0, 90).Format(time.RFC3339Nano), meta["next_rotation_at"])
 
-		var resp SealOpenResponse
+		req, err = json.Marshal(UpdateKeyRequest{
+			RotationPolicy: &RotationPolicy{IntervalDays: 0},
+		})
+		require.NoError(t, err)
 
-		err = json.Unmarshal(buf.Bytes(), &resp)
+		wr, err = json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
 		require.NoError(t, err)
-		require.Equal(t, []byte("plaintext"), resp.Plaintext)
+
+		err = cmd.UpdateKey(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		entry = p.Store.Store["key_store_id_key_id"]
+		require.NotContains(t, entry.Tags, storage.Tag{Name: "rotationPolicy"})
+		require.NotContains(t, string(entry.Value), "next_rotation_at")
 	})
 
-	t.Run("Fail to decrypt a ciphertext", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
 
-		cryptoBox := NewMockCryptoBox(ctrl)
-		cryptoBox.EXPECT().SealOpen(gomock.Any(), gomock.Any()).Return(nil, errors.New("seal open error")).Times(1)
-		cryptoBox.EXPECT().EasyOpen(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil,
-			errors.New("easy open error")).Times(1)
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    []byte("{}"),
+		})
+		require.NoError(t, err)
 
-		creator := NewMockCryptoBoxCreator(ctrl)
-		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+		err = cmd.UpdateKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			"validate request: validation failed: label, description, tags, rotation policy or publish must be set")
+	})
 
-		cmd := createCmd(t, gomock.NewController(t), withCryptoBoxCreator(creator))
+	t.Run("Fail to set rotation policy with shamir secret lock", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
 
-		req, err := json.Marshal(SealOpenRequest{
-			Ciphertext: []byte("payload"),
-			MyPub:      []byte("my pub"),
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd, err := New(&Config{
+			StorageProvider: p,
+			ShamirProvider:  NewMockShamirProvider(gomock.NewController(t)),
 		})
 		require.NoError(t, err)
 
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
 			KeyID:      "key_id",
-			Request:    req,
+			Request:    []byte(`{"rotation_policy":{"interval_days":30}}`),
 		})
 		require.NoError(t, err)
 
-		var buf bytes.Buffer
+		err = cmd.UpdateKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: scheduled rotation is not supported with shamir secret lock")
+	})
 
-		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "unwrapKey cryptobox request invalid: seal open: seal open error")
+	t.Run("Key not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    []byte(`{"tags":{"env":"prod"}}`),
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key not found")
 	})
 }
 
-func TestCommand_WrapKey(t *testing.T) {
+func TestCommand_SetAlias(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			WrapValue: &crypto.RecipientWrappedKey{},
-		}))
+		p := mockstorage.NewMockStoreProvider()
 
-		req, err := json.Marshal(WrapKeyRequest{
-			CEK:             []byte("cek"),
-			APU:             []byte("apu"),
-			APV:             []byte("apv"),
-			RecipientPubKey: &crypto.PublicKey{},
-			Tag:             []byte("tag"),
+		putKeyMeta(t, p, "key_store_id", "key1")
+		putKeyMeta(t, p, "key_store_id", "key2")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		for _, kid := range []string{"key1", "key2"} {
+			req, marshalErr := json.Marshal(SetAliasRequest{Name: "issuer", KeyID: kid})
+			require.NoError(t, marshalErr)
+
+			wr, marshalErr := json.Marshal(WrappedRequest{
+				KeyStoreID: "key_store_id",
+				Request:    req,
+			})
+			require.NoError(t, marshalErr)
+
+			var buf bytes.Buffer
+
+			err = cmd.SetAlias(&buf, bytes.NewBuffer(wr))
+			require.NoError(t, err)
+
+			var resp AliasInfo
+
+			err = json.Unmarshal(buf.Bytes(), &resp)
+			require.NoError(t, err)
+			require.Equal(t, "issuer", resp.Name)
+			require.Equal(t, kid, resp.KeyID)
+			require.Equal(t, "/key_store_id/keys/"+kid, resp.KeyURL)
+		}
+
+		require.Contains(t, p.Store.Store["key_store_id_issuer"].Tags,
+			storage.Tag{Name: "keyID", Value: "key_store_id_key2"})
+	})
+
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(SetAliasRequest{Name: "issuer:key", KeyID: "key_id"})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.SetAlias(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "validate request: validation failed: alias name must be 1 to 256 characters "+
+			"long and contain only letters, digits, '-' and '_'")
+	})
+
+	t.Run("Key not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(SetAliasRequest{Name: "issuer", KeyID: "key_id"})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
 		})
 		require.NoError(t, err)
 
+		err = cmd.SetAlias(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key not found")
+	})
+}
+
+func TestCommand_GetAlias(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		putAlias(t, p, "key_store_id", "issuer", "key_id")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(AliasRequest{Name: "issuer"})
+		require.NoError(t, err)
+
 		wr, err := json.Marshal(WrappedRequest{
 			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
 			Request:    req,
 		})
 		require.NoError(t, err)
 
 		var buf bytes.Buffer
 
-		err = cmd.WrapKey(&buf, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+		err = cmd.GetAlias(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp AliasInfo
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, "key_id", resp.KeyID)
+	})
+
+	t.Run("Alias not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    []byte(`{"name":"issuer"}`),
+		})
+		require.NoError(t, err)
+
+		err = cmd.GetAlias(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: alias not found")
+	})
+}
+
+func TestCommand_ListAliases(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		putAlias(t, p, "key_store_id", "verifier", "key1")
+		putAlias(t, p, "key_store_id", "issuer", "key2")
+		putAlias(t, p, "other_key_store_id", "issuer", "key3")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.ListAliases(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp ListAliasesResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Len(t, resp.Aliases, 2)
+		require.Equal(t, "issuer", resp.Aliases[0].Name)
+		require.Equal(t, "verifier", resp.Aliases[1].Name)
+	})
+
+	t.Run("Fail to query aliases", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.ErrQuery = errors.New("query error")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.ListAliases(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "list aliases: query: query error")
+	})
+}
+
+func TestCommand_DeleteAlias(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		putAlias(t, p, "key_store_id", "issuer", "key_id")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    []byte(`{"name":"issuer"}`),
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteAlias(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+		require.NotContains(t, p.Store.Store, "key_store_id_issuer")
+	})
+
+	t.Run("Alias not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    []byte(`{"name":"issuer"}`),
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteAlias(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: alias not found")
+	})
+}
+
+func TestCommand_DeleteKey(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.Store["key_id"] = mockstorage.DBEntry{Value: []byte("keyset")}
+
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    []byte("{}"),
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.DeleteKey(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp DeleteKeyResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, "deleted", resp.Status)
+		require.Nil(t, resp.DeletionTime)
+
+		require.NotContains(t, p.Store.Store, "key_id")
+		require.NotContains(t, p.Store.Store, "key_store_id_key_id")
+	})
+
+	t.Run("Success with pending window", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.Store["key_id"] = mockstorage.DBEntry{Value: []byte("keyset")}
+
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(DeleteKeyRequest{
+			PendingWindowDays: 7,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.DeleteKey(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp DeleteKeyResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, "pending_deletion", resp.Status)
+		require.NotNil(t, resp.DeletionTime)
+		require.WithinDuration(t, time.Now().AddDate(0, 0, 7), *resp.DeletionTime, time.Minute)
+
+		require.Contains(t, p.Store.Store, "key_id")
+		require.Contains(t, p.Store.Store["key_store_id_key_id"].Tags, storage.Tag{Name: "pendingDeletion"})
+	})
+
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(DeleteKeyRequest{
+			PendingWindowDays: 31,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			"validate request: validation failed: pending window must be between 0 and 30 days")
+	})
+
+	t.Run("Key not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    []byte("{}"),
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key not found")
+	})
+
+	t.Run("Fail to delete keyset", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.ErrDelete = errors.New("delete error")
+
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    []byte("{}"),
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "destroy key: delete keyset: delete error")
+	})
+}
+
+func TestCommand_CancelKeyDeletion(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		putKeyMeta(t, p, "key_store_id", "key_id", keyPendingDeletion(time.Now().Add(time.Hour)))
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.CancelKeyDeletion(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		entry := p.Store.Store["key_store_id_key_id"]
+		require.NotContains(t, entry.Tags, storage.Tag{Name: "pendingDeletion"})
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(entry.Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, "enabled", meta["status"])
+		require.NotContains(t, meta, "deletion_time")
+	})
+
+	t.Run("Key is not pending deletion", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.CancelKeyDeletion(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "conflict: key is not pending deletion")
+	})
+
+	t.Run("Key not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.CancelKeyDeletion(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key not found")
+	})
+}
+
+func TestCommand_PurgeExpiredKeys(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.Store["key1"] = mockstorage.DBEntry{Value: []byte("keyset")}
+		p.Store.Store["key2"] = mockstorage.DBEntry{Value: []byte("keyset")}
+		p.Store.Store["key3"] = mockstorage.DBEntry{Value: []byte("keyset")}
+
+		putKeyMeta(t, p, "key_store_id", "key1", keyPendingDeletion(time.Now().Add(-time.Hour)))
+		putKeyMeta(t, p, "key_store_id", "key2", keyPendingDeletion(time.Now().Add(time.Hour)))
+		putKeyMeta(t, p, "key_store_id", "key3")
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p})
+		require.NoError(t, err)
+
+		purged, failed, err := cmd.PurgeExpiredKeys()
+		require.NoError(t, err)
+		require.Equal(t, 1, purged)
+		require.Equal(t, 0, failed)
+
+		require.NotContains(t, p.Store.Store, "key1")
+		require.NotContains(t, p.Store.Store, "key_store_id_key1")
+		require.Contains(t, p.Store.Store, "key2")
+		require.Contains(t, p.Store.Store, "key3")
+	})
+
+	t.Run("Fail to query key metadata", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.ErrQuery = errors.New("query error")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		_, _, err = cmd.PurgeExpiredKeys()
+		require.EqualError(t, err, "query key metadata: query: query error")
+	})
+
+	t.Run("Fail to destroy key", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.Store["key2"] = mockstorage.DBEntry{Value: []byte("keyset")}
+
+		putKeyMeta(t, p, "other_key_store_id", "key1", keyPendingDeletion(time.Now().Add(-time.Hour)))
+		putKeyMeta(t, p, "key_store_id", "key2", keyPendingDeletion(time.Now().Add(-time.Hour)))
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p})
+		require.NoError(t, err)
+
+		purged, failed, err := cmd.PurgeExpiredKeys()
+		require.NoError(t, err)
+		require.Equal(t, 1, purged)
+		require.Equal(t, 1, failed)
+
+		require.Contains(t, p.Store.Store, "other_key_store_id_key1")
+		require.NotContains(t, p.Store.Store, "key2")
+	})
+}
+
+func TestCommand_RotateDueKeys(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		putKeyMeta(t, p, "key_store_id", "key1", keyRotationDue(time.Now().Add(-time.Hour)))
+		putKeyMeta(t, p, "key_store_id", "key2", keyRotationDue(time.Now().Add(time.Hour)))
+		putAlias(t, p, "key_store_id", "encryption", "key1")
+
+		ctrl := gomock.NewController(t)
+
+		metrics := NewMockMetricsProvider(ctrl)
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).Times(1)
+		metrics.EXPECT().KeysRotated(1).Times(1)
+		metrics.EXPECT().KeyRotationFailures(0).Times(1)
+		metrics.EXPECT().LastKeyRotationTime(gomock.Any()).Times(1)
+		metrics.EXPECT().NextKeyRotationTime(gomock.Any()).Times(1)
+
+		creator := NewMockKeyStoreCreator(ctrl)
+		creator.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&mockkms.KeyManager{
+			RotateKeyID: "rotated_key1",
+		}, nil).Times(1)
+
+		cmd, err := New(&Config{
+			StorageProvider:    p,
+			KeyStorageProvider: p,
+			KeyStoreCreator:    creator,
+			MetricsProvider:    metrics,
+		})
+		require.NoError(t, err)
+
+		rotated, err := cmd.RotateDueKeys()
+		require.NoError(t, err)
+		require.Equal(t, 1, rotated)
+
+		require.NotContains(t, p.Store.Store, "key_store_id_key1")
+		require.Contains(t, p.Store.Store, "key_store_id_key2")
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id_rotated_key1"].Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"interval_days": float64(90)}, meta["rotation_policy"])
+		require.NotEmpty(t, meta["last_rotated_at"])
+
+		nextRotationAt, err := time.Parse(time.RFC3339, meta["next_rotation_at"].(string))
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().AddDate(0, 0, 90), nextRotationAt, time.Minute)
+
+		var alias map[string]interface{}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id_encryption"].Value, &alias)
+		require.NoError(t, err)
+		require.Equal(t, "rotated_key1", alias["key_id"])
+	})
+
+	t.Run("Fail with shamir secret lock", func(t *testing.T) {
+		cmd, err := New(&Config{
+			StorageProvider: mockstorage.NewMockStoreProvider(),
+			ShamirProvider:  NewMockShamirProvider(gomock.NewController(t)),
+		})
+		require.NoError(t, err)
+
+		_, err = cmd.RotateDueKeys()
+		require.EqualError(t, err, "bad request: scheduled rotation is not supported with shamir secret lock")
+	})
+
+	t.Run("Fail to query key metadata", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.ErrQuery = errors.New("query error")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		_, err = cmd.RotateDueKeys()
+		require.EqualError(t, err, "query key metadata: query: query error")
+	})
+
+	t.Run("Fail to rotate key", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		putKeyMeta(t, p, "key_store_id", "key_id", keyRotationDue(time.Now().Add(-time.Hour)))
+
+		ctrl := gomock.NewController(t)
+
+		metrics := NewMockMetricsProvider(ctrl)
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).Times(1)
+		metrics.EXPECT().KeysRotated(0).Times(1)
+		metrics.EXPECT().KeyRotationFailures(1).Times(1)
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p, MetricsProvider: metrics})
+		require.NoError(t, err)
+
+		rotated, err := cmd.RotateDueKeys()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "rotate key key_id in key store key_store_id: resolve key store")
+		require.Equal(t, 0, rotated)
+	})
+}
+
+func TestCommand_GetKeyStore(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{
+			"id": "key_store_id",
+			"controller": "did:example:test",
+			"edv": {
+				"vault_url": "https://edv.example.com/encrypted-data-vaults/vault_id",
+				"recipient_key_id": "recipient_key_id",
+				"mac_key_id": "mac_key_id",
+				"capability": "Y2FwYWJpbGl0eQ=="
+			},
+			"created_at": "2021-11-01T10:00:00Z"
+		}`)}
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.GetKeyStore(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+		require.NotContains(t, buf.String(), "capability")
+
+		var resp GetKeyStoreResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, "key_store_id", resp.ID)
+		require.Equal(t, "did:example:test", resp.Controller)
+		require.Equal(t, "active", resp.Status)
+		require.Equal(t, time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC), resp.CreatedAt)
+		require.Equal(t, &EDVInfo{
+			VaultURL:       "https://edv.example.com/encrypted-data-vaults/vault_id",
+			RecipientKeyID: "recipient_key_id",
+			MACKeyID:       "mac_key_id",
+		}, resp.EDV)
+	})
+
+	t.Run("Key store not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.GetKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key store not found")
+	})
+}
+
+func TestCommand_DisableKey(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p))
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DisableKey(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id_key_id"].Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, "disabled", meta["status"])
+
+		req, err := json.Marshal(SignRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err = json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Sign(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "conflict: key is disabled")
+	})
+
+	t.Run("Key is pending deletion", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		putKeyMeta(t, p, "key_store_id", "key_id", keyPendingDeletion(time.Now().Add(time.Hour)))
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DisableKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "conflict: key is pending deletion")
+	})
+
+	t.Run("Key not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DisableKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key not found")
+	})
+
+	t.Run("Fail to save key metadata", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		p.Store.ErrPut = errors.New("put error")
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DisableKey(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "save key metadata: put: put error")
+	})
+}
+
+func TestCommand_EnableKey(t *testing.T) {
+	p := mockstorage.NewMockStoreProvider()
+
+	putKeyMeta(t, p, "key_store_id", "key_id")
+
+	cmd, err := New(&Config{StorageProvider: p})
+	require.NoError(t, err)
+
+	wr, err := json.Marshal(WrappedRequest{
+		KeyStoreID: "key_store_id",
+		KeyID:      "key_id",
+	})
+	require.NoError(t, err)
+
+	err = cmd.DisableKey(nil, bytes.NewBuffer(wr))
+	require.NoError(t, err)
+
+	err = cmd.EnableKey(nil, bytes.NewReader(wr))
+	require.NoError(t, err)
+
+	var meta map[string]interface{}
+
+	err = json.Unmarshal(p.Store.Store["key_store_id_key_id"].Value, &meta)
+	require.NoError(t, err)
+	require.Equal(t, "enabled", meta["status"])
+}
+
+func TestCommand_UpdateKeyStore(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{
+			"id": "key_store_id",
+			"controller": "did:example:test",
+			"edv": {"vault_url": "https://edv.example.com/encrypted-data-vaults/vault_id"}
+		}`)}
+
+		zcap := NewMockZCAPService(ctrl)
+		revoke := zcap.EXPECT().Revoke("https://kms.example.com/v1/keystores/key_store_id").Return(nil).Times(1)
+		zcap.EXPECT().NewCapability(context.Background(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&zcapld.Capability{}, nil).
+			After(revoke).
+			Times(1)
+
+		cmd, err := New(&Config{
+			StorageProvider: p,
+			ZCAPService:     zcap,
+			EnableZCAPs:     true,
+			BaseKeyStoreURL: "https://kms.example.com/v1/keystores",
+		})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateKeyStoreRequest{
+			Controller: "did:example:new",
+			EDV:        &UpdateEDVOptions{VaultURL: "https://edv.example.com/encrypted-data-vaults/vault_id"},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.UpdateKeyStore(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp UpdateKeyStoreResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.Capability)
+
+		buf.Reset()
+
+		err = cmd.GetKeyStore(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var info GetKeyStoreResponse
+
+		err = json.Unmarshal(buf.Bytes(), &info)
+		require.NoError(t, err)
+		require.Equal(t, "did:example:new", info.Controller)
+		require.Equal(t, "https://edv.example.com/encrypted-data-vaults/vault_id", info.EDV.VaultURL)
+	})
+
+	t.Run("EDV vault URL can't be changed", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{
+			"id": "key_store_id",
+			"edv": {"vault_url": "https://edv.example.com/encrypted-data-vaults/vault_id"}
+		}`)}
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateKeyStoreRequest{
+			EDV: &UpdateEDVOptions{VaultURL: "https://edv.example.com/encrypted-data-vaults/new_vault_id"},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			"bad request: edv vault url can't be updated, migrate the key store to the new vault instead")
+	})
+
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    []byte("{}"),
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "validate request: validation failed: controller or edv must be set")
+	})
+
+	t.Run("Key store doesn't use EDV", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateKeyStoreRequest{
+			EDV: &UpdateEDVOptions{VaultURL: "https://edv.example.com/encrypted-data-vaults/vault_id"},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: key store doesn't use edv")
+	})
+
+	t.Run("Key store not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateKeyStoreRequest{
+			Controller: "did:example:new",
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key store not found")
+	})
+
+	t.Run("Fail to create zcap", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		zcap := NewMockZCAPService(ctrl)
+		zcap.EXPECT().Revoke(gomock.Any()).Return(nil).Times(1)
+		zcap.EXPECT().NewCapability(context.Background(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("zcap error")).
+			Times(1)
+
+		cmd, err := New(&Config{
+			StorageProvider: p,
+			ZCAPService:     zcap,
+			EnableZCAPs:     true,
+		})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateKeyStoreRequest{
+			Controller: "did:example:new",
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "new compressed zcap: create zcap: zcap error")
+	})
+
+	t.Run("Delegated capability can't change controller", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_store_id","controller":"did:example:test"}`),
+		}
+
+		cmd, err := New(&Config{
+			StorageProvider: p,
+			ZCAPService:     NewMockZCAPService(gomock.NewController(t)),
+			EnableZCAPs:     true,
+		})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateKeyStoreRequest{
+			Controller: "did:example:new",
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+			Capability: &InvokedCapability{Delegated: true},
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "forbidden: controller can be changed with the root capability only")
+		require.Contains(t, string(p.Store.Store["key_store_id"].Value), "did:example:test")
+	})
+
+	t.Run("Fail to revoke root capability", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		zcap := NewMockZCAPService(gomock.NewController(t))
+		zcap.EXPECT().Revoke(gomock.Any()).Return(errors.New("revoke error")).Times(1)
+
+		cmd, err := New(&Config{
+			StorageProvider: p,
+			ZCAPService:     zcap,
+			EnableZCAPs:     true,
+		})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateKeyStoreRequest{
+			Controller: "did:example:new",
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "revoke root capability: revoke error")
+	})
+}
+
+func TestCommand_ReissueRootCapability(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_store_id","controller":"did:example:test"}`),
+		}
+
+		zcap := NewMockZCAPService(gomock.NewController(t))
+		zcap.EXPECT().NewCapability(context.Background(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&zcapld.Capability{}, nil).
+			Times(1)
+
+		cmd, err := New(&Config{
+			StorageProvider: p,
+			ZCAPService:     zcap,
+			EnableZCAPs:     true,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Capability: &InvokedCapability{AllowedActions: []string{ActionCreateKey, ActionStoreCapability}},
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.ReissueRootCapability(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp ReissueRootCapabilityResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.Capability)
+	})
+
+	t.Run("Delegated capability", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		cmd, err := New(&Config{
+			StorageProvider: p,
+			ZCAPService:     NewMockZCAPService(gomock.NewController(t)),
+			EnableZCAPs:     true,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Capability: &InvokedCapability{Delegated: true, AllowedActions: []string{ActionStoreCapability}},
+		})
+		require.NoError(t, err)
+
+		err = cmd.ReissueRootCapability(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "forbidden: root capability can be reissued with the root capability only")
+	})
+
+	t.Run("ZCAPs are not enabled", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+		require.NoError(t, err)
+
+		err = cmd.ReissueRootCapability(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: zcaps are not enabled")
+	})
+
+	t.Run("Key store not found", func(t *testing.T) {
+		cmd, err := New(&Config{
+			StorageProvider: mockstorage.NewMockStoreProvider(),
+			ZCAPService:     NewMockZCAPService(gomock.NewController(t)),
+			EnableZCAPs:     true,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+		require.NoError(t, err)
+
+		err = cmd.ReissueRootCapability(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key store not found")
+	})
+}
+
+func TestCommand_UpdateEDVCapability(t *testing.T) {
+	const vaultURL = "https://edv.example.com/encrypted-data-vaults/vault_id"
+
+	keyStoreData := []byte(`{"id":"key_store_id","edv":{"vault_url":"` + vaultURL + `"}}`)
+
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: keyStoreData}
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		capability := []byte(`{"id":"urn:zcap:new","invocationTarget":{"ID":"vault_id","Type":"urn:edv:vault"}}`)
+
+		req, err := json.Marshal(UpdateEDVCapabilityRequest{Capability: capability})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateEDVCapability(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var meta struct {
+			EDV struct {
+				Capability []byte `json:"capability"`
+			} `json:"edv"`
+		}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id"].Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, capability, meta.EDV.Capability)
+	})
+
+	t.Run("Capability doesn't match vault", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: keyStoreData}
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateEDVCapabilityRequest{
+			Capability: []byte(`{"id":"urn:zcap:new","invocationTarget":{"ID":"other_vault_id"}}`),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateEDVCapability(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err,
+			`validation failed: capability invocation target "other_vault_id" doesn't match vault "vault_id"`)
+	})
+
+	t.Run("Fail to parse capability", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: keyStoreData}
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateEDVCapabilityRequest{Capability: []byte("invalid")})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateEDVCapability(nil, bytes.NewBuffer(wr))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "validation failed: parse capability")
+	})
+
+	t.Run("Key store doesn't use EDV", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(UpdateEDVCapabilityRequest{Capability: []byte("{}")})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateEDVCapability(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: key store doesn't use edv")
+	})
+
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    []byte("{}"),
+		})
+		require.NoError(t, err)
+
+		err = cmd.UpdateEDVCapability(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "validate request: validation failed: capability must be non-empty")
+	})
+}
+
+func TestCommand_BackupKeyStore(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		key, did := newBackupSigningKey(t)
+
+		cmd := createCmdWithLocalKMS(t, withKeyManager(backupKeyManager(t)), withBackupSigningKey(key))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		publicKeyID := importPublicKey(t, cmd, kms.ED25519Type, pub)
+
+		backup := backupKeyStore(t, cmd)
+
+		require.Equal(t, did, backup.Signer)
+		require.True(t, ed25519.Verify(key.Public().(ed25519.PublicKey), backup.Payload, backup.Signature)) //nolint:forcetypeassert,lll
+
+		var contents map[string]json.RawMessage
+
+		require.NoError(t, json.Unmarshal(backup.Payload, &contents))
+		require.Equal(t, "1", string(contents["version"]))
+
+		var keysets map[string][]byte
+
+		require.NoError(t, json.Unmarshal(contents["keysets"], &keysets))
+		require.Contains(t, keysets, keyID)
+		require.NotContains(t, keysets, publicKeyID) // imported public keys have no keyset
+	})
+
+	t.Run("Key store not found", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider(), BackupSigningKey: key})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+		require.NoError(t, err)
+
+		err = cmd.BackupKeyStore(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key store not found")
+	})
+
+	t.Run("Backup signing key is not configured", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withKeyManager(backupKeyManager(t)))
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+		require.NoError(t, err)
+
+		err = cmd.BackupKeyStore(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: backup signing key is not configured")
+	})
+}
+
+func TestCommand_RestoreKeyStore(t *testing.T) {
+	t.Run("Restore into another instance", func(t *testing.T) {
+		km := backupKeyManager(t)
+		key, did := newBackupSigningKey(t)
+
+		cmd := createCmdWithLocalKMS(t, withKeyManager(km), withMemStorage(t), withBackupSigningKey(key))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type, Label: "signing key"})
+
+		req, err := json.Marshal(SetAliasRequest{Name: "signer", KeyID: keyID})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", Request: req})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.SetAlias(&bytes.Buffer{}, bytes.NewBuffer(wr)))
+
+		backup := backupKeyStore(t, cmd)
+
+		// other instance trusts backups of the first one
+		otherKey, _ := newBackupSigningKey(t)
+
+		other := createCmdWithLocalKMS(t, withKeyManager(km), withBackupSigningKey(otherKey, did), func(c *Config) {
+			p := mem.NewProvider()
+
+			c.StorageProvider = p
+			c.KeyStorageProvider = p
+		})
+
+		resp, err := execute[RestoreKeyStoreResponse](other.RestoreKeyStore, "", &RestoreKeyStoreRequest{
+			Backup: backup,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "/key_store_id", resp.KeyStoreURL)
+
+		// restored key is available by the alias and signs messages verifiable with the original key
+		msg := []byte("test message")
+
+		req, err = json.Marshal(SignRequest{Message: msg})
+		require.NoError(t, err)
+
+		wr, err = json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: "alias:signer", Request: req})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		require.NoError(t, other.Sign(&buf, bytes.NewBuffer(wr)))
+
+		var signResp SignResponse
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &signResp))
+
+		req, err = json.Marshal(VerifyRequest{Signature: signResp.Signature, Message: msg})
+		require.NoError(t, err)
+
+		wr, err = json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.Verify(nil, bytes.NewBuffer(wr)))
+
+		wr, err = json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", Request: []byte("{}")})
+		require.NoError(t, err)
+
+		buf.Reset()
+
+		require.NoError(t, other.ListKeys(&buf, bytes.NewBuffer(wr)))
+
+		var listResp ListKeysResponse
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &listResp))
+		require.Len(t, listResp.Keys, 1)
+		require.Equal(t, "signing key", listResp.Keys[0].Label)
+	})
+
+	t.Run("Restore deleted key store with root zcap", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		rootZCAP := &zcapld.Capability{ID: "https://kms.example.com/v1/keystores/key_store_id"}
+
+		zcap := NewMockZCAPService(ctrl)
+		zcap.EXPECT().Resolve(rootZCAP.ID).Return(rootZCAP, nil).Times(1)
+		zcap.EXPECT().Revoke(rootZCAP.ID).Return(nil).Times(1)
+		zcap.EXPECT().Save(rootZCAP).Return(nil).Times(1)
+
+		key, _ := newBackupSigningKey(t)
+
+		cmd := createCmdWithLocalKMS(t, withKeyManager(backupKeyManager(t)), withMemStorage(t), withBackupSigningKey(key),
+			func(c *Config) {
+				c.ZCAPService = zcap
+				c.EnableZCAPs = true
+				c.BaseKeyStoreURL = "https://kms.example.com/v1/keystores"
+			})
+
+		createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		backup := backupKeyStore(t, cmd)
+
+		deleteKeyStore(t, cmd)
+
+		resp, err := execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{Backup: backup})
+		require.NoError(t, err)
+		require.Equal(t, rootZCAP.ID, resp.KeyStoreURL)
+		require.NotEmpty(t, resp.Capability)
+	})
+
+	t.Run("Key store already exists", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		cmd := createCmdWithLocalKMS(t, withKeyManager(backupKeyManager(t)), withBackupSigningKey(key))
+
+		_, err := execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{
+			Backup: backupKeyStore(t, cmd),
+		})
+		require.EqualError(t, err, "conflict: key store already exists")
+	})
+
+	t.Run("Key already exists", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		p := mem.NewProvider()
+
+		cmd := createCmdWithLocalKMS(t, withKeyManager(backupKeyManager(t)), withBackupSigningKey(key), func(c *Config) {
+			c.StorageProvider = p
+			c.KeyStorageProvider = p
+		})
+
+		store, err := p.OpenStore("keys")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("other_key_store_key_id", []byte(`{"id":"key_id"}`)))
+
+		_, err = execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{
+			Backup: signBackup(t, key, `{"version":1,"key_store":{"id":"other_key_store"},"keys":[{"id":"key_id"}]}`),
+		})
+		require.EqualError(t, err, "conflict: key key_id already exists")
+	})
+
+	t.Run("Server key already exists", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		p := mem.NewProvider()
+
+		cmd := createCmdWithLocalKMS(t, withKeyManager(backupKeyManager(t)), withBackupSigningKey(key), func(c *Config) {
+			c.StorageProvider = p
+			c.KeyStorageProvider = p
+		})
+
+		serverStore, err := kms.NewAriesProviderWrapper(p)
+		require.NoError(t, err)
+
+		require.NoError(t, serverStore.Put("main_key_id", []byte("main key of another key store")))
+
+		_, err = execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{
+			Backup: signBackup(t, key, `{"version":1,"key_store":{"id":"other_key_store","main_key_id":"main_key_id"},`+
+				`"server_keysets":{"main_key_id":"AA=="}}`),
+		})
+		require.EqualError(t, err, "conflict: server key main_key_id already exists")
+
+		b, err := serverStore.Get("main_key_id")
+		require.NoError(t, err)
+		require.Equal(t, "main key of another key store", string(b))
+	})
+
+	t.Run("Server key is not referenced by key store", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		cmd := createCmdWithLocalKMS(t, withBackupSigningKey(key))
+
+		_, err := execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{
+			Backup: signBackup(t, key, `{"version":1,`+
+				`"key_store":{"id":"other_key_store"},"server_keysets":{"main_key_id":"AA=="}}`),
+		})
+		require.EqualError(t, err, "bad request: server key main_key_id is not referenced by the key store")
+	})
+
+	t.Run("Backup has no server key of key store", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		cmd := createCmdWithLocalKMS(t, withBackupSigningKey(key))
+
+		_, err := execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{
+			Backup: signBackup(t, key,
+				`{"version":1,"key_store":{"id":"other_key_store","main_key_id":"main_key_id"}}`),
+		})
+		require.EqualError(t, err, "bad request: backup has no server key main_key_id")
+	})
+
+	t.Run("Failed restore is rolled back", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		zcap := NewMockZCAPService(gomock.NewController(t))
+		zcap.EXPECT().Save(gomock.Any()).Return(errors.New("save error")).Times(1)
+
+		p := mem.NewProvider()
+
+		cmd := createCmdWithLocalKMS(t, withKeyManager(backupKeyManager(t)), withBackupSigningKey(key), func(c *Config) {
+			c.StorageProvider = p
+			c.KeyStorageProvider = p
+			c.EnableZCAPs = true
+			c.ZCAPService = zcap
+		})
+
+		backup := signBackup(t, key, `{"version":1,"key_store":{"id":"other_key_store","main_key_id":"main_key_id"},`+
+			`"server_keysets":{"main_key_id":"AA=="},"keys":[{"id":"key_id"}],"keysets":{"key_id":"AA=="},`+
+			`"zcaps":[{"id":"/other_key_store"}]}`)
+
+		_, err := execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{Backup: backup})
+		require.EqualError(t, err, "save root zcap: save error")
+
+		serverStore, err := kms.NewAriesProviderWrapper(p)
+		require.NoError(t, err)
+
+		_, err = serverStore.Get("main_key_id")
+		require.ErrorIs(t, err, kms.ErrKeyNotFound)
+
+		store, err := p.OpenStore("keys")
+		require.NoError(t, err)
+
+		_, err = store.Get("other_key_store_key_id")
+		require.ErrorIs(t, err, storage.ErrDataNotFound)
+	})
+
+	t.Run("Invalid backup signature", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		cmd := createCmdWithLocalKMS(t, withKeyManager(backupKeyManager(t)), withBackupSigningKey(key))
+
+		backup := backupKeyStore(t, cmd)
+		backup.Payload = bytes.Replace(backup.Payload, []byte("key_store_id"), []byte("key_store_xx"), 1)
+
+		_, err := execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{Backup: backup})
+		require.EqualError(t, err, "bad request: invalid backup signature")
+	})
+
+	t.Run("Backup signer is not trusted", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+		otherKey, _ := newBackupSigningKey(t)
+
+		cmd := createCmdWithLocalKMS(t, withBackupSigningKey(key))
+
+		// archive embeds its own signer
+		_, err := execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{
+			Backup: signBackup(t, otherKey, `{"version":1,"key_store":{"id":"other_key_store"}}`),
+		})
+		require.EqualError(t, err, "forbidden: backup signer is not trusted")
+	})
+
+	t.Run("Unsupported backup version", func(t *testing.T) {
+		key, _ := newBackupSigningKey(t)
+
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider(), BackupSigningKey: key})
+		require.NoError(t, err)
+
+		_, err = execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{
+			Backup: signBackup(t, key, `{"version":2,"key_store":{"id":"key_store_id"}}`),
+		})
+		require.EqualError(t, err, "bad request: unsupported backup version 2")
+	})
+
+	t.Run("Invalid trusted backup signer", func(t *testing.T) {
+		_, err := New(&Config{
+			StorageProvider:      mockstorage.NewMockStoreProvider(),
+			BackupTrustedSigners: []string{"did:example:signer"},
+		})
+		require.EqualError(t, err,
+			"parse backup signers: invalid backup signer did:example:signer: not an Ed25519 did:key")
+	})
+
+	t.Run("Backup is not set", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		_, err = execute[RestoreKeyStoreResponse](cmd.RestoreKeyStore, "", &RestoreKeyStoreRequest{})
+		require.EqualError(t, err, "validate request: validation failed: backup must be set")
+	})
+}
+
+func TestCommand_MigrateKeyStore(t *testing.T) {
+	t.Run("Migrate to EDV and back", func(t *testing.T) {
+		edvServer := newMockEDVServer(t)
+		defer edvServer.Close()
+
+		serverStorage := mem.NewProvider()
+		keyStorage := mem.NewProvider()
+
+		cmd := createCmdWithLocalKMS(t, withEDVKeyManager(t, serverStorage, keyStorage))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		importPublicKey(t, cmd, kms.ED25519Type, pub)
+
+		resp, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV: &EDVOptions{VaultURL: edvServer.URL + "/encrypted-data-vaults/vault_id"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, resp.MigratedKeys) // imported public keys have no keyset
+		require.Len(t, edvServer.documents, 1)
+
+		localStore, err := kms.NewAriesProviderWrapper(keyStorage)
+		require.NoError(t, err)
+
+		_, err = localStore.Get(keyID)
+		require.ErrorIs(t, err, kms.ErrKeyNotFound)
+
+		signMessage(t, cmd, keyID)
+
+		meta := getKeyStore(t, cmd)
+		require.NotNil(t, meta.EDV)
+
+		resp, err = execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{})
+		require.NoError(t, err)
+		require.Equal(t, 1, resp.MigratedKeys)
+		require.Empty(t, edvServer.documents)
+
+		_, err = localStore.Get(keyID)
+		require.NoError(t, err)
+
+		signMessage(t, cmd, keyID)
+
+		require.Nil(t, getKeyStore(t, cmd).EDV)
+
+		// EDV keys of the old vault are deleted
+		serverStore, err := kms.NewAriesProviderWrapper(serverStorage)
+		require.NoError(t, err)
+
+		_, err = serverStore.Get(meta.EDV.RecipientKeyID)
+		require.ErrorIs(t, err, kms.ErrKeyNotFound)
+
+		_, err = serverStore.Get(meta.EDV.MACKeyID)
+		require.ErrorIs(t, err, kms.ErrKeyNotFound)
+	})
+
+	t.Run("Fail to copy keysets to EDV", func(t *testing.T) {
+		edvServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer edvServer.Close()
+
+		keyStorage := mem.NewProvider()
+
+		cmd := createCmdWithLocalKMS(t, withEDVKeyManager(t, mem.NewProvider(), keyStorage))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		_, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV: &EDVOptions{VaultURL: edvServer.URL + "/encrypted-data-vaults/vault_id"},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "put keyset "+keyID)
+
+		// key store keeps using local storage
+		require.Nil(t, getKeyStore(t, cmd).EDV)
+
+		signMessage(t, cmd, keyID)
+	})
+
+	t.Run("Roll back key store metadata if fail to copy keysets created during migration", func(t *testing.T) {
+		edvServer := newMockEDVServer(t)
+		defer edvServer.Close()
+
+		serverStorage := &failingQueryProvider{Provider: mem.NewProvider()}
+		keyStorage := mem.NewProvider()
+
+		cmd := createCmdWithLocalKMS(t, withEDVKeyManager(t, serverStorage, keyStorage))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		// second listing of keys to catch up keysets created during migration fails
+		serverStorage.failQueryAfter = 1
+
+		_, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV: &EDVOptions{VaultURL: edvServer.URL + "/encrypted-data-vaults/vault_id"},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "copy keysets created during migration")
+
+		serverStorage.failQueryAfter = 0
+
+		// key store is switched back to local storage and copies in EDV are removed
+		require.Nil(t, getKeyStore(t, cmd).EDV)
+		require.Empty(t, edvServer.documents)
+
+		signMessage(t, cmd, keyID)
+	})
+
+	t.Run("Key manager is removed from the cache after migration", func(t *testing.T) {
+		edvServer := newMockEDVServer(t)
+		defer edvServer.Close()
+
+		ctrl := gomock.NewController(t)
+
+		metrics := NewMockMetricsProvider(ctrl)
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyStoreGetKeyTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().CryptoSignTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyManagerCacheMiss().AnyTimes()
+		metrics.EXPECT().KeyManagerCacheHit().AnyTimes()
+
+		cache := newMemKeyManagerCache()
+
+		cmd := createCmdWithLocalKMS(t, withEDVKeyManager(t, mem.NewProvider(), mem.NewProvider()),
+			withKeyManagerCache(cache, time.Minute), withMetrics(metrics))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+		require.Equal(t, 1, cache.len())
+
+		_, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV: &EDVOptions{VaultURL: edvServer.URL + "/encrypted-data-vaults/vault_id"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 0, cache.len())
+
+		signMessage(t, cmd, keyID)
+	})
+
+	t.Run("Migrate key without metadata", func(t *testing.T) {
+		edvServer := newMockEDVServer(t)
+		defer edvServer.Close()
+
+		serverStorage := mem.NewProvider()
+
+		cmd := createCmdWithLocalKMS(t, withEDVKeyManager(t, serverStorage, mem.NewProvider()))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		// key created before key metadata was introduced
+		keys, err := serverStorage.OpenStore("keys")
+		require.NoError(t, err)
+
+		require.NoError(t, keys.Delete("key_store_id_"+keyID))
+
+		resp, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV:    &EDVOptions{VaultURL: edvServer.URL + "/encrypted-data-vaults/vault_id"},
+			KeyIDs: []string{keyID},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, resp.MigratedKeys)
+		require.Len(t, edvServer.documents, 1)
+
+		signMessage(t, cmd, keyID)
+	})
+
+	t.Run("Keep EDV keys of the old vault if key without metadata is not migrated", func(t *testing.T) {
+		edvServer := newMockEDVServer(t)
+		defer edvServer.Close()
+
+		serverStorage := mem.NewProvider()
+
+		cmd := createCmdWithLocalKMS(t, withEDVKeyManager(t, serverStorage, mem.NewProvider()))
+
+		// key store created before key metadata was introduced
+		keyStores, err := serverStorage.OpenStore("keystores")
+		require.NoError(t, err)
+
+		b, err := keyStores.Get("key_store_id")
+		require.NoError(t, err)
+
+		var ks map[string]interface{}
+
+		require.NoError(t, json.Unmarshal(b, &ks))
+
+		delete(ks, "created_at")
+
+		b, err = json.Marshal(ks)
+		require.NoError(t, err)
+
+		require.NoError(t, keyStores.Put("key_store_id", b))
+
+		legacyKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		_, err = execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV: &EDVOptions{VaultURL: edvServer.URL + "/encrypted-data-vaults/vault_id"},
+		})
+		require.NoError(t, err)
+
+		keys, err := serverStorage.OpenStore("keys")
+		require.NoError(t, err)
+
+		require.NoError(t, keys.Delete("key_store_id_"+legacyKeyID))
+
+		meta := getKeyStore(t, cmd)
+
+		resp, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{})
+		require.NoError(t, err)
+		require.Equal(t, 1, resp.MigratedKeys)
+		require.Len(t, edvServer.documents, 1) // keyset of the key without metadata
+
+		signMessage(t, cmd, keyID)
+
+		serverStore, err := kms.NewAriesProviderWrapper(serverStorage)
+		require.NoError(t, err)
+
+		_, err = serverStore.Get(meta.EDV.RecipientKeyID)
+		require.NoError(t, err)
+
+		_, err = serverStore.Get(meta.EDV.MACKeyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Key without metadata is not found", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withEDVKeyManager(t, mem.NewProvider(), mem.NewProvider()))
+
+		_, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV:    &EDVOptions{VaultURL: "https://edv.example.com/encrypted-data-vaults/vault_id"},
+			KeyIDs: []string{"unknown"},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request: get key unknown")
+	})
+
+	t.Run("Key store already uses local storage", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{})
+		require.EqualError(t, err, "conflict: key store already uses local storage")
+	})
+
+	t.Run("Key store already uses EDV vault", func(t *testing.T) {
+		const vaultURL = "https://edv.example.com/encrypted-data-vaults/vault_id"
+
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_store_id","edv":{"vault_url":"` + vaultURL + `"}}`),
+		}
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		_, err = execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV: &EDVOptions{VaultURL: vaultURL},
+		})
+		require.EqualError(t, err, "conflict: key store already uses edv vault "+vaultURL)
+	})
+
+	t.Run("Key store is deactivated", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_store_id","status":"deactivated"}`),
+		}
+
+		cmd := createCmdWithLocalKMS(t, withStorageProvider(p))
+
+		_, err := execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{
+			EDV: &EDVOptions{VaultURL: "https://edv.example.com/encrypted-data-vaults/vault_id"},
+		})
+		require.EqualError(t, err, "conflict: key store is deactivated")
+	})
+
+	t.Run("Key store not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		_, err = execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{})
+		require.EqualError(t, err, "not found: key store not found")
+	})
+
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		_, err = execute[MigrateKeyStoreResponse](cmd.MigrateKeyStore, "", &MigrateKeyStoreRequest{EDV: &EDVOptions{}})
+		require.EqualError(t, err, "validate request: validation failed: edv vault url must be non-empty")
+	})
+}
+
+func TestCommand_DeleteKeyStore(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_store_id","main_key_id":"main_key_id"}`),
+		}
+		p.Store.Store["main_key_id"] = mockstorage.DBEntry{Value: []byte("keyset")}
+		p.Store.Store["key1"] = mockstorage.DBEntry{Value: []byte("keyset")}
+		p.Store.Store["key2"] = mockstorage.DBEntry{Value: []byte("keyset")}
+
+		putKeyMeta(t, p, "key_store_id", "key1")
+		putKeyMeta(t, p, "key_store_id", "key2")
+
+		zcap := NewMockZCAPService(ctrl)
+		zcap.EXPECT().Revoke("https://kms.example.com/v1/keystores/key_store_id").Return(nil).Times(1)
+
+		cmd, err := New(&Config{
+			StorageProvider:    p,
+			KeyStorageProvider: p,
+			ZCAPService:        zcap,
+			EnableZCAPs:        true,
+			BaseKeyStoreURL:    "https://kms.example.com/v1/keystores",
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteKeyStore(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		require.Empty(t, p.Store.Store)
+	})
+
+	t.Run("Server keys are removed from key manager cache", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_store_id","main_key_id":"main_key_id","edv":{"recipient_key_id":"rec_key_id"}}`),
+		}
+		p.Store.Store["main_key_id"] = mockstorage.DBEntry{Value: []byte("keyset")}
+
+		km := &invalidatingKMS{}
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p, KMS: km})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteKeyStore(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"main_key_id", "rec_key_id"}, km.invalidated)
+	})
+
+	t.Run("Key store not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key store not found")
+	})
+
+	t.Run("Fail to delete keys", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.ErrDelete = errors.New("delete error")
+
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "delete keys: remove key key_id: delete keyset: delete error")
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id"].Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, "deactivated", meta["status"])
+	})
+
+	t.Run("Fail to revoke root zcap", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		zcap := NewMockZCAPService(ctrl)
+		zcap.EXPECT().Revoke(gomock.Any()).Return(errors.New("revoke error")).Times(1)
+
+		cmd, err := New(&Config{
+			StorageProvider:    p,
+			KeyStorageProvider: p,
+			ZCAPService:        zcap,
+			EnableZCAPs:        true,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeleteKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "revoke root zcap: revoke error")
+		require.Contains(t, p.Store.Store, "key_store_id")
+	})
+}
+
+func TestCommand_DeactivateKeyStore(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		metrics := NewMockMetricsProvider(gomock.NewController(t))
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+
+		cmd, err := New(&Config{StorageProvider: p, KeyStorageProvider: p, MetricsProvider: metrics})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    []byte(`{"message":"dGVzdCBtZXNzYWdl"}`),
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeactivateKeyStore(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		err = cmd.Sign(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "resolve key store: conflict: key store is deactivated")
+
+		err = cmd.ActivateKeyStore(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var meta map[string]interface{}
+
+		err = json.Unmarshal(p.Store.Store["key_store_id"].Value, &meta)
+		require.NoError(t, err)
+		require.Equal(t, "active", meta["status"])
+	})
+
+	t.Run("Key store not found", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeactivateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key store not found")
+
+		err = cmd.ActivateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key store not found")
+	})
+
+	t.Run("Fail to save key store metadata", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.ErrPut = errors.New("put error")
+
+		cmd, err := New(&Config{StorageProvider: p})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+		})
+		require.NoError(t, err)
+
+		err = cmd.DeactivateKeyStore(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "save key store metadata: put: put error")
+	})
+}
+
+func TestCommand_JWKS(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		edKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type, Publish: true})
+		ecKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ECDSAP256TypeDER, Publish: true})
+		createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		expired := time.Now().Add(-time.Hour)
+		createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type, NotAfter: &expired, Publish: true})
+
+		req, err := json.Marshal(ImportKeyRequest{
+			KeyType: kms.X25519ECDHKWType,
+			Key:     random.GetRandomBytes(32),
+			Public:  true,
+			Publish: true,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", Request: req})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.ImportKey(&bytes.Buffer{}, bytes.NewBuffer(wr)))
+
+		keys := jwks(t, cmd)
+		require.Len(t, keys, 3)
+
+		require.Equal(t, edKeyID, keys[0].KeyID)
+		require.Equal(t, "EdDSA", keys[0].Algorithm)
+		require.Equal(t, "sig", keys[0].Use)
+		require.Equal(t, ecKeyID, keys[1].KeyID)
+		require.Equal(t, "ES256", keys[1].Algorithm)
+		require.Equal(t, "P-256", keys[1].Crv)
+		require.Equal(t, "X25519", keys[2].Crv)
+		require.Equal(t, "enc", keys[2].Use)
+
+		// unpublished and disabled keys are removed from the set
+		publish := false
+
+		req, err = json.Marshal(UpdateKeyRequest{Publish: &publish})
+		require.NoError(t, err)
+
+		wr, err = json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: ecKeyID, Request: req})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.UpdateKey(nil, bytes.NewBuffer(wr)))
+
+		wr, err = json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: edKeyID})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.DisableKey(nil, bytes.NewBuffer(wr)))
+
+		keys = jwks(t, cmd)
+		require.Len(t, keys, 1)
+		require.Equal(t, "X25519", keys[0].Crv)
+	})
+
+	t.Run("Publish existing key", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ECDSAP384TypeIEEEP1363})
+		require.Empty(t, jwks(t, cmd))
+
+		publish := true
+
+		req, err := json.Marshal(UpdateKeyRequest{Publish: &publish})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.UpdateKey(nil, bytes.NewBuffer(wr)))
+
+		keys := jwks(t, cmd)
+		require.Len(t, keys, 1)
+		require.Equal(t, keyID, keys[0].KeyID)
+		require.Equal(t, "ES384", keys[0].Algorithm)
+	})
+
+	t.Run("Rotated key is published", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		cmd := createCmdWithLocalKMS(t, withStorageProvider(p))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type, Publish: true})
+
+		req, err := json.Marshal(RotateKeyRequest{KeyType: kms.ED25519Type})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		require.NoError(t, cmd.RotateKey(&buf, bytes.NewBuffer(wr)))
+
+		var resp RotateKeyResponse
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+
+		// previous version stays in the set for clients that cached the set before rotation
+		keys := jwks(t, cmd)
+		require.Len(t, keys, 2)
+		require.Equal(t, keyID, keys[0].KeyID)
+		require.NotEqual(t, keyID, keys[1].KeyID)
+		require.True(t, strings.HasSuffix(resp.KeyURL, keys[1].KeyID))
+
+		// previous version is removed from the set after the retention period
+		entry := p.Store.Store["key_store_id_"+keys[1].KeyID]
+
+		var meta map[string]interface{}
+
+		require.NoError(t, json.Unmarshal(entry.Value, &meta))
+
+		retired, ok := meta["retired_jwks"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, retired, 1)
+
+		retired[0].(map[string]interface{})["retired_at"] = time.Now().Add(-2 * time.Hour) //nolint:forcetypeassert
+
+		entry.Value, err = json.Marshal(meta)
+		require.NoError(t, err)
+
+		p.Store.Store["key_store_id_"+keys[1].KeyID] = entry
+
+		keys = jwks(t, cmd)
+		require.Len(t, keys, 1)
+		require.True(t, strings.HasSuffix(resp.KeyURL, keys[0].KeyID))
+
+		// unpublished key is removed from the set with its previous versions
+		publish := false
+
+		req, err = json.Marshal(UpdateKeyRequest{Publish: &publish})
+		require.NoError(t, err)
+
+		wr, err = json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keys[0].KeyID, Request: req})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.UpdateKey(nil, bytes.NewBuffer(wr)))
+		require.Empty(t, jwks(t, cmd))
+	})
+
+	t.Run("Key of type can't be published", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		req, err := json.Marshal(CreateKeyRequest{KeyType: kms.AES256GCMType, Publish: true})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", Request: req})
+		require.NoError(t, err)
+
+		err = cmd.CreateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "validate request: validation failed: key of type AES256GCM can't be published")
+	})
+
+	t.Run("Key store not found", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "unknown"})
+		require.NoError(t, err)
+
+		err = cmd.JWKS(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "not found: key store not found")
+	})
+}
+
+func TestCommand_ExportPrivateKey(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type, Exportable: true})
+
+		cr, err := tinkcrypto.New()
+		require.NoError(t, err)
+
+		transportKMS, transportKey := createTransportKey(t, kms.NISTP256ECDHKWType)
+
+		req, err := json.Marshal(ExportPrivateKeyRequest{TransportKey: transportKey})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		require.NoError(t, cmd.ExportPrivateKey(&buf, bytes.NewBuffer(wr)))
+
+		var resp ExportPrivateKeyResponse
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+		require.Len(t, strings.Split(resp.JWE, "."), 5)
+
+		jwe, err := jose.Deserialize(resp.JWE)
+		require.NoError(t, err)
+
+		keysetJSON, err := jose.NewJWEDecrypt(nil, cr, transportKMS).Decrypt(jwe)
+		require.NoError(t, err)
+
+		kh, err := insecurecleartextkeyset.Read(keyset.NewJSONReader(bytes.NewReader(keysetJSON)))
+		require.NoError(t, err)
+
+		// exported private key signs messages verifiable with the key in the key store
+		msg := []byte("test message")
+
+		sig, err := cr.Sign(msg, kh)
+		require.NoError(t, err)
+
+		req, err = json.Marshal(VerifyRequest{Signature: sig, Message: msg})
+		require.NoError(t, err)
+
+		wr, err = json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.Verify(nil, bytes.NewBuffer(wr)))
+	})
+
+	t.Run("Key is not exportable", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		_, transportKey := createTransportKey(t, kms.X25519ECDHKWType)
+
+		req, err := json.Marshal(ExportPrivateKeyRequest{TransportKey: transportKey})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+		require.NoError(t, err)
+
+		err = cmd.ExportPrivateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: key is not exportable")
+	})
+
+	t.Run("Key is outside of validity window", func(t *testing.T) {
+		expired := time.Now().Add(-time.Hour)
+		notYetValid := time.Now().Add(time.Hour)
+
+		tests := []struct {
+			name string
+			req  *CreateKeyRequest
+			err  string
+		}{
+			{
+				name: "expired",
+				req:  &CreateKeyRequest{KeyType: kms.ED25519Type, Exportable: true, NotAfter: &expired},
+				err:  "conflict: key has expired",
+			},
+			{
+				name: "not yet valid",
+				req:  &CreateKeyRequest{KeyType: kms.ED25519Type, Exportable: true, NotBefore: &notYetValid},
+				err:  "conflict: key is not yet valid",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cmd := createCmdWithLocalKMS(t)
+
+				keyID := createKey(t, cmd, tt.req)
+
+				_, transportKey := createTransportKey(t, kms.NISTP256ECDHKWType)
+
+				req, err := json.Marshal(ExportPrivateKeyRequest{TransportKey: transportKey})
+				require.NoError(t, err)
+
+				wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+				require.NoError(t, err)
+
+				err = cmd.ExportPrivateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+				require.EqualError(t, err, tt.err)
+			})
+		}
+	})
+
+	t.Run("Invalid transport key", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type, Exportable: true})
+
+		req, err := json.Marshal(ExportPrivateKeyRequest{TransportKey: &crypto.PublicKey{
+			KID:   "kid",
+			X:     []byte("x"),
+			Curve: "NIST_P256",
+			Type:  "EC",
+		}})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+		require.NoError(t, err)
+
+		err = cmd.ExportPrivateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: transport key: point is not on curve NIST_P256")
+	})
+
+	t.Run("Transport key is not set", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		req, err := json.Marshal(ExportPrivateKeyRequest{})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: "key_id", Request: req})
+		require.NoError(t, err)
+
+		err = cmd.ExportPrivateKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "validate request: validation failed: transport key must be set")
+	})
+}
+
+func TestCommand_Sign(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			SignValue: []byte("signature"),
+		}))
+
+		req, err := json.Marshal(SignRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.Sign(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp SignResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("signature"), resp.Signature)
+	})
+
+	t.Run("Fail to sign", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			SignErr: errors.New("sign error"),
+		}))
+
+		req, err := json.Marshal(SignRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.Sign(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "sign: sign error")
+	})
+
+	t.Run("Fail if key is pending deletion", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		putKeyMeta(t, p, "key_store_id", "key_id", keyPendingDeletion(time.Now().Add(time.Hour)))
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p))
+
+		req, err := json.Marshal(SignRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Sign(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "conflict: key is pending deletion")
+	})
+
+	t.Run("Fail if operation is not allowed for the key", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+		p.Store.Store["key_store_id_key_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_id","key_store_id":"key_store_id","key_type":"ED25519",` +
+				`"allowed_operations":["verify"]}`),
+		}
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p))
+
+		req, err := json.Marshal(SignRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Sign(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: operation sign is not allowed for the key")
+	})
+
+	t.Run("Fail if key is not yet valid", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		putKeyMeta(t, p, "key_store_id", "key_id", keyValidity(time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)))
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p))
+
+		req, err := json.Marshal(SignRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Sign(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "conflict: key is not yet valid")
+	})
+
+	t.Run("Fail if key has expired", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		putKeyMeta(t, p, "key_store_id", "key_id", keyValidity(time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)))
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyExpiryGracePeriod(24*time.Hour))
+
+		req, err := json.Marshal(SignRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Sign(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "conflict: key has expired")
+	})
+}
+
+func TestCommand_Verify(t *testing.T) {
+	t.Run("Success with imported public key", func(t *testing.T) {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := importPublicKey(t, cmd, kms.ED25519Type, pub)
+
+		msg := []byte("test message")
+
+		req, err := json.Marshal(VerifyRequest{
+			Signature: ed25519.Sign(priv, msg),
+			Message:   msg,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      keyID,
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.Verify(nil, bytes.NewBuffer(wr)))
+
+		// imported public key can't be used for signing
+		req, err = json.Marshal(SignRequest{Message: msg})
+		require.NoError(t, err)
+
+		wr, err = json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      keyID,
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Sign(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: operation sign is not allowed for the key")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		cmd := createCmd(t, gomock.NewController(t),
+			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
+			withCrypto(&mockcrypto.Crypto{}),
+		)
+
+		req, err := json.Marshal(VerifyRequest{
+			Signature: []byte("signature"),
+			Message:   []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Verify(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+	})
+
+	t.Run("Success within expiry grace period", func(t *testing.T) {
+		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		putKeyMeta(t, p, "key_store_id", "key_id", keyValidity(time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)))
+
+		cmd := createCmd(t, gomock.NewController(t),
+			withStorageProvider(p),
+			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
+			withCrypto(&mockcrypto.Crypto{}),
+			withKeyExpiryGracePeriod(24*time.Hour),
+		)
+
+		req, err := json.Marshal(VerifyRequest{
+			Signature: []byte("signature"),
+			Message:   []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Verify(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail after expiry grace period", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		putKeyMeta(t, p, "key_store_id", "key_id", keyValidity(time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)))
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p), withKeyExpiryGracePeriod(time.Minute))
+
+		req, err := json.Marshal(VerifyRequest{
+			Signature: []byte("signature"),
+			Message:   []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Verify(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "conflict: key has expired")
+	})
+
+	t.Run("Fail to get public key from handle", func(t *testing.T) {
+		badKH, err := keyset.NewHandle(aead.KMSEnvelopeAEADKeyTemplate("badUrl", nil))
+		require.NoError(t, err)
+
+		cmd := createCmd(t, gomock.NewController(t),
+			withKeyManager(&mockkms.KeyManager{GetKeyValue: badKH}),
+			withCrypto(&mockcrypto.Crypto{}),
+		)
+
+		req, err := json.Marshal(VerifyRequest{
+			Signature: []byte("signature"),
+			Message:   []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Verify(nil, bytes.NewBuffer(wr))
+		require.Error(t, err)
+	})
+
+	t.Run("Fail to verify", func(t *testing.T) {
+		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		cmd := createCmd(t, gomock.NewController(t),
+			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
+			withCrypto(&mockcrypto.Crypto{VerifyErr: errors.New("verify error")}),
+		)
+
+		req, err := json.Marshal(VerifyRequest{
+			Signature: []byte("signature"),
+			Message:   []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Verify(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "verify: verify error")
+	})
+}
+
+func TestCommand_SignJWS(t *testing.T) {
+	t.Run("Success with Ed25519 key in compact serialization", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signResp, err := execute[SignJWSResponse](cmd.SignJWS, keyID, &SignJWSRequest{
+			Payload: []byte("test payload"),
+			Headers: map[string]interface{}{"typ": "JWT"},
+		})
+		require.NoError(t, err)
+
+		var compact string
+
+		require.NoError(t, json.Unmarshal(signResp.JWS, &compact))
+
+		parts := strings.Split(compact, ".")
+		require.Len(t, parts, 3)
+
+		protected, err := base64.RawURLEncoding.DecodeString(parts[0])
+		require.NoError(t, err)
+		require.JSONEq(t, fmt.Sprintf(`{"alg":"EdDSA","kid":%q,"typ":"JWT"}`, keyID), string(protected))
+
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		require.Equal(t, []byte("test payload"), payload)
+
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+
+		pub := exportKey(t, cmd, keyID, "")
+		require.True(t, ed25519.Verify(pub.PublicKey, []byte(parts[0]+"."+parts[1]), signature))
+	})
+
+	t.Run("Success with ECDSA DER key in JSON serialization", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ECDSAP256TypeDER})
+
+		signResp, err := execute[SignJWSResponse](cmd.SignJWS, keyID, &SignJWSRequest{
+			Payload:       []byte("test payload"),
+			Headers:       map[string]interface{}{"kid": "did:example:test#key-1"},
+			Serialization: JWSJSON,
+		})
+		require.NoError(t, err)
+
+		var jws struct {
+			Payload    string `json:"payload"`
+			Signatures []struct {
+				Protected string `json:"protected"`
+				Signature string `json:"signature"`
+			} `json:"signatures"`
+		}
+
+		require.NoError(t, json.Unmarshal(signResp.JWS, &jws))
+		require.Len(t, jws.Signatures, 1)
+
+		protected, err := base64.RawURLEncoding.DecodeString(jws.Signatures[0].Protected)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"alg":"ES256","kid":"did:example:test#key-1"}`, string(protected))
+
+		signature, err := base64.RawURLEncoding.DecodeString(jws.Signatures[0].Signature)
+		require.NoError(t, err)
+		require.Len(t, signature, 64) // IEEE P1363
+
+		pub := exportKey(t, cmd, keyID, FormatJWK)
+
+		digest := sha256.Sum256([]byte(jws.Signatures[0].Protected + "." + jws.Payload))
+
+		require.True(t, ecdsa.Verify(pub.JWK.Key.(*ecdsa.PublicKey), digest[:],
+			new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])))
+	})
+
+	t.Run("Fail with key not allowed to sign", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.AES256GCMType})
+
+		_, err := execute[SignJWSResponse](cmd.SignJWS, keyID, &SignJWSRequest{Payload: []byte("test payload")})
+		require.EqualError(t, err, "bad request: operation sign is not allowed for the key")
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[SignJWSResponse](cmd.SignJWS, "key_id", &SignJWSRequest{
+			Payload: []byte("test payload"),
+			Headers: map[string]interface{}{"alg": "none"},
+		})
+		require.EqualError(t, err, "validate request: validation failed: alg header is set from the key type")
+
+		_, err = execute[SignJWSResponse](cmd.SignJWS, "key_id", &SignJWSRequest{
+			Payload:       []byte("test payload"),
+			Serialization: "invalid",
+		})
+		require.EqualError(t, err, `validate request: validation failed: unsupported serialization "invalid"`)
+
+		_, err = execute[SignJWSResponse](cmd.SignJWS, "key_id", &SignJWSRequest{
+			Payload: []byte("test payload"),
+			Headers: map[string]interface{}{"b64": false},
+		})
+		require.EqualError(t, err, "validate request: validation failed: unencoded payload is not supported")
+
+		_, err = execute[SignJWSResponse](cmd.SignJWS, "key_id", &SignJWSRequest{
+			Payload: []byte("test payload"),
+			Headers: map[string]interface{}{"crit": []string{"exp"}},
+		})
+		require.EqualError(t, err, "validate request: validation failed: crit header is not supported")
+	})
+}
+
+func TestCommand_VerifyJWS(t *testing.T) {
+	t.Run("Success with compact serialization", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ECDSAP384TypeDER})
+
+		signResp, err := execute[SignJWSResponse](cmd.SignJWS, keyID, &SignJWSRequest{
+			Payload: []byte("test payload"),
+			Headers: map[string]interface{}{"typ": "JWT"},
+		})
+		require.NoError(t, err)
+
+		resp, err := execute[VerifyJWSResponse](cmd.VerifyJWS, keyID, &VerifyJWSRequest{JWS: signResp.JWS})
+		require.NoError(t, err)
+		require.Equal(t, []byte("test payload"), resp.Payload)
+		require.Equal(t, "ES384", resp.Headers["alg"])
+		require.Equal(t, "JWT", resp.Headers["typ"])
+	})
+
+	t.Run("Success with flattened JSON serialization", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signResp, err := execute[SignJWSResponse](cmd.SignJWS, keyID, &SignJWSRequest{Payload: []byte("test payload")})
+		require.NoError(t, err)
+
+		var compact string
+
+		require.NoError(t, json.Unmarshal(signResp.JWS, &compact))
+
+		parts := strings.Split(compact, ".")
+
+		flattened := fmt.Sprintf(`{"protected":%q,"payload":%q,"signature":%q}`, parts[0], parts[1], parts[2])
+
+		resp, err := execute[VerifyJWSResponse](cmd.VerifyJWS, keyID, &VerifyJWSRequest{JWS: []byte(flattened)})
+		require.NoError(t, err)
+		require.Equal(t, []byte("test payload"), resp.Payload)
+	})
+
+	t.Run("Fail with modified payload", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signResp, err := execute[SignJWSResponse](cmd.SignJWS, keyID, &SignJWSRequest{Payload: []byte("test payload")})
+		require.NoError(t, err)
+
+		var compact string
+
+		require.NoError(t, json.Unmarshal(signResp.JWS, &compact))
+
+		parts := strings.Split(compact, ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte("other payload"))
+
+		jws, err := json.Marshal(strings.Join(parts, "."))
+		require.NoError(t, err)
+
+		_, err = execute[VerifyJWSResponse](cmd.VerifyJWS, keyID, &VerifyJWSRequest{JWS: jws})
+		require.EqualError(t, err, "bad request: jws signature verification failed")
+	})
+
+	t.Run("Fail with crit header", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		jws := signCompactJWS(t, cmd, keyID, `{"alg":"EdDSA","crit":["exp"],"exp":1}`, `{"sub":"user"}`)
+
+		_, err := execute[VerifyJWSResponse](cmd.VerifyJWS, keyID, &VerifyJWSRequest{JWS: []byte(`"` + jws + `"`)})
+		require.EqualError(t, err, "bad request: jws signature verification failed")
+
+		_, err = execute[VerifyJWTResponse](cmd.VerifyJWT, keyID, &VerifyJWTRequest{JWT: jws})
+		require.EqualError(t, err, "bad request: jwt signature verification failed")
+
+		// same JWS without crit header is valid
+		jws = signCompactJWS(t, cmd, keyID, `{"alg":"EdDSA","exp":1}`, `{"sub":"user"}`)
+
+		_, err = execute[VerifyJWSResponse](cmd.VerifyJWS, keyID, &VerifyJWSRequest{JWS: []byte(`"` + jws + `"`)})
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail with invalid JWS", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[VerifyJWSResponse](cmd.VerifyJWS, "key_id", &VerifyJWSRequest{JWS: []byte(`"invalid"`)})
+		require.EqualError(t, err, "bad request: invalid jws compact serialization")
+
+		_, err = execute[VerifyJWSResponse](cmd.VerifyJWS, "key_id", &VerifyJWSRequest{JWS: []byte(`{"payload":""}`)})
+		require.EqualError(t, err, "bad request: jws has no signatures")
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[VerifyJWSResponse](cmd.VerifyJWS, "key_id", &VerifyJWSRequest{})
+		require.EqualError(t, err, "validate request: validation failed: jws must be non-empty")
+	})
+}
+
+func TestCommand_SignJWT(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ECDSAP256TypeIEEEP1363})
+
+		signResp, err := execute[SignJWTResponse](cmd.SignJWT, keyID, &SignJWTRequest{
+			Claims:    map[string]interface{}{"iss": "did:example:issuer", "sub": "user"},
+			ExpiresIn: 600,
+		})
+		require.NoError(t, err)
+
+		parts := strings.Split(signResp.JWT, ".")
+		require.Len(t, parts, 3)
+
+		protected, err := base64.RawURLEncoding.DecodeString(parts[0])
+		require.NoError(t, err)
+		require.JSONEq(t, fmt.Sprintf(`{"alg":"ES256","kid":%q,"typ":"JWT"}`, keyID), string(protected))
+
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+
+		var claims struct {
+			Issuer    string `json:"iss"`
+			Subject   string `json:"sub"`
+			IssuedAt  int64  `json:"iat"`
+			ExpiresAt int64  `json:"exp"`
+			ID        string `json:"jti"`
+		}
+
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		require.Equal(t, "did:example:issuer", claims.Issuer)
+		require.Equal(t, "user", claims.Subject)
+		require.Equal(t, int64(600), claims.ExpiresAt-claims.IssuedAt)
+		require.NotEmpty(t, claims.ID)
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[SignJWTResponse](cmd.SignJWT, "key_id", &SignJWTRequest{
+			Claims: map[string]interface{}{"exp": 1},
+		})
+		require.EqualError(t, err, "validate request: validation failed: exp claim is set by the server")
+
+		_, err = execute[SignJWTResponse](cmd.SignJWT, "key_id", &SignJWTRequest{ExpiresIn: 100000})
+		require.EqualError(t, err, "validate request: validation failed: expires in must be between 0 and 86400 seconds")
+
+		_, err = execute[SignJWTResponse](cmd.SignJWT, "key_id", &SignJWTRequest{ExpiresIn: -1})
+		require.EqualError(t, err, "validate request: validation failed: expires in must be non-negative")
+	})
+
+	t.Run("Configured JWT lifetime", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withJWTLifetime(10*time.Minute, time.Hour))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signResp, err := execute[SignJWTResponse](cmd.SignJWT, keyID, &SignJWTRequest{})
+		require.NoError(t, err)
+
+		payload, err := base64.RawURLEncoding.DecodeString(strings.Split(signResp.JWT, ".")[1])
+		require.NoError(t, err)
+
+		var claims struct {
+			IssuedAt  int64 `json:"iat"`
+			ExpiresAt int64 `json:"exp"`
+		}
+
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		require.Equal(t, int64(600), claims.ExpiresAt-claims.IssuedAt)
+
+		_, err = execute[SignJWTResponse](cmd.SignJWT, keyID, &SignJWTRequest{ExpiresIn: 3601})
+		require.EqualError(t, err, "validate request: validation failed: expires in must be between 0 and 3600 seconds")
+	})
+
+	t.Run("JWT lifetime exceeds max lifetime", func(t *testing.T) {
+		_, err := New(&Config{
+			StorageProvider: mockstorage.NewMockStoreProvider(),
+			JWTLifetime:     48 * time.Hour,
+		})
+		require.EqualError(t, err, "jwt lifetime 48h0m0s exceeds max lifetime 24h0m0s")
+	})
+}
+
+func TestCommand_VerifyJWT(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signResp, err := execute[SignJWTResponse](cmd.SignJWT, keyID, &SignJWTRequest{
+			Claims: map[string]interface{}{
+				"iss": "did:example:issuer",
+				"aud": []string{"https://api.example.com", "https://other.example.com"},
+			},
+		})
+		require.NoError(t, err)
+
+		resp, err := execute[VerifyJWTResponse](cmd.VerifyJWT, keyID, &VerifyJWTRequest{
+			JWT:      signResp.JWT,
+			Audience: "https://api.example.com",
+			Issuer:   "did:example:issuer",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "did:example:issuer", resp.Claims["iss"])
+		require.NotEmpty(t, resp.Claims["jti"])
+	})
+
+	t.Run("Fail with invalid claims", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signResp, err := execute[SignJWTResponse](cmd.SignJWT, keyID, &SignJWTRequest{
+			Claims: map[string]interface{}{"iss": "did:example:issuer", "aud": "https://api.example.com"},
+		})
+		require.NoError(t, err)
+
+		_, err = execute[VerifyJWTResponse](cmd.VerifyJWT, keyID, &VerifyJWTRequest{
+			JWT:      signResp.JWT,
+			Audience: "https://other.example.com",
+		})
+		require.EqualError(t, err, "bad request: invalid jwt audience")
+
+		_, err = execute[VerifyJWTResponse](cmd.VerifyJWT, keyID, &VerifyJWTRequest{
+			JWT:    signResp.JWT,
+			Issuer: "did:example:other",
+		})
+		require.EqualError(t, err, "bad request: invalid jwt issuer")
+	})
+
+	t.Run("Fail with expired JWT", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		jwt := signClaims(t, cmd, keyID, map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})
+
+		_, err := execute[VerifyJWTResponse](cmd.VerifyJWT, keyID, &VerifyJWTRequest{JWT: jwt})
+		require.EqualError(t, err, "bad request: jwt is expired")
+	})
+
+	t.Run("Fail with JWT without expiry", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		jwt := signClaims(t, cmd, keyID, map[string]interface{}{"sub": "user"})
+
+		_, err := execute[VerifyJWTResponse](cmd.VerifyJWT, keyID, &VerifyJWTRequest{JWT: jwt})
+		require.EqualError(t, err, "bad request: jwt has no valid exp claim")
+	})
+
+	t.Run("Fail with JWT not valid yet", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		jwt := signClaims(t, cmd, keyID, map[string]interface{}{
+			"exp": time.Now().Add(2 * time.Hour).Unix(),
+			"nbf": time.Now().Add(time.Hour).Unix(),
+		})
+
+		_, err := execute[VerifyJWTResponse](cmd.VerifyJWT, keyID, &VerifyJWTRequest{JWT: jwt})
+		require.EqualError(t, err, "bad request: jwt is not valid yet")
+	})
+
+	t.Run("Fail with JWT signed by another key", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+		otherKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signResp, err := execute[SignJWTResponse](cmd.SignJWT, otherKeyID, &SignJWTRequest{})
+		require.NoError(t, err)
+
+		_, err = execute[VerifyJWTResponse](cmd.VerifyJWT, keyID, &VerifyJWTRequest{JWT: signResp.JWT})
+		require.EqualError(t, err, "bad request: jwt signature verification failed")
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[VerifyJWTResponse](cmd.VerifyJWT, "key_id", &VerifyJWTRequest{})
+		require.EqualError(t, err, "validate request: validation failed: jwt must be non-empty")
+	})
+}
+
+func TestCommand_SignLDProof(t *testing.T) {
+	t.Run("Success with Ed25519Signature2018", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withDocumentLoader(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		resp, err := execute[SignLDProofResponse](cmd.SignLDProof, keyID, &SignLDProofRequest{
+			Document:           []byte(testCredential),
+			SignatureType:      "Ed25519Signature2018",
+			VerificationMethod: "did:example:issuer#key1",
+		})
+		require.NoError(t, err)
+
+		vc := parseCredential(t, resp.Document, &verifier.PublicKey{
+			Type:  "Ed25519VerificationKey2018",
+			Value: exportKey(t, cmd, keyID, "").PublicKey,
+		})
+		require.Len(t, vc.Proofs, 1)
+		require.Equal(t, "Ed25519Signature2018", vc.Proofs[0]["type"])
+		require.Equal(t, "assertionMethod", vc.Proofs[0]["proofPurpose"])
+		require.Equal(t, "did:example:issuer#key1", vc.Proofs[0]["verificationMethod"])
+		require.Contains(t, vc.Proofs[0], "jws")
+	})
+
+	t.Run("Success with Ed25519Signature2020", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withDocumentLoader(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+		created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		resp, err := execute[SignLDProofResponse](cmd.SignLDProof, keyID, &SignLDProofRequest{
+			Document:           credentialWithContext("https://w3id.org/security/suites/ed25519-2020/v1"),
+			SignatureType:      "Ed25519Signature2020",
+			VerificationMethod: "did:example:issuer#key1",
+			Created:            &created,
+		})
+		require.NoError(t, err)
+
+		vc := parseCredential(t, resp.Document, &verifier.PublicKey{
+			Type:  "Ed25519VerificationKey2020",
+			Value: exportKey(t, cmd, keyID, "").PublicKey,
+		})
+		require.Len(t, vc.Proofs, 1)
+		require.Equal(t, "2022-01-02T03:04:05Z", vc.Proofs[0]["created"])
+		require.Contains(t, vc.Proofs[0], "proofValue")
+	})
+
+	t.Run("Success with JsonWebSignature2020", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withDocumentLoader(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ECDSAP256TypeDER})
+
+		resp, err := execute[SignLDProofResponse](cmd.SignLDProof, keyID, &SignLDProofRequest{
+			Document:           credentialWithContext("https://w3id.org/security/suites/jws-2020/v1"),
+			SignatureType:      "JsonWebSignature2020",
+			VerificationMethod: "did:example:issuer#key1",
+		})
+		require.NoError(t, err)
+
+		vc := parseCredential(t, resp.Document, &verifier.PublicKey{
+			Type: "JsonWebKey2020",
+			JWK:  exportKey(t, cmd, keyID, "jwk").JWK,
+		})
+		require.Len(t, vc.Proofs, 1)
+		require.Equal(t, "JsonWebSignature2020", vc.Proofs[0]["type"])
+	})
+
+	t.Run("Success with BbsBlsSignature2020", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withDocumentLoader(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.BLS12381G2Type})
+
+		resp, err := execute[SignLDProofResponse](cmd.SignLDProof, keyID, &SignLDProofRequest{
+			Document:           credentialWithContext("https://w3id.org/security/bbs/v1"),
+			SignatureType:      "BbsBlsSignature2020",
+			VerificationMethod: "did:example:issuer#key1",
+		})
+		require.NoError(t, err)
+
+		vc := parseCredential(t, resp.Document, &verifier.PublicKey{
+			Type:  "Bls12381G2Key2020",
+			Value: exportKey(t, cmd, keyID, "").PublicKey,
+		})
+		require.Len(t, vc.Proofs, 1)
+		require.Equal(t, "BbsBlsSignature2020", vc.Proofs[0]["type"])
+	})
+
+	t.Run("Success with presentation", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withDocumentLoader(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		resp, err := execute[SignLDProofResponse](cmd.SignLDProof, keyID, &SignLDProofRequest{
+			Document:           []byte(testPresentation),
+			SignatureType:      "Ed25519Signature2018",
+			VerificationMethod: "did:example:holder#key1",
+			Domain:             "example.com",
+			Challenge:          "challenge",
+		})
+		require.NoError(t, err)
+
+		vp, err := verifiable.ParsePresentation(resp.Document,
+			verifiable.WithPresPublicKeyFetcher(verifiable.SingleKey(exportKey(t, cmd, keyID, "").PublicKey,
+				"Ed25519VerificationKey2018")),
+			verifiable.WithPresJSONLDDocumentLoader(testDocumentLoader(t)),
+		)
+		require.NoError(t, err)
+		require.Len(t, vp.Proofs, 1)
+		require.Equal(t, "authentication", vp.Proofs[0]["proofPurpose"])
+		require.Equal(t, "example.com", vp.Proofs[0]["domain"])
+		require.Equal(t, "challenge", vp.Proofs[0]["challenge"])
+	})
+
+	t.Run("Fail with key of another type", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withDocumentLoader(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ECDSAP256TypeDER})
+
+		_, err := execute[SignLDProofResponse](cmd.SignLDProof, keyID, &SignLDProofRequest{
+			Document:           []byte(testCredential),
+			SignatureType:      "Ed25519Signature2018",
+			VerificationMethod: "did:example:issuer#key1",
+		})
+		require.EqualError(t, err,
+			"bad request: key of type ECDSAP256DER can't be used for Ed25519Signature2018 proof")
+	})
+
+	t.Run("Fail with undefined proof terms", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withDocumentLoader(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.BLS12381G2Type})
+
+		_, err := execute[SignLDProofResponse](cmd.SignLDProof, keyID, &SignLDProofRequest{
+			Document: []byte(`{
+				"@context": ["https://www.w3.org/2018/credentials/v1"],
+				"type": "VerifiableCredential",
+				"undefined": "x"
+			}`),
+			SignatureType:      "BbsBlsSignature2020",
+			VerificationMethod: "did:example:issuer#key1",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request: add proof")
+	})
+
+	t.Run("Fail with document of another type", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withDocumentLoader(t))
+
+		_, err := execute[SignLDProofResponse](cmd.SignLDProof, "key_id", &SignLDProofRequest{
+			Document:           []byte(`{"type":["Capability"]}`),
+			SignatureType:      "Ed25519Signature2018",
+			VerificationMethod: "did:example:issuer#key1",
+		})
+		require.EqualError(t, err, "bad request: document is not a verifiable credential or presentation")
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[SignLDProofResponse](cmd.SignLDProof, "key_id", &SignLDProofRequest{
+			SignatureType:      "Ed25519Signature2018",
+			VerificationMethod: "did:example:issuer#key1",
+		})
+		require.EqualError(t, err, "validate request: validation failed: document must be non-empty")
+
+		_, err = execute[SignLDProofResponse](cmd.SignLDProof, "key_id", &SignLDProofRequest{
+			Document:           []byte(testCredential),
+			SignatureType:      "RsaSignature2018",
+			VerificationMethod: "did:example:issuer#key1",
+		})
+		require.EqualError(t, err, `validate request: validation failed: unsupported signature type "RsaSignature2018"`)
+
+		_, err = execute[SignLDProofResponse](cmd.SignLDProof, "key_id", &SignLDProofRequest{
+			Document:      []byte(testCredential),
+			SignatureType: "Ed25519Signature2018",
+		})
+		require.EqualError(t, err, "validate request: validation failed: verification method must be non-empty")
+	})
+}
+
+func TestCommand_Encrypt(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			EncryptValue:      []byte("ciphertext"),
+			EncryptNonceValue: []byte("nonce"),
+		}))
+
+		req, err := json.Marshal(EncryptRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.Encrypt(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp EncryptResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("ciphertext"), resp.Ciphertext)
+		require.Equal(t, []byte("nonce"), resp.Nonce)
+	})
+
+	t.Run("Fail if key type doesn't support encryption", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+		putKeyMeta(t, p, "key_store_id", "key_id")
+
+		cmd := createCmd(t, gomock.NewController(t), withStorageProvider(p))
+
+		req, err := json.Marshal(EncryptRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.Encrypt(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: operation encrypt is not allowed for the key")
+	})
+
+	t.Run("Fail to encrypt", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			EncryptErr: errors.New("encrypt error"),
+		}))
+
+		req, err := json.Marshal(EncryptRequest{
+			Message: []byte("test message"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.Encrypt(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "encrypt: encrypt error")
+	})
+}
+
+func TestCommand_Decrypt(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			DecryptValue: []byte("plaintext"),
+		}))
+
+		req, err := json.Marshal(DecryptRequest{
+			Ciphertext:     []byte("ciphertext"),
+			AssociatedData: []byte("ad"),
+			Nonce:          []byte("nonce"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.Decrypt(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp DecryptResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("plaintext"), resp.Plaintext)
+	})
+
+	t.Run("Fail to decrypt", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			DecryptErr: errors.New("decrypt error"),
+		}))
+
+		req, err := json.Marshal(DecryptRequest{
+			Ciphertext:     []byte("ciphertext"),
+			AssociatedData: []byte("ad"),
+			Nonce:          []byte("nonce"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.Decrypt(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "decrypt: decrypt error")
+	})
+}
+
+func TestCommand_ComputeMAC(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			ComputeMACValue: []byte("mac"),
+		}))
+
+		req, err := json.Marshal(ComputeMACRequest{
+			Data: []byte("data"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.ComputeMAC(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp ComputeMACResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("mac"), resp.MAC)
+	})
+
+	t.Run("Fail to compute MAC", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			ComputeMACErr: errors.New("compute mac error"),
+		}))
+
+		req, err := json.Marshal(ComputeMACRequest{
+			Data: []byte("data"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.ComputeMAC(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "compute mac: compute mac error")
+	})
+}
+
+func TestCommand_VerifyMAC(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{}))
+
+		req, err := json.Marshal(VerifyMACRequest{
+			Data: []byte("data"),
+			MAC:  []byte("mac"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.VerifyMAC(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail to verify MAC", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			VerifyMACErr: errors.New("verify mac error"),
+		}))
+
+		req, err := json.Marshal(VerifyMACRequest{
+			Data: []byte("data"),
+			MAC:  []byte("mac"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.VerifyMAC(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "verify mac: verify mac error")
+	})
+}
+
+func TestCommand_SignMulti(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			BBSSignValue: []byte("signature"),
+		}))
+
+		req, err := json.Marshal(SignMultiRequest{
+			Messages: [][]byte{
+				[]byte("test message 1"),
+				[]byte("test message 2"),
+			},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.SignMulti(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp SignMultiResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("signature"), resp.Signature)
+	})
+
+	t.Run("Fail to sign multi", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			BBSSignErr: errors.New("sign error"),
+		}))
+
+		req, err := json.Marshal(SignMultiRequest{
+			Messages: [][]byte{
+				[]byte("test message 1"),
+				[]byte("test message 2"),
+			},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.SignMulti(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "sign multi: sign error")
+	})
+}
+
+func TestCommand_VerifyMulti(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		cmd := createCmd(t, gomock.NewController(t),
+			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
+			withCrypto(&mockcrypto.Crypto{}),
+		)
+
+		req, err := json.Marshal(VerifyMultiRequest{
+			Messages: [][]byte{
+				[]byte("test message 1"),
+				[]byte("test message 2"),
+			},
+			Signature: []byte("signature"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.VerifyMulti(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail to verify a signature", func(t *testing.T) {
+		kh, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		cmd := createCmd(t, gomock.NewController(t),
+			withKeyManager(&mockkms.KeyManager{GetKeyValue: kh}),
+			withCrypto(&mockcrypto.Crypto{
+				BBSVerifyErr: errors.New("verify error"),
+			}),
+		)
+
+		req, err := json.Marshal(VerifyMultiRequest{
+			Messages: [][]byte{
+				[]byte("test message 1"),
+				[]byte("test message 2"),
+			},
+			Signature: []byte("signature"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.VerifyMulti(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "verify multi: verify error")
+	})
+}
+
+func TestCommand_DeriveProof(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			DeriveProofValue: []byte("proof"),
+		}))
+
+		req, err := json.Marshal(DeriveProofRequest{
+			Messages: [][]byte{
+				[]byte("test message 1"),
+				[]byte("test message 2"),
+			},
+			Signature:       []byte("signature"),
+			Nonce:           []byte("nonce"),
+			RevealedIndexes: []int{1},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.DeriveProof(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp DeriveProofResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("proof"), resp.Proof)
+	})
+
+	t.Run("Fail to derive proof", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			DeriveProofError: errors.New("derive proof error"),
+		}))
+
+		req, err := json.Marshal(DeriveProofRequest{
+			Messages: [][]byte{
+				[]byte("test message 1"),
+				[]byte("test message 2"),
+			},
+			Signature:       []byte("signature"),
+			Nonce:           []byte("nonce"),
+			RevealedIndexes: []int{1},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.DeriveProof(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "derive proof: derive proof error")
+	})
+}
+
+func TestCommand_VerifyProof(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{}))
+
+		req, err := json.Marshal(VerifyProofRequest{
+			Messages: [][]byte{
+				[]byte("test message 1"),
+				[]byte("test message 2"),
+			},
+			Proof: []byte("proof"),
+			Nonce: []byte("nonce"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.VerifyProof(nil, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+	})
+
+	t.Run("Fail to verify proof", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			VerifyProofErr: errors.New("verify proof error"),
+		}))
+
+		req, err := json.Marshal(VerifyProofRequest{
+			Messages: [][]byte{
+				[]byte("test message 1"),
+				[]byte("test message 2"),
+			},
+			Proof: []byte("proof"),
+			Nonce: []byte("nonce"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.VerifyProof(nil, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "verify proof: verify proof error")
+	})
+}
+
+func TestCommand_Batch(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Success (parallel: %t)", parallel), func(t *testing.T) {
+			cmd := createCmdWithLocalKMS(t, withMemStorage(t))
+
+			signingKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+			encKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.AES256GCMType})
+			macKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.HMACSHA256Tag256Type})
+
+			msg := []byte("test message")
+
+			resp, err := execute[BatchResponse](cmd.Batch, "", &BatchRequest{
+				Operations: []BatchOperation{
+					batchOperation(t, ActionSign, signingKeyID, SignRequest{Message: msg}),
+					batchOperation(t, ActionEncrypt, encKeyID, EncryptRequest{Message: msg}),
+					batchOperation(t, ActionComputeMac, macKeyID, ComputeMACRequest{Data: msg}),
+					batchOperation(t, ActionSign, encKeyID, SignRequest{Message: msg}),
+					batchOperation(t, ActionSign, "unknown", SignRequest{Message: msg}),
+				},
+				Parallel: parallel,
+			})
+			require.NoError(t, err)
+			require.Len(t, resp.Results, 5)
+
+			var signResp SignResponse
+
+			require.Empty(t, resp.Results[0].Error)
+			require.NoError(t, json.Unmarshal(resp.Results[0].Result, &signResp))
+
+			var encResp EncryptResponse
+
+			require.Empty(t, resp.Results[1].Error)
+			require.NoError(t, json.Unmarshal(resp.Results[1].Result, &encResp))
+
+			require.Empty(t, resp.Results[2].Error)
+			require.NotEmpty(t, resp.Results[2].Result)
+
+			require.Equal(t, "bad request: operation sign is not allowed for the key", resp.Results[3].Error)
+			require.NotEmpty(t, resp.Results[4].Error)
+			require.Empty(t, resp.Results[4].Result)
+
+			resp, err = execute[BatchResponse](cmd.Batch, "", &BatchRequest{
+				Operations: []BatchOperation{
+					batchOperation(t, ActionVerify, signingKeyID, VerifyRequest{Signature: signResp.Signature, Message: msg}),
+					batchOperation(t, ActionVerify, signingKeyID, VerifyRequest{Signature: signResp.Signature}),
+					batchOperation(t, ActionDecrypt, encKeyID, DecryptRequest{
+						Ciphertext: encResp.Ciphertext,
+						Nonce:      encResp.Nonce,
+					}),
+				},
+				Parallel: parallel,
+			})
+			require.NoError(t, err)
+			require.Len(t, resp.Results, 3)
+
+			require.Equal(t, BatchResult{}, resp.Results[0])
+			require.Contains(t, resp.Results[1].Error, "verify:")
+
+			var decResp DecryptResponse
+
+			require.Empty(t, resp.Results[2].Error)
+			require.NoError(t, json.Unmarshal(resp.Results[2].Result, &decResp))
+			require.Equal(t, msg, decResp.Plaintext)
+		})
+	}
+
+	t.Run("Fail to decode operation request", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withMemStorage(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		resp, err := execute[BatchResponse](cmd.Batch, "", &BatchRequest{
+			Operations: []BatchOperation{{Operation: ActionSign, KeyID: keyID, Request: []byte(`"message"`)}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "bad request: decode request", resp.Results[0].Error)
+	})
+
+	t.Run("Key store is deactivated", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.Store["key_store_id"] = mockstorage.DBEntry{
+			Value: []byte(`{"id":"key_store_id","status":"deactivated"}`),
+		}
+
+		cmd := createCmdWithLocalKMS(t, withStorageProvider(p))
+
+		_, err := execute[BatchResponse](cmd.Batch, "", &BatchRequest{
+			Operations: []BatchOperation{batchOperation(t, ActionSign, "key_id", SignRequest{})},
+		})
+		require.EqualError(t, err, "resolve key store: conflict: key store is deactivated")
+	})
+
+	t.Run("Operation is not allowed by the capability", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t, withMemStorage(t))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		req, err := json.Marshal(&BatchRequest{
+			Operations: []BatchOperation{
+				batchOperation(t, ActionSign, keyID, SignRequest{Message: []byte("test message")}),
+				batchOperation(t, ActionVerify, keyID, VerifyRequest{Message: []byte("test message")}),
+			},
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+			Capability: &InvokedCapability{AllowedActions: []string{ActionBatch, ActionSign}},
+		})
+		require.NoError(t, err)
+
+		err = cmd.Batch(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "forbidden: action verify is not allowed by the capability")
+	})
+
+	t.Run("Fail to validate request", func(t *testing.T) {
+		cmd, err := New(&Config{StorageProvider: mockstorage.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		_, err = execute[BatchResponse](cmd.Batch, "", &BatchRequest{})
+		require.EqualError(t, err, "validate request: validation failed: operations must be non-empty")
+
+		_, err = execute[BatchResponse](cmd.Batch, "", &BatchRequest{
+			Operations: []BatchOperation{batchOperation(t, ActionExportKey, "key_id", nil)},
+		})
+		require.EqualError(t, err, `validate request: validation failed: operation 0: unsupported operation "exportKey"`)
+
+		_, err = execute[BatchResponse](cmd.Batch, "", &BatchRequest{
+			Operations: []BatchOperation{batchOperation(t, ActionSign, "", SignRequest{})},
+		})
+		require.EqualError(t, err, "validate request: validation failed: operation 0: key id must be non-empty")
+	})
+}
+
+func TestCommand_KeyManagerCache(t *testing.T) {
+	t.Run("Key manager is resolved once and reused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		metrics := NewMockMetricsProvider(ctrl)
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyStoreGetKeyTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().CryptoSignTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyManagerCacheMiss().Times(1)
+		metrics.EXPECT().KeyManagerCacheHit().Times(2)
+
+		cache := newMemKeyManagerCache()
+
+		cmd := createCmdWithLocalKMS(t, withKeyManagerCache(cache, time.Minute), withMetrics(metrics))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signMessage(t, cmd, keyID)
+		signMessage(t, cmd, keyID)
+
+		require.Equal(t, 1, cache.len())
+	})
+
+	t.Run("Key manager is re-created after key store metadata update", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		metrics := NewMockMetricsProvider(ctrl)
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyStoreGetKeyTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().CryptoSignTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyManagerCacheMiss().Times(2)
+		metrics.EXPECT().KeyManagerCacheHit().Times(1)
+
+		p := mem.NewProvider()
+
+		store, err := p.OpenStore("keystores")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key_store_id", []byte(`{"id":"key_store_id"}`)))
+
+		cmd := createCmdWithLocalKMS(t, withStorageProvider(p), withKeyManagerCache(newMemKeyManagerCache(), time.Minute),
+			withMetrics(metrics))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signMessage(t, cmd, keyID)
+
+		require.NoError(t, store.Put("key_store_id", []byte(`{"id":"key_store_id","controller":"did:example:new"}`)))
+
+		signMessage(t, cmd, keyID)
+	})
+
+	t.Run("Cached key manager is not used for deactivated key store", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		metrics := NewMockMetricsProvider(ctrl)
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyStoreGetKeyTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().CryptoSignTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyManagerCacheMiss().Times(1)
+
+		p := mem.NewProvider()
+
+		store, err := p.OpenStore("keystores")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key_store_id", []byte(`{"id":"key_store_id"}`)))
+
+		cmd := createCmdWithLocalKMS(t, withStorageProvider(p), withKeyManagerCache(newMemKeyManagerCache(), time.Minute),
+			withMetrics(metrics))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		require.NoError(t, store.Put("key_store_id", []byte(`{"id":"key_store_id","status":"deactivated"}`)))
+
+		req, err := json.Marshal(SignRequest{Message: []byte("test message")})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+		require.NoError(t, err)
+
+		err = cmd.Sign(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "resolve key store: conflict: key store is deactivated")
+	})
+
+	t.Run("Key manager is removed from the cache on key store update, deactivation and deletion", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		metrics := NewMockMetricsProvider(ctrl)
+		metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyStoreGetKeyTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().CryptoSignTime(gomock.Any()).AnyTimes()
+		metrics.EXPECT().KeyManagerCacheMiss().Times(3)
+		metrics.EXPECT().KeyManagerCacheHit().Times(1)
+
+		cache := newMemKeyManagerCache()
+
+		cmd := createCmdWithLocalKMS(t, withMemStorage(t), withKeyManagerCache(cache, time.Minute),
+			withMetrics(metrics))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signMessage(t, cmd, keyID)
+		require.Equal(t, 1, cache.len())
+
+		req, err := json.Marshal(UpdateKeyStoreRequest{Controller: "did:example:new"})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", Request: req})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.UpdateKeyStore(&bytes.Buffer{}, bytes.NewBuffer(wr)))
+		require.Equal(t, 0, cache.len())
+
+		signMessage(t, cmd, keyID)
+		require.Equal(t, 1, cache.len())
+
+		wr, err = json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+		require.NoError(t, err)
+
+		require.NoError(t, cmd.DeactivateKeyStore(nil, bytes.NewBuffer(wr)))
+		require.Equal(t, 0, cache.len())
+
+		require.NoError(t, cmd.ActivateKeyStore(nil, bytes.NewBuffer(wr)))
+
+		signMessage(t, cmd, keyID)
+		require.Equal(t, 1, cache.len())
+
+		deleteKeyStore(t, cmd)
+		require.Equal(t, 0, cache.len())
+	})
+
+	t.Run("Key manager is not cached with zero TTL", func(t *testing.T) {
+		cache := newMemKeyManagerCache()
+
+		cmd := createCmdWithLocalKMS(t, withKeyManagerCache(cache, 0))
+
+		keyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.ED25519Type})
+
+		signMessage(t, cmd, keyID)
+
+		require.Equal(t, 0, cache.len())
+	})
+}
+
+func TestCommand_Easy(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		cryptoBox := NewMockCryptoBox(ctrl)
+		cryptoBox.EXPECT().Easy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]byte("ciphertext"), nil).Times(1)
+
+		creator := NewMockCryptoBoxCreator(ctrl)
+		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+
+		cmd := createCmd(t, ctrl, withCryptoBoxCreator(creator))
+
+		req, err := json.Marshal(EasyRequest{
+			Payload:  []byte("payload"),
+			Nonce:    []byte("nonce"),
+			TheirPub: []byte("their pub"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.WrapKey(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp EasyResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("ciphertext"), resp.Ciphertext)
+	})
+
+	t.Run("Fail to seal a payload", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		cryptoBox := NewMockCryptoBox(ctrl)
+		cryptoBox.EXPECT().Easy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("easy error")).Times(1)
+
+		creator := NewMockCryptoBoxCreator(ctrl)
+		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+
+		cmd := createCmd(t, ctrl, withCryptoBoxCreator(creator))
+
+		req, err := json.Marshal(EasyRequest{
+			Payload:  []byte("payload"),
+			Nonce:    []byte("nonce"),
+			TheirPub: []byte("their pub"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.WrapKey(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "unwrap wrap request: easy: easy error")
+	})
+}
+
+func TestCommand_EasyOpen(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		cryptoBox := NewMockCryptoBox(ctrl)
+		cryptoBox.EXPECT().EasyOpen(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]byte("plaintext"), nil).Times(1)
+
+		creator := NewMockCryptoBoxCreator(ctrl)
+		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+
+		cmd := createCmd(t, gomock.NewController(t), withCryptoBoxCreator(creator))
+
+		req, err := json.Marshal(EasyOpenRequest{
+			Ciphertext: []byte("payload"),
+			Nonce:      []byte("nonce"),
+			TheirPub:   []byte("their pub"),
+			MyPub:      []byte("my pub"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp EasyOpenResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("plaintext"), resp.Plaintext)
+	})
+
+	t.Run("Fail to unseal a ciphertext", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		cryptoBox := NewMockCryptoBox(ctrl)
+		cryptoBox.EXPECT().SealOpen(gomock.Any(), gomock.Any()).Return(nil, errors.New("seal open error")).Times(1)
+		cryptoBox.EXPECT().EasyOpen(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("easy open error")).Times(1)
+
+		creator := NewMockCryptoBoxCreator(ctrl)
+		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+
+		cmd := createCmd(t, gomock.NewController(t), withCryptoBoxCreator(creator))
+
+		req, err := json.Marshal(EasyOpenRequest{
+			Ciphertext: []byte("payload"),
+			Nonce:      []byte("nonce"),
+			TheirPub:   []byte("their pub"),
+			MyPub:      []byte("my pub"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+		// since both easyopen and sealopen are both part of UnwrapKey, if easyopen fails, sealopen will be executed
+		// and will be logged as last error regardless (we try easyopen first then sealopen in this order)
+		require.EqualError(t, err, "unwrapKey cryptobox request invalid: seal open: seal open error")
+	})
+}
+
+func TestCommand_SealOpen(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		cryptoBox := NewMockCryptoBox(ctrl)
+		cryptoBox.EXPECT().SealOpen(gomock.Any(), gomock.Any()).Return([]byte("plaintext"), nil).Times(1)
+		cryptoBox.EXPECT().EasyOpen(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil,
+			errors.New("easy open error")).Times(1)
+
+		creator := NewMockCryptoBoxCreator(ctrl)
+		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+
+		cmd := createCmd(t, gomock.NewController(t), withCryptoBoxCreator(creator))
+
+		req, err := json.Marshal(SealOpenRequest{
+			Ciphertext: []byte("payload"),
+			MyPub:      []byte("my pub"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp SealOpenResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("plaintext"), resp.Plaintext)
+	})
+
+	t.Run("Fail to decrypt a ciphertext", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		cryptoBox := NewMockCryptoBox(ctrl)
+		cryptoBox.EXPECT().SealOpen(gomock.Any(), gomock.Any()).Return(nil, errors.New("seal open error")).Times(1)
+		cryptoBox.EXPECT().EasyOpen(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil,
+			errors.New("easy open error")).Times(1)
+
+		creator := NewMockCryptoBoxCreator(ctrl)
+		creator.EXPECT().Create(gomock.Any()).Return(cryptoBox, nil).Times(1)
+
+		cmd := createCmd(t, gomock.NewController(t), withCryptoBoxCreator(creator))
+
+		req, err := json.Marshal(SealOpenRequest{
+			Ciphertext: []byte("payload"),
+			MyPub:      []byte("my pub"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "unwrapKey cryptobox request invalid: seal open: seal open error")
+	})
+}
+
+func TestCommand_WrapKey(t *testing.T) {
+	t.Run("Success with imported recipient public key", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		recipientKeyID := importPublicKey(t, cmd, kms.X25519ECDHKWType, random.GetRandomBytes(32))
+
+		req, err := json.Marshal(WrapKeyRequest{
+			CEK:            random.GetRandomBytes(32),
+			APU:            []byte("apu"),
+			APV:            []byte("apv"),
+			RecipientKeyID: recipientKeyID,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		require.NoError(t, cmd.WrapKey(&buf, bytes.NewBuffer(wr)))
+
+		var resp WrapKeyResponse
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+		require.Equal(t, recipientKeyID, resp.KID)
+		require.NotEmpty(t, resp.EncryptedCEK)
+	})
+
+	t.Run("Fail to wrap to a key that is not an imported public key", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		keyID := importPublicKey(t, cmd, kms.ED25519Type, random.GetRandomBytes(32))
+
+		req, err := json.Marshal(WrapKeyRequest{
+			CEK:            random.GetRandomBytes(32),
+			RecipientKeyID: keyID,
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		err = cmd.WrapKey(&bytes.Buffer{}, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "bad request: operation wrap is not allowed for the key")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			WrapValue: &crypto.RecipientWrappedKey{},
+		}))
+
+		req, err := json.Marshal(WrapKeyRequest{
+			CEK:             []byte("cek"),
+			APU:             []byte("apu"),
+			APV:             []byte("apv"),
+			RecipientPubKey: &crypto.PublicKey{},
+			Tag:             []byte("tag"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.WrapKey(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp WrapKeyResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.NotNil(t, resp.RecipientWrappedKey)
+	})
+
+	t.Run("Fail to wrap a key", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			WrapError: errors.New("wrap error"),
+		}))
+
+		req, err := json.Marshal(WrapKeyRequest{
+			CEK:             []byte("cek"),
+			APU:             []byte("apu"),
+			APV:             []byte("apv"),
+			RecipientPubKey: &crypto.PublicKey{},
+			Tag:             []byte("tag"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.WrapKey(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "wrap key: wrap error")
+	})
+}
+
+func TestCommand_UnwrapKey(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			UnwrapValue: []byte("key"),
+		}))
+
+		req, err := json.Marshal(UnwrapKeyRequest{
+			WrappedKey:   crypto.RecipientWrappedKey{EncryptedCEK: []byte{0}},
+			SenderPubKey: &crypto.PublicKey{},
+			Tag:          []byte("tag"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+		require.NoError(t, err)
+
+		var resp UnwrapKeyResponse
+
+		err = json.Unmarshal(buf.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, []byte("key"), resp.Key)
+	})
+
+	t.Run("Success with previous key versions", func(t *testing.T) {
+		cr, err := tinkcrypto.New()
+		require.NoError(t, err)
+
+		kh, err := keyset.NewHandle(ecdh.NISTP256ECDHKWKeyTemplate())
+		require.NoError(t, err)
+
+		oldPub, err := keyio.ExtractPrimaryPublicKey(kh)
+		require.NoError(t, err)
+
+		rotatedKH := rotateKeyset(t, kh, ecdh.NISTP256ECDHKWKeyTemplate())
+
+		newPub, err := keyio.ExtractPrimaryPublicKey(rotatedKH)
+		require.NoError(t, err)
+
+		for _, pub := range []*crypto.PublicKey{newPub, oldPub} {
+			cmd := createCmd(t, gomock.NewController(t), withKeyManager(&mockkms.KeyManager{
+				GetKeyValue: rotatedKH,
+			}))
+
+			cek := random.GetRandomBytes(32)
+
+			wk, wrapErr := cr.WrapKey(cek, []byte("apu"), []byte("apv"), pub)
+			require.NoError(t, wrapErr)
+
+			req, marshalErr := json.Marshal(UnwrapKeyRequest{WrappedKey: *wk})
+			require.NoError(t, marshalErr)
+
+			wr, marshalErr := json.Marshal(WrappedRequest{
+				KeyStoreID: "key_store_id",
+				KeyID:      "key_id",
+				Request:    req,
+			})
+			require.NoError(t, marshalErr)
+
+			var buf bytes.Buffer
+
+			err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+			require.NoError(t, err)
+
+			var resp UnwrapKeyResponse
+
+			err = json.Unmarshal(buf.Bytes(), &resp)
+			require.NoError(t, err)
+			require.Equal(t, cek, resp.Key)
+		}
+	})
+
+	t.Run("Fail to unwrap a key", func(t *testing.T) {
+		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
+			UnwrapError: errors.New("unwrap error"),
+		}))
+
+		req, err := json.Marshal(UnwrapKeyRequest{
+			WrappedKey:   crypto.RecipientWrappedKey{EncryptedCEK: []byte("0")},
+			SenderPubKey: &crypto.PublicKey{},
+			Tag:          []byte("tag"),
+		})
+		require.NoError(t, err)
+
+		wr, err := json.Marshal(WrappedRequest{
+			KeyStoreID: "key_store_id",
+			KeyID:      "key_id",
+			Request:    req,
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
+		require.EqualError(t, err, "unwrap key: unwrap error")
+	})
+}
+
+func TestCommand_EncryptJWE(t *testing.T) {
+	t.Run("Success with anoncrypt to one recipient", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		recKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.NISTP256ECDHKWType})
+
+		encResp, err := execute[EncryptJWEResponse](cmd.EncryptJWE, "", &EncryptJWERequest{
+			Plaintext:  []byte("test message"),
+			Recipients: []*crypto.PublicKey{ecdhPublicKey(t, cmd, recKeyID)},
+		})
+		require.NoError(t, err)
+
+		var compact string
+
+		require.NoError(t, json.Unmarshal(encResp.JWE, &compact))
+		require.Len(t, strings.Split(compact, "."), 5)
+
+		decResp, err := execute[DecryptJWEResponse](cmd.DecryptJWE, recKeyID, &DecryptJWERequest{JWE: encResp.JWE})
+		require.NoError(t, err)
+		require.Equal(t, []byte("test message"), decResp.Plaintext)
+	})
+
+	t.Run("Success with anoncrypt to multiple recipients", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		recKeyID1 := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.X25519ECDHKWType})
+		recKeyID2 := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.X25519ECDHKWType})
+
+		encResp, err := execute[EncryptJWEResponse](cmd.EncryptJWE, "", &EncryptJWERequest{
+			Plaintext:         []byte("test message"),
+			AAD:               []byte("additional data"),
+			Recipients:        []*crypto.PublicKey{ecdhPublicKey(t, cmd, recKeyID1), ecdhPublicKey(t, cmd, recKeyID2)},
+			ContentEncryption: "XC20P",
+			Serialization:     JWEJSON,
+		})
+		require.NoError(t, err)
+
+		var full struct {
+			Recipients []json.RawMessage `json:"recipients"`
+		}
+
+		require.NoError(t, json.Unmarshal(encResp.JWE, &full))
+		require.Len(t, full.Recipients, 2)
+
+		decResp, err := execute[DecryptJWEResponse](cmd.DecryptJWE, recKeyID2, &DecryptJWERequest{JWE: encResp.JWE})
+		require.NoError(t, err)
+		require.Equal(t, []byte("test message"), decResp.Plaintext)
+	})
+
+	t.Run("Success with authcrypt", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		senderKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.NISTP256ECDHKWType})
+		recKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.NISTP256ECDHKWType})
+
+		encResp, err := execute[EncryptJWEResponse](cmd.EncryptJWE, senderKeyID, &EncryptJWERequest{
+			Plaintext:     []byte("test message"),
+			Recipients:    []*crypto.PublicKey{ecdhPublicKey(t, cmd, recKeyID)},
+			Serialization: JWEJSON,
+		})
+		require.NoError(t, err)
+
+		var full struct {
+			Protected string `json:"protected"`
+		}
+
+		require.NoError(t, json.Unmarshal(encResp.JWE, &full))
+
+		protected, err := base64.RawURLEncoding.DecodeString(full.Protected)
+		require.NoError(t, err)
+		require.Contains(t, string(protected), `"alg":"ECDH-1PU`)
+		require.Contains(t, string(protected), fmt.Sprintf(`"skid":%q`, senderKeyID))
+
+		decResp, err := execute[DecryptJWEResponse](cmd.DecryptJWE, recKeyID, &DecryptJWERequest{
+			JWE:          encResp.JWE,
+			SenderPubKey: ecdhPublicKey(t, cmd, senderKeyID),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []byte("test message"), decResp.Plaintext)
+
+		_, err = execute[DecryptJWEResponse](cmd.DecryptJWE, recKeyID, &DecryptJWERequest{JWE: encResp.JWE})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sender public key is required")
+	})
+
+	t.Run("Fail with invalid recipient key", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[EncryptJWEResponse](cmd.EncryptJWE, "", &EncryptJWERequest{
+			Plaintext: []byte("test message"),
+			Recipients: []*crypto.PublicKey{{
+				Type:  "EC",
+				Curve: "P-256",
+				X:     []byte{1},
+				Y:     []byte{2},
+			}},
+		})
+		require.EqualError(t, err, "bad request: recipient 0: point is not on curve P-256")
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[EncryptJWEResponse](cmd.EncryptJWE, "", &EncryptJWERequest{
+			Plaintext: []byte("test message"),
+		})
+		require.EqualError(t, err, "validate request: validation failed: recipients must be non-empty")
+
+		_, err = execute[EncryptJWEResponse](cmd.EncryptJWE, "", &EncryptJWERequest{
+			Plaintext:       []byte("test message"),
+			RecipientKeyIDs: []string{"key1", "key2"},
+		})
+		require.EqualError(t, err,
+			"validate request: validation failed: compact serialization supports one recipient and no aad")
+
+		_, err = execute[EncryptJWEResponse](cmd.EncryptJWE, "", &EncryptJWERequest{
+			Plaintext:         []byte("test message"),
+			RecipientKeyIDs:   []string{"key1"},
+			ContentEncryption: "A128GCM",
+		})
+		require.EqualError(t, err, `validate request: validation failed: unsupported content encryption "A128GCM"`)
+	})
+}
+
+func TestCommand_DecryptJWE(t *testing.T) {
+	t.Run("Fail with JWE to another recipient", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		recKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.NISTP256ECDHKWType})
+		otherKeyID := createKey(t, cmd, &CreateKeyRequest{KeyType: kms.NISTP256ECDHKWType})
+
+		encResp, err := execute[EncryptJWEResponse](cmd.EncryptJWE, "", &EncryptJWERequest{
+			Plaintext:  []byte("test message"),
+			Recipients: []*crypto.PublicKey{ecdhPublicKey(t, cmd, recKeyID)},
+		})
+		require.NoError(t, err)
+
+		_, err = execute[DecryptJWEResponse](cmd.DecryptJWE, otherKeyID, &DecryptJWERequest{JWE: encResp.JWE})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request: decrypt jwe")
+	})
+
+	t.Run("Fail with invalid JWE", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[DecryptJWEResponse](cmd.DecryptJWE, "key_id", &DecryptJWERequest{JWE: []byte(`"invalid"`)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request: deserialize jwe")
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		cmd := createCmdWithLocalKMS(t)
+
+		_, err := execute[DecryptJWEResponse](cmd.DecryptJWE, "key_id", &DecryptJWERequest{})
+		require.EqualError(t, err, "validate request: validation failed: jwe must be non-empty")
+	})
+}
+
+// signCompactJWS creates a JWS in compact serialization with the given protected headers and payload.
+func signCompactJWS(t *testing.T, cmd *Command, keyID, headers, payload string) string {
+	t.Helper()
+
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(headers)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(payload))
+
+	resp, err := execute[SignResponse](cmd.Sign, keyID, &SignRequest{Message: []byte(signingInput)})
+	require.NoError(t, err)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(resp.Signature)
+}
+
+func createCmd(t *testing.T, ctrl *gomock.Controller, opts ...configOption) *Command {
+	t.Helper()
+
+	metrics := NewMockMetricsProvider(ctrl)
+	metrics.EXPECT().CryptoSignTime(gomock.Any()).AnyTimes()
+	metrics.EXPECT().KeyStoreGetKeyTime(gomock.Any()).AnyTimes()
+	metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyStoreData, err := json.Marshal(struct {
+		ID         string `json:"id"`
+		Controller string `json:"controller"`
+	}{
+		ID:         "key_store_id",
+		Controller: "controller",
+	})
+	require.NoError(t, err)
+
+	p := mockstorage.NewMockStoreProvider()
+	p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: keyStoreData}
+
+	config := &Config{
+		StorageProvider:    p,
+		KeyStorageProvider: p,
+		KMS:                &mockkms.KeyManager{},
+		Crypto:             cr,
+		MetricsProvider:    metrics,
+	}
+
+	for i := range opts {
+		opts[i](config)
+	}
+
+	creator := NewMockKeyStoreCreator(ctrl)
+	creator.EXPECT().Create(gomock.Any(), gomock.Any()).Return(config.KMS, nil).Times(1)
+
+	config.KeyStoreCreator = creator
+
+	cmd, err := New(config)
+	require.NoError(t, err)
+	require.NotNil(t, cmd)
+
+	return cmd
+}
+
+// createCmdWithLocalKMS creates a command with local KMS key stores protected with a key lock.
+func createCmdWithLocalKMS(t *testing.T, opts ...configOption) *Command {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+
+	metrics := NewMockMetricsProvider(ctrl)
+	metrics.EXPECT().CryptoSignTime(gomock.Any()).AnyTimes()
+	metrics.EXPECT().KeyStoreGetKeyTime(gomock.Any()).AnyTimes()
+	metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	lockKH, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	p := mockstorage.NewMockStoreProvider()
+	p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: []byte(`{"id":"key_store_id"}`)}
+
+	creator := NewMockKeyStoreCreator(ctrl)
+	creator.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(keyURI string, provider kms.Provider) (kms.KeyManager, error) {
+			return localkms.New(keyURI, provider)
+		}).AnyTimes()
+
+	config := &Config{
+		StorageProvider:    p,
+		KeyStorageProvider: p,
+		KMS:                &mockkms.KeyManager{GetKeyValue: lockKH},
+		Crypto:             cr,
+		KeyStoreCreator:    creator,
+		MetricsProvider:    metrics,
+	}
+
+	for i := range opts {
+		opts[i](config)
+	}
+
+	cmd, err := New(config)
+	require.NoError(t, err)
+
+	return cmd
+}
+
+type configOption func(c *Config)
+
+func withKeyManagerCache(cache *memKeyManagerCache, ttl time.Duration) configOption {
+	return func(c *Config) {
+		c.KeyManagerCache = cache
+		c.KeyManagerCacheTTL = ttl
+	}
+}
+
+func withJWTLifetime(lifetime, maxLifetime time.Duration) configOption {
+	return func(c *Config) {
+		c.JWTLifetime = lifetime
+		c.JWTMaxLifetime = maxLifetime
+	}
+}
+
+func withMetrics(m *MockMetricsProvider) configOption {
+	return func(c *Config) {
+		c.MetricsProvider = m
+	}
+}
+
+func withStorageProvider(p storage.Provider) configOption {
+	return func(c *Config) {
+		c.StorageProvider = p
+	}
+}
+
+func withKeyManager(km kms.KeyManager) configOption {
+	return func(c *Config) {
+		c.KMS = km
+	}
+}
+
+func withCrypto(cr crypto.Crypto) configOption {
+	return func(c *Config) {
+		c.Crypto = cr
+	}
+}
+
+type cryptoBoxCreator interface {
+	Create(km kms.KeyManager) (CryptoBox, error)
+}
+
+func withKeyExpiryGracePeriod(d time.Duration) configOption {
+	return func(c *Config) {
+		c.KeyExpiryGracePeriod = d
+	}
+}
+
+func withCryptoBoxCreator(creator cryptoBoxCreator) configOption {
+	return func(c *Config) {
+		c.CryptBoxCreator = creator
+	}
+}
+
+type shamirSecretLockCreator interface {
+	Create(secretShares [][]byte) (secretlock.Service, error)
+}
+
+func withShamirSecretLockCreator(creator shamirSecretLockCreator) configOption {
+	return func(c *Config) {
+		c.ShamirSecretLockCreator = creator
+	}
+}
+
+type shamirProvider interface {
+	FetchSecretShare(subject string) ([]byte, error)
+}
+
+func withShamirProvider(provider shamirProvider) configOption {
+	return func(c *Config) {
+		c.ShamirProvider = provider
+	}
+}
+
+func createPrivateKey(t *testing.T, kt kms.KeyType) interface{} {
+	t.Helper()
+
+	switch kt { //nolint:exhaustive
+	case kms.ED25519:
+		_, pk, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		return pk
+	case kms.ECDSAP256TypeDER, kms.ECDSAP256TypeIEEEP1363, kms.NISTP256ECDHKWType:
+		pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		return pk
+	case kms.ECDSAP384TypeDER, kms.ECDSAP384TypeIEEEP1363, kms.NISTP384ECDHKWType:
+		pk, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		return pk
+	case kms.ECDSAP521TypeDER, kms.ECDSAP521TypeIEEEP1363, kms.NISTP521ECDHKWType:
+		pk, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		require.NoError(t, err)
+
+		return pk
+	default:
+		require.Fail(t, "not supported key type")
+
+		return nil
+	}
+}
+
+// createKey creates a key in "key_store_id" key store and returns its ID.
+func createKey(t *testing.T, cmd *Command, r *CreateKeyRequest) string {
+	t.Helper()
+
+	req, err := json.Marshal(r)
+	require.NoError(t, err)
+
+	wr, err := json.Marshal(WrappedRequest{
+		KeyStoreID: "key_store_id",
+		Request:    req,
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, cmd.CreateKey(&buf, bytes.NewBuffer(wr)))
+
+	var resp CreateKeyResponse
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+
+	return resp.KeyURL[strings.LastIndex(resp.KeyURL, "/")+1:]
+}
+
+// jwks returns keys of the JWK Set of "key_store_id" key store.
+func jwks(t *testing.T, cmd *Command) []jwk.JWK {
+	t.Helper()
+
+	wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, cmd.JWKS(&buf, bytes.NewBuffer(wr)))
+
+	var resp struct {
+		Keys []jwk.JWK `json:"keys"`
+	}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+
+	return resp.Keys
+}
+
+// createTransportKey creates a key in a separate local KMS and returns the KMS and the public key of the key.
+func createTransportKey(t *testing.T, kt kms.KeyType) (kms.KeyManager, *crypto.PublicKey) {
+	t.Helper()
+
+	p, err := mockkms.NewProviderForKMS(mem.NewProvider(), &noop.NoLock{})
+	require.NoError(t, err)
+
+	km, err := localkms.New("local-lock://transport", p)
+	require.NoError(t, err)
+
+	kid, b, err := km.CreateAndExportPubKeyBytes(kt)
+	require.NoError(t, err)
+
+	var pub crypto.PublicKey
+
+	require.NoError(t, json.Unmarshal(b, &pub))
+
+	pub.KID = kid
+
+	return km, &pub
+}
+
+// withMemStorage sets in-memory storage with separate stores and "key_store_id" key store.
+func withMemStorage(t *testing.T) configOption {
+	t.Helper()
+
+	p := mem.NewProvider()
+
+	store, err := p.OpenStore("keystores")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key_store_id", []byte(`{"id":"key_store_id"}`)))
+
+	return func(c *Config) {
+		c.StorageProvider = p
+		c.KeyStorageProvider = p
+	}
+}
+
+// backupKeyManager returns server's key manager that locks key stores with an AES key.
+func backupKeyManager(t *testing.T) *mockkms.KeyManager {
+	t.Helper()
+
+	lockKH, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	return &mockkms.KeyManager{GetKeyValue: lockKH}
+}
+
+// withBackupSigningKey sets the backup signing key and trusted backup signers.
+func withBackupSigningKey(key ed25519.PrivateKey, trustedSigners ...string) configOption {
+	return func(c *Config) {
+		c.BackupSigningKey = key
+		c.BackupTrustedSigners = trustedSigners
+	}
+}
+
+// newBackupSigningKey returns a new Ed25519 backup signing key with its did:key.
+func newBackupSigningKey(t *testing.T) (ed25519.PrivateKey, string) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	did, _ := fingerprint.CreateDIDKey(pub)
+
+	return priv, did
+}
+
+// signBackup returns the archive of the backup contents signed with the key.
+func signBackup(t *testing.T, key ed25519.PrivateKey, payload string) *KeyStoreBackup {
+	t.Helper()
+
+	did, _ := fingerprint.CreateDIDKey(key.Public().(ed25519.PublicKey)) //nolint:forcetypeassert
+
+	return &KeyStoreBackup{
+		Payload:   []byte(payload),
+		Signer:    did,
+		Signature: ed25519.Sign(key, []byte(payload)),
+	}
+}
+
+// backupKeyStore creates a backup of "key_store_id" key store.
+func backupKeyStore(t *testing.T, cmd *Command) *KeyStoreBackup {
+	t.Helper()
+
+	wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, cmd.BackupKeyStore(&buf, bytes.NewBuffer(wr)))
+
+	var backup KeyStoreBackup
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &backup))
+
+	return &backup
+}
+
+// deleteKeyStore deletes "key_store_id" key store.
+func deleteKeyStore(t *testing.T, cmd *Command) {
+	t.Helper()
+
+	wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+	require.NoError(t, err)
+
+	require.NoError(t, cmd.DeleteKeyStore(nil, bytes.NewBuffer(wr)))
+}
+
+// withEDVKeyManager sets server's local key manager that creates EDV keys, and a header signer for EDV requests.
+// "key_store_id" key store is locked with the server's main key.
+func withEDVKeyManager(t *testing.T, serverStorage, keyStorage storage.Provider) configOption {
+	t.Helper()
+
+	p, err := mockkms.NewProviderForKMS(serverStorage, &noop.NoLock{})
+	require.NoError(t, err)
+
+	km, err := localkms.New("local-lock://server", p)
+	require.NoError(t, err)
+
+	mainKeyID, _, err := km.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	store, err := serverStorage.OpenStore("keystores")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key_store_id",
+		[]byte(`{"id":"key_store_id","main_key_id":"`+mainKeyID+`","created_at":"2022-09-01T00:00:00Z"}`)))
+
+	signer := NewMockHeaderSigner(gomock.NewController(t))
+	signer.EXPECT().SignHeader(gomock.Any(), gomock.Any()).Return(&http.Header{}, nil).AnyTimes()
+
+	return func(c *Config) {
+		c.StorageProvider = serverStorage
+		c.KeyStorageProvider = keyStorage
+		c.KMS = km
+		c.HeaderSigner = signer
+		c.EDVRecipientKeyType = kms.NISTP256ECDHKWType
+		c.EDVMACKeyType = kms.HMACSHA256Tag256Type
+	}
+}
+
+// mockEDVServer is an EDV server with a single vault that keeps encrypted documents in memory.
+type mockEDVServer struct {
+	*httptest.Server
+	documents map[string][]byte
+}
+
+func newMockEDVServer(t *testing.T) *mockEDVServer {
+	t.Helper()
+
+	s := &mockEDVServer{documents: make(map[string][]byte)}
+
+	const documentsPath = "/encrypted-data-vaults/vault_id/documents"
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		docID := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, documentsPath), "/")
+
+		switch {
+		case r.Method == http.MethodPost && docID == "":
+			var doc struct {
+				ID string `json:"id"`
+			}
+
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(b, &doc))
+
+			s.documents[doc.ID] = b
+
+			w.Header().Set("Location", r.URL.String()+"/"+doc.ID)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPost:
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			s.documents[docID] = b
+		case r.Method == http.MethodGet:
+			b, ok := s.documents[docID]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, err := w.Write(b)
+			require.NoError(t, err)
+		case r.Method == http.MethodDelete:
+			if _, ok := s.documents[docID]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			delete(s.documents, docID)
+		}
+	}))
+
+	return s
+}
+
+// execute calls the command method with the request for the key of "key_store_id" key store and returns its decoded
+// response.
+func execute[T any](method func(io.Writer, io.Reader) error, keyID string, r interface{}) (*T, error) {
+	req, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if err = method(&buf, bytes.NewBuffer(wr)); err != nil {
+		return nil, err
+	}
+
+	var resp T
+
+	if err = json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// getKeyStore returns metadata of "key_store_id" key store.
+func getKeyStore(t *testing.T, cmd *Command) *GetKeyStoreResponse {
+	t.Helper()
+
+	wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, cmd.GetKeyStore(&buf, bytes.NewBuffer(wr)))
+
+	var resp GetKeyStoreResponse
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
+
+	return &resp
+}
+
+// signMessage signs a test message with the key from "key_store_id" key store.
+func signMessage(t *testing.T, cmd *Command, keyID string) {
+	t.Helper()
+
+	req, err := json.Marshal(SignRequest{Message: []byte("test message")})
+	require.NoError(t, err)
+
+	wr, err := json.Marshal(WrappedRequest{KeyStoreID: "key_store_id", KeyID: keyID, Request: req})
+	require.NoError(t, err)
 
-		var resp WrapKeyResponse
+	require.NoError(t, cmd.Sign(&bytes.Buffer{}, bytes.NewBuffer(wr)))
+}
 
-		err = json.Unmarshal(buf.Bytes(), &resp)
-		require.NoError(t, err)
-		require.NotNil(t, resp.RecipientWrappedKey)
-	})
+func batchOperation(t *testing.T, operation, keyID string, r interface{}) BatchOperation {
+	t.Helper()
 
-	t.Run("Fail to wrap a key", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			WrapError: errors.New("wrap error"),
-		}))
+	req, err := json.Marshal(r)
+	require.NoError(t, err)
 
-		req, err := json.Marshal(WrapKeyRequest{
-			CEK:             []byte("cek"),
-			APU:             []byte("apu"),
-			APV:             []byte("apv"),
-			RecipientPubKey: &crypto.PublicKey{},
-			Tag:             []byte("tag"),
-		})
-		require.NoError(t, err)
+	return BatchOperation{Operation: operation, KeyID: keyID, Request: req}
+}
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
-		})
-		require.NoError(t, err)
+// exportKey exports the public key from "key_store_id" key store in the format.
+func exportKey(t *testing.T, cmd *Command, keyID, format string) *ExportKeyResponse {
+	t.Helper()
 
-		var buf bytes.Buffer
+	req, err := json.Marshal(ExportKeyRequest{Format: format})
+	require.NoError(t, err)
 
-		err = cmd.WrapKey(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "wrap key: wrap error")
+	wr, err := json.Marshal(WrappedRequest{
+		KeyStoreID: "key_store_id",
+		KeyID:      keyID,
+		Request:    req,
 	})
-}
+	require.NoError(t, err)
 
-func TestCommand_UnwrapKey(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			UnwrapValue: []byte("key"),
-		}))
+	var buf bytes.Buffer
 
-		req, err := json.Marshal(UnwrapKeyRequest{
-			WrappedKey:   crypto.RecipientWrappedKey{EncryptedCEK: []byte{0}},
-			SenderPubKey: &crypto.PublicKey{},
-			Tag:          []byte("tag"),
-		})
-		require.NoError(t, err)
+	require.NoError(t, cmd.ExportKey(&buf, bytes.NewBuffer(wr)))
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
-		})
-		require.NoError(t, err)
+	var resp ExportKeyResponse
 
-		var buf bytes.Buffer
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
 
-		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
-		require.NoError(t, err)
+	return &resp
+}
 
-		var resp UnwrapKeyResponse
+// importPublicKey imports the public key into "key_store_id" key store and returns the key ID.
+func importPublicKey(t *testing.T, cmd *Command, kt kms.KeyType, key []byte) string {
+	t.Helper()
 
-		err = json.Unmarshal(buf.Bytes(), &resp)
-		require.NoError(t, err)
-		require.Equal(t, []byte("key"), resp.Key)
+	req, err := json.Marshal(ImportKeyRequest{KeyType: kt, Key: key, Public: true})
+	require.NoError(t, err)
+
+	wr, err := json.Marshal(WrappedRequest{
+		KeyStoreID: "key_store_id",
+		Request:    req,
 	})
+	require.NoError(t, err)
 
-	t.Run("Fail to unwrap a key", func(t *testing.T) {
-		cmd := createCmd(t, gomock.NewController(t), withCrypto(&mockcrypto.Crypto{
-			UnwrapError: errors.New("unwrap error"),
-		}))
+	var buf bytes.Buffer
 
-		req, err := json.Marshal(UnwrapKeyRequest{
-			WrappedKey:   crypto.RecipientWrappedKey{EncryptedCEK: []byte("0")},
-			SenderPubKey: &crypto.PublicKey{},
-			Tag:          []byte("tag"),
-		})
-		require.NoError(t, err)
+	require.NoError(t, cmd.ImportKey(&buf, bytes.NewBuffer(wr)))
 
-		wr, err := json.Marshal(WrappedRequest{
-			KeyStoreID: "key_store_id",
-			KeyID:      "key_id",
-			Request:    req,
-		})
-		require.NoError(t, err)
+	var resp ImportKeyResponse
 
-		var buf bytes.Buffer
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &resp))
 
-		err = cmd.UnwrapKey(&buf, bytes.NewBuffer(wr))
-		require.EqualError(t, err, "unwrap key: unwrap error")
-	})
+	return resp.KeyURL[strings.LastIndex(resp.KeyURL, "/")+1:]
 }
 
-func createCmd(t *testing.T, ctrl *gomock.Controller, opts ...configOption) *Command {
+// keyMetaOption sets fields of key metadata put by putKeyMeta.
+type keyMetaOption func(meta map[string]interface{})
+
+// putKeyMeta puts metadata of the ED25519 key into the keys store with storage tags the command sets for it.
+func putKeyMeta(t *testing.T, p *mockstorage.MockStoreProvider, keyStoreID, keyID string, opts ...keyMetaOption) {
 	t.Helper()
 
-	metrics := NewMockMetricsProvider(ctrl)
-	metrics.EXPECT().CryptoSignTime(gomock.Any()).AnyTimes()
-	metrics.EXPECT().KeyStoreGetKeyTime(gomock.Any()).AnyTimes()
-	metrics.EXPECT().KeyStoreResolveTime(gomock.Any()).AnyTimes()
+	meta := map[string]interface{}{
+		"id":           keyID,
+		"key_store_id": keyStoreID,
+		"key_type":     kms.ED25519Type,
+		"created_at":   time.Now(),
+	}
 
-	cr, err := tinkcrypto.New()
-	require.NoError(t, err)
+	for _, opt := range opts {
+		opt(meta)
+	}
 
-	keyStoreData, err := json.Marshal(struct {
-		ID         string `json:"id"`
-		Controller string `json:"controller"`
-	}{
-		ID:         "key_store_id",
-		Controller: "controller",
-	})
+	b, err := json.Marshal(meta)
 	require.NoError(t, err)
 
-	p := mockstorage.NewMockStoreProvider()
-	p.Store.Store["key_store_id"] = mockstorage.DBEntry{Value: keyStoreData}
+	tags := []storage.Tag{{Name: "keyStoreID", Value: keyStoreID}}
 
-	config := &Config{
-		StorageProvider:    p,
-		KeyStorageProvider: p,
-		KMS:                &mockkms.KeyManager{},
-		Crypto:             cr,
-		MetricsProvider:    metrics,
+	if meta["status"] == "pending_deletion" {
+		tags = append(tags, storage.Tag{Name: "pendingDeletion"})
 	}
 
-	for i := range opts {
-		opts[i](config)
+	if _, ok := meta["rotation_policy"]; ok {
+		tags = append(tags, storage.Tag{Name: "rotationPolicy"})
 	}
 
-	creator := NewMockKeyStoreCreator(ctrl)
-	creator.EXPECT().Create(gomock.Any(), gomock.Any()).Return(config.KMS, nil).Times(1)
+	if keyTags, ok := meta["tags"].(map[string]string); ok {
+		for name, value := range keyTags {
+			tags = append(tags,
+				storage.Tag{Name: "tag_" + name, Value: keyStoreID + "=" + value},
+				storage.Tag{Name: "tagname_" + name, Value: keyStoreID},
+			)
+		}
+	}
 
-	config.KeyStoreCreator = creator
+	p.Store.Store[keyStoreID+"_"+keyID] = mockstorage.DBEntry{Value: b, Tags: tags}
+}
 
-	cmd, err := New(config)
-	require.NoError(t, err)
-	require.NotNil(t, cmd)
+func keyCreatedAt(createdAt time.Time) keyMetaOption {
+	return func(meta map[string]interface{}) {
+		meta["created_at"] = createdAt
+	}
+}
 
-	return cmd
+func keyPendingDeletion(deletionTime time.Time) keyMetaOption {
+	return func(meta map[string]interface{}) {
+		meta["status"] = "pending_deletion"
+		meta["deletion_time"] = deletionTime
+	}
 }
 
-type configOption func(c *Config)
+func keyValidity(notBefore, notAfter time.Time) keyMetaOption {
+	return func(meta map[string]interface{}) {
+		meta["not_before"] = notBefore
+		meta["not_after"] = notAfter
+		meta["created_at"] = notBefore
+	}
+}
 
-func withStorageProvider(p storage.Provider) configOption {
-	return func(c *Config) {
-		c.StorageProvider = p
+// keyRotationDue makes the key an AES key rotated every 90 days with the next rotation at the given time.
+func keyRotationDue(nextRotationAt time.Time) keyMetaOption {
+	return func(meta map[string]interface{}) {
+		meta["key_type"] = kms.AES256GCMType
+		meta["rotation_policy"] = map[string]interface{}{"interval_days": 90}
+		meta["next_rotation_at"] = nextRotationAt
+		meta["created_at"] = nextRotationAt.AddDate(0, 0, -90)
 	}
 }
 
-func withKeyManager(km kms.KeyManager) configOption {
-	return func(c *Config) {
-		c.KMS = km
+func keyAttributes(label, description string, tags map[string]string) keyMetaOption {
+	return func(meta map[string]interface{}) {
+		meta["label"] = label
+		meta["description"] = description
+		meta["tags"] = tags
 	}
 }
 
-func withCrypto(cr crypto.Crypto) configOption {
-	return func(c *Config) {
-		c.Crypto = cr
+func putAlias(t *testing.T, p *mockstorage.MockStoreProvider, keyStoreID, name, keyID string) {
+	t.Helper()
+
+	b, err := json.Marshal(map[string]interface{}{
+		"name":         name,
+		"key_store_id": keyStoreID,
+		"key_id":       keyID,
+	})
+	require.NoError(t, err)
+
+	p.Store.Store[keyStoreID+"_"+name] = mockstorage.DBEntry{
+		Value: b,
+		Tags: []storage.Tag{
+			{Name: "keyStoreID", Value: keyStoreID},
+			{Name: "keyID", Value: keyStoreID + "_" + keyID},
+		},
 	}
 }
 
-type cryptoBoxCreator interface {
-	Create(km kms.KeyManager) (CryptoBox, error)
+// failingQueryProvider is a storage provider whose keys store fails queries after failQueryAfter queries, if set.
+type failingQueryProvider struct {
+	storage.Provider
+	failQueryAfter int
+	queries        int
 }
 
-func withCryptoBoxCreator(creator cryptoBoxCreator) configOption {
-	return func(c *Config) {
-		c.CryptBoxCreator = creator
+func (p *failingQueryProvider) OpenStore(name string) (storage.Store, error) {
+	store, err := p.Provider.OpenStore(name)
+	if err != nil || name != "keys" {
+		return store, err
 	}
+
+	return &failingQueryStore{Store: store, provider: p}, nil
 }
 
-type shamirSecretLockCreator interface {
-	Create(secretShares [][]byte) (secretlock.Service, error)
+type failingQueryStore struct {
+	storage.Store
+	provider *failingQueryProvider
 }
 
-func withShamirSecretLockCreator(creator shamirSecretLockCreator) configOption {
-	return func(c *Config) {
-		c.ShamirSecretLockCreator = creator
+func (s *failingQueryStore) Query(expression string, options ...storage.QueryOption) (storage.Iterator, error) {
+	if s.provider.failQueryAfter > 0 {
+		s.provider.queries++
+
+		if s.provider.queries > s.provider.failQueryAfter {
+			return nil, errors.New("query error")
+		}
 	}
+
+	return s.Store.Query(expression, options...)
 }
 
-type shamirProvider interface {
-	FetchSecretShare(subject string) ([]byte, error)
+// storeConfigProvider is a mock storage provider that keeps store configurations.
+type storeConfigProvider struct {
+	*mockstorage.MockStoreProvider
+	configs map[string]storage.StoreConfiguration
 }
 
-func withShamirProvider(provider shamirProvider) configOption {
-	return func(c *Config) {
-		c.ShamirProvider = provider
+func newStoreConfigProvider() *storeConfigProvider {
+	return &storeConfigProvider{
+		MockStoreProvider: mockstorage.NewMockStoreProvider(),
+		configs:           make(map[string]storage.StoreConfiguration),
 	}
 }
 
-func createPrivateKey(t *testing.T, kt kms.KeyType) interface{} {
+func (p *storeConfigProvider) SetStoreConfig(name string, config storage.StoreConfiguration) error {
+	p.configs[name] = config
+
+	return p.MockStoreProvider.SetStoreConfig(name, config)
+}
+
+func (p *storeConfigProvider) GetStoreConfig(name string) (storage.StoreConfiguration, error) {
+	return p.configs[name], nil
+}
+
+// rotateKeyset adds a new primary key to the keyset. A new keyset is created if kh is nil.
+func rotateKeyset(t *testing.T, kh *keyset.Handle, template *tinkpb.KeyTemplate) *keyset.Handle {
 	t.Helper()
 
-	switch kt { //nolint:exhaustive
-	case kms.ED25519:
-		_, pk, err := ed25519.GenerateKey(rand.Reader)
-		require.NoError(t, err)
+	if kh == nil {
+		var err error
 
-		return pk
-	case kms.ECDSAP256TypeDER, kms.ECDSAP256TypeIEEEP1363:
-		pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		kh, err = keyset.NewHandle(template)
 		require.NoError(t, err)
+	}
 
-		return pk
-	case kms.ECDSAP384TypeDER, kms.ECDSAP384TypeIEEEP1363:
-		pk, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
-		require.NoError(t, err)
+	m := keyset.NewManagerFromHandle(kh)
 
-		return pk
-	case kms.ECDSAP521TypeDER, kms.ECDSAP521TypeIEEEP1363:
-		pk, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
-		require.NoError(t, err)
+	require.NoError(t, m.Rotate(template))
 
-		return pk
-	default:
-		require.Fail(t, "not supported key type")
+	rotated, err := m.Handle()
+	require.NoError(t, err)
 
-		return nil
-	}
+	return rotated
 }
 
 func createRecipientPubKey(t *testing.T) []byte {
@@ -2167,3 +7886,155 @@ func createRecipientPubKey(t *testing.T) []byte {
 
 	return buf.Bytes()
 }
+
+// memKeyManagerCache is an in-memory key manager cache that ignores cost and TTL.
+type memKeyManagerCache struct {
+	mu    sync.Mutex
+	items map[interface{}]interface{}
+}
+
+func newMemKeyManagerCache() *memKeyManagerCache {
+	return &memKeyManagerCache{items: make(map[interface{}]interface{})}
+}
+
+func (c *memKeyManagerCache) Get(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.items[key]
+
+	return v, ok
+}
+
+func (c *memKeyManagerCache) SetWithTTL(key, value interface{}, _ int64, _ time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = value
+
+	return true
+}
+
+func (c *memKeyManagerCache) Del(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+func (c *memKeyManagerCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// signClaims returns a compact JWS of the claims created with the key of "key_store_id" key store.
+func signClaims(t *testing.T, cmd *Command, keyID string, claims map[string]interface{}) string {
+	t.Helper()
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	resp, err := execute[SignJWSResponse](cmd.SignJWS, keyID, &SignJWSRequest{Payload: payload})
+	require.NoError(t, err)
+
+	var jwt string
+
+	require.NoError(t, json.Unmarshal(resp.JWS, &jwt))
+
+	return jwt
+}
+
+// ecdhPublicKey returns the public key of ECDH key of "key_store_id" key store.
+func ecdhPublicKey(t *testing.T, cmd *Command, keyID string) *crypto.PublicKey {
+	t.Helper()
+
+	var pub crypto.PublicKey
+
+	require.NoError(t, json.Unmarshal(exportKey(t, cmd, keyID, "").PublicKey, &pub))
+
+	pub.KID = keyID
+
+	return &pub
+}
+
+const (
+	testCredential = `{
+		"@context": ["https://www.w3.org/2018/credentials/v1"],
+		"id": "http://example.edu/credentials/1872",
+		"type": ["VerifiableCredential"],
+		"issuer": "did:example:issuer",
+		"issuanceDate": "2010-01-01T19:23:24Z",
+		"credentialSubject": {"id": "did:example:subject"}
+	}`
+
+	testPresentation = `{
+		"@context": ["https://www.w3.org/2018/credentials/v1"],
+		"type": ["VerifiablePresentation"],
+		"holder": "did:example:holder"
+	}`
+)
+
+// credentialWithContext returns the test credential with an additional JSON-LD context of the signature suite.
+func credentialWithContext(ctx string) []byte {
+	return []byte(strings.Replace(testCredential, `"https://www.w3.org/2018/credentials/v1"`,
+		fmt.Sprintf(`"https://www.w3.org/2018/credentials/v1", %q`, ctx), 1))
+}
+
+// parseCredential parses the credential and verifies its proofs with the public key.
+func parseCredential(t *testing.T, doc []byte, pub *verifier.PublicKey) *verifiable.Credential {
+	t.Helper()
+
+	vc, err := verifiable.ParseCredential(doc,
+		verifiable.WithPublicKeyFetcher(func(string, string) (*verifier.PublicKey, error) { return pub, nil }),
+		verifiable.WithJSONLDDocumentLoader(testDocumentLoader(t)),
+	)
+	require.NoError(t, err)
+
+	return vc
+}
+
+func withDocumentLoader(t *testing.T) configOption {
+	t.Helper()
+
+	loader := testDocumentLoader(t)
+
+	return func(c *Config) {
+		c.DocumentLoader = loader
+	}
+}
+
+func testDocumentLoader(t *testing.T) *ld.DocumentLoader {
+	t.Helper()
+
+	loader, err := ld.NewDocumentLoader(&mockLDStoreProvider{
+		ContextStore:        mockldstore.NewMockContextStore(),
+		RemoteProviderStore: mockldstore.NewMockRemoteProviderStore(),
+	})
+	require.NoError(t, err)
+
+	return loader
+}
+
+type mockLDStoreProvider struct {
+	ContextStore        ldstore.ContextStore
+	RemoteProviderStore ldstore.RemoteProviderStore
+}
+
+func (p *mockLDStoreProvider) JSONLDContextStore() ldstore.ContextStore {
+	return p.ContextStore
+}
+
+func (p *mockLDStoreProvider) JSONLDRemoteProviderStore() ldstore.RemoteProviderStore {
+	return p.RemoteProviderStore
+}
+
+type invalidatingKMS struct {
+	mockkms.KeyManager
+	invalidated []string
+}
+
+func (k *invalidatingKMS) Invalidate(keyID string) {
+	k.invalidated = append(k.invalidated, keyID)
+}
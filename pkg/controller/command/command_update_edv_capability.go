@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// UpdateEDVCapability replaces the capability used for accessing EDV vault of the key store. EDV storage provider is
+// created from key store metadata on each request, so the new capability is used starting from the next request.
+func (c *Command) UpdateEDVCapability(_ io.Writer, r io.Reader) error {
+	var req UpdateEDVCapabilityRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	meta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	if meta.EDV.VaultURL == "" {
+		return fmt.Errorf("%w: key store doesn't use edv", errors.ErrBadRequest)
+	}
+
+	capability, err := zcapld.ParseCapability(req.Capability)
+	if err != nil {
+		return fmt.Errorf("%w: parse capability: %s", errors.ErrValidation, err)
+	}
+
+	if _, vaultID := splitVaultURL(meta.EDV.VaultURL); capability.InvocationTarget.ID != vaultID {
+		return fmt.Errorf("%w: capability invocation target %q doesn't match vault %q", errors.ErrValidation,
+			capability.InvocationTarget.ID, vaultID)
+	}
+
+	meta.EDV.Capability = req.Capability
+
+	if err = c.save(meta); err != nil {
+		return fmt.Errorf("save key store metadata: %w", err)
+	}
+
+	return nil
+}
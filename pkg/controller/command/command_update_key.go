@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+const (
+	keyTagPrefix            = "tag_"     // prefix for storage tag names of user's key tags with key store ID and value
+	keyTagNamePrefix        = "tagname_" // prefix for storage tag names of user's key tags with key store ID only
+	tagReservedChars        = ":&"       // characters with special meaning in storage query expressions
+	maxKeyLabelLength       = 256
+	maxKeyDescriptionLength = 1024
+	maxKeyTags              = 50
+)
+
+// UpdateKey updates label, description, tags, rotation policy and publishing in the JWK Set of a key.
+func (c *Command) UpdateKey(_ io.Writer, r io.Reader) error {
+	var req UpdateKeyRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = c.resolveKeyID(wr); err != nil {
+		return err
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	if err = c.checkRotationPolicy(req.RotationPolicy); err != nil {
+		return err
+	}
+
+	meta, err := c.findKeyMeta(wr.KeyStoreID, wr.KeyID)
+	if err != nil {
+		return err
+	}
+
+	if req.Label != nil {
+		meta.Label = *req.Label
+	}
+
+	if req.Description != nil {
+		meta.Description = *req.Description
+	}
+
+	if req.Tags != nil {
+		meta.Tags = req.Tags
+	}
+
+	if req.RotationPolicy != nil {
+		if meta.PublicKey != nil && req.RotationPolicy.IntervalDays > 0 {
+			return fmt.Errorf("%w: rotation policy can't be set for public key", errors.ErrValidation)
+		}
+
+		from := meta.CreatedAt
+
+		if meta.LastRotatedAt != nil {
+			from = *meta.LastRotatedAt
+		}
+
+		meta.setRotationPolicy(req.RotationPolicy, from)
+	}
+
+	if req.Publish != nil {
+		if err = c.updatePublished(wr, meta, *req.Publish); err != nil {
+			return err
+		}
+	}
+
+	if err = c.saveKeyMeta(meta); err != nil {
+		return fmt.Errorf("save key metadata: %w", err)
+	}
+
+	return nil
+}
+
+// updatePublished publishes or unpublishes the key in the JWK Set of the key store. Key store is opened to export the
+// public key only when the key is published.
+func (c *Command) updatePublished(wr *WrappedRequest, meta *keyMeta, publish bool) error {
+	if !publish {
+		meta.unpublish()
+
+		return nil
+	}
+
+	if err := validatePublish(meta.KeyType); err != nil {
+		return err
+	}
+
+	ks, err := c.resolveKeyStore(wr.KeyStoreID, wr.User, wr.SecretShare)
+	if err != nil {
+		return fmt.Errorf("resolve key store: %w", err)
+	}
+
+	if err = publishKey(ks, meta); err != nil {
+		return fmt.Errorf("publish key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/trustbloc/kms/pkg/controller/errors"
+)
+
+// UpdateKeyStore updates controller of the key store. When the controller is changed and ZCAPs are enabled, the root
+// capability of the previous controller is revoked with its delegations and a new root capability is issued to the new
+// controller and returned in the response. Only the root capability can change the controller. EDV vault URL can't be
+// changed as the keys aren't moved to the new vault; key store migration copies the keys and switches the vault instead.
+func (c *Command) UpdateKeyStore(w io.Writer, r io.Reader) error {
+	var req UpdateKeyStoreRequest
+
+	wr, err := unwrapRequest(&req, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if err = req.Validate(); err != nil {
+		return fmt.Errorf("validate request: %w", err)
+	}
+
+	meta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	if req.EDV != nil {
+		if meta.EDV.VaultURL == "" {
+			return fmt.Errorf("%w: key store doesn't use edv", errors.ErrBadRequest)
+		}
+
+		if req.EDV.VaultURL != meta.EDV.VaultURL {
+			return fmt.Errorf("%w: edv vault url can't be updated, migrate the key store to the new vault instead",
+				errors.ErrBadRequest)
+		}
+	}
+
+	var rootCapability []byte
+
+	if req.Controller != "" && req.Controller != meta.Controller {
+		if wr.Capability != nil && wr.Capability.Delegated {
+			return fmt.Errorf("%w: controller can be changed with the root capability only", errors.ErrForbidden)
+		}
+
+		meta.Controller = req.Controller
+
+		if c.enableZCAPs {
+			resource := c.baseKeyStoreURL + "/" + meta.ID
+
+			if err = c.zcap.Revoke(resource); err != nil {
+				return fmt.Errorf("revoke root capability: %w", err)
+			}
+
+			rootCapability, err = c.newCompressedZCAP(context.Background(), resource, meta.Controller)
+			if err != nil {
+				return fmt.Errorf("new compressed zcap: %w", err)
+			}
+		}
+	}
+
+	if err = c.save(meta); err != nil {
+		return fmt.Errorf("save key store metadata: %w", err)
+	}
+
+	c.invalidateKeyManager(wr.KeyStoreID, wr.User, wr.SecretShare)
+
+	return json.NewEncoder(w).Encode(UpdateKeyStoreResponse{Capability: rootCapability})
+}
+
+// ReissueRootCapability issues a new root capability of the key store to its controller. Root capabilities allow the
+// actions supported by the server when they were issued, so clients holding an older root capability get a new one to
+// call endpoints added since. The route is authorized with the updateEDVCapability action every root capability has;
+// delegated capabilities are refused, otherwise they could be traded for the root one.
+func (c *Command) ReissueRootCapability(w io.Writer, r io.Reader) error {
+	wr, err := unwrapRequest(nil, r)
+	if err != nil {
+		return fmt.Errorf("unwrap request: %w", err)
+	}
+
+	if !c.enableZCAPs {
+		return fmt.Errorf("%w: zcaps are not enabled", errors.ErrBadRequest)
+	}
+
+	if wr.Capability != nil && wr.Capability.Delegated {
+		return fmt.Errorf("%w: root capability can be reissued with the root capability only", errors.ErrForbidden)
+	}
+
+	meta, err := c.findKeyStoreMeta(wr.KeyStoreID)
+	if err != nil {
+		return err
+	}
+
+	rootCapability, err := c.newCompressedZCAP(context.Background(), c.baseKeyStoreURL+"/"+meta.ID, meta.Controller)
+	if err != nil {
+		return fmt.Errorf("new compressed zcap: %w", err)
+	}
+
+	return json.NewEncoder(w).Encode(ReissueRootCapabilityResponse{Capability: rootCapability})
+}
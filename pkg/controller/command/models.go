@@ -7,9 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package command
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose/jwk"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 
 	"github.com/trustbloc/kms/pkg/controller/errors"
@@ -17,11 +21,18 @@ import (
 
 // WrappedRequest is a command request with a wrapped original request from user.
 type WrappedRequest struct {
-	KeyStoreID  string `json:"key_store_id"`
-	KeyID       string `json:"key_id"`
-	User        string `json:"user"`
-	SecretShare []byte `json:"secret_share"`
-	Request     []byte `json:"request"`
+	KeyStoreID  string             `json:"key_store_id"`
+	KeyID       string             `json:"key_id"`
+	User        string             `json:"user"`
+	SecretShare []byte             `json:"secret_share"`
+	Capability  *InvokedCapability `json:"capability,omitempty"` // set if the request is authorized with ZCAP
+	Request     []byte             `json:"request"`
+}
+
+// InvokedCapability describes the ZCAP the request is authorized with.
+type InvokedCapability struct {
+	Delegated      bool     `json:"delegated"`                 // capability is delegated from the root capability
+	AllowedActions []string `json:"allowed_actions,omitempty"` // empty means all actions are allowed
 }
 
 // CreateDIDResponse is a response for CreateDID request.
@@ -56,9 +67,184 @@ type CreateKeyStoreResponse struct {
 	Capability  []byte `json:"capability,omitempty"`
 }
 
-// CreateKeyRequest is a request to create a key.
+// GetKeyStoreResponse is a response for GetKeyStore request.
+type GetKeyStoreResponse struct {
+	ID         string    `json:"id"`
+	Controller string    `json:"controller"`
+	MainKeyID  string    `json:"main_key_id,omitempty"`
+	EDV        *EDVInfo  `json:"edv,omitempty"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// EDVInfo represents non-secret parameters of the data vault on EDV used by the key store.
+type EDVInfo struct {
+	VaultURL       string `json:"vault_url"`
+	RecipientKeyID string `json:"recipient_key_id"`
+	MACKeyID       string `json:"mac_key_id"`
+}
+
+// UpdateKeyStoreRequest is a request to update key store metadata.
+type UpdateKeyStoreRequest struct {
+	Controller string            `json:"controller,omitempty"`
+	EDV        *UpdateEDVOptions `json:"edv,omitempty"`
+}
+
+// UpdateEDVOptions represents options for updating data vault parameters.
+type UpdateEDVOptions struct {
+	VaultURL string `json:"vault_url"`
+}
+
+// Validate validates UpdateKeyStore request.
+func (r *UpdateKeyStoreRequest) Validate() error {
+	if r.Controller == "" && r.EDV == nil {
+		return fmt.Errorf("%w: controller or edv must be set", errors.ErrValidation)
+	}
+
+	if r.EDV != nil && r.EDV.VaultURL == "" {
+		return fmt.Errorf("%w: edv vault url must be non-empty", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// UpdateKeyStoreResponse is a response for UpdateKeyStore request.
+type UpdateKeyStoreResponse struct {
+	Capability []byte `json:"capability,omitempty"`
+}
+
+// ReissueRootCapabilityResponse is a response for ReissueRootCapability request.
+type ReissueRootCapabilityResponse struct {
+	Capability []byte `json:"capability"`
+}
+
+// UpdateEDVCapabilityRequest is a request to update the capability for accessing EDV vault.
+type UpdateEDVCapabilityRequest struct {
+	Capability []byte `json:"capability"`
+}
+
+// Validate validates UpdateEDVCapability request.
+func (r *UpdateEDVCapabilityRequest) Validate() error {
+	if len(r.Capability) == 0 {
+		return fmt.Errorf("%w: capability must be non-empty", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// KeyStoreBackup is a signed archive of a key store returned by BackupKeyStore.
+type KeyStoreBackup struct {
+	Payload   []byte `json:"payload"`   // versioned backup contents
+	Signer    string `json:"signer"`    // did:key of the backup signing key the payload is signed with
+	Signature []byte `json:"signature"` // signature on the payload
+}
+
+// RestoreKeyStoreRequest is a request to restore a key store from a backup.
+type RestoreKeyStoreRequest struct {
+	Backup *KeyStoreBackup `json:"backup"`
+}
+
+// Validate validates RestoreKeyStore request.
+func (r *RestoreKeyStoreRequest) Validate() error {
+	if r.Backup == nil || len(r.Backup.Payload) == 0 {
+		return fmt.Errorf("%w: backup must be set", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// RestoreKeyStoreResponse is a response for RestoreKeyStore request.
+type RestoreKeyStoreResponse struct {
+	KeyStoreURL string `json:"key_store_url"`
+	Capability  []byte `json:"capability,omitempty"`
+}
+
+// MigrateKeyStoreRequest is a request to move user's keysets of the key store to another storage backend. Keysets are
+// moved to the data vault on EDV if EDV is set, otherwise to the server's local storage. KeyIDs list keys created
+// before key metadata was introduced: keysets can't be enumerated in the backend, so keys without metadata are migrated
+// only if their metadata is backfilled first.
+type MigrateKeyStoreRequest struct {
+	EDV    *EDVOptions `json:"edv,omitempty"`
+	KeyIDs []string    `json:"key_ids,omitempty"`
+}
+
+// Validate validates MigrateKeyStore request.
+func (r *MigrateKeyStoreRequest) Validate() error {
+	if r.EDV != nil && r.EDV.VaultURL == "" {
+		return fmt.Errorf("%w: edv vault url must be non-empty", errors.ErrValidation)
+	}
+
+	for _, kid := range r.KeyIDs {
+		if kid == "" {
+			return fmt.Errorf("%w: key id must be non-empty", errors.ErrValidation)
+		}
+	}
+
+	return nil
+}
+
+// MigrateKeyStoreResponse is a response for MigrateKeyStore request.
+type MigrateKeyStoreResponse struct {
+	MigratedKeys int `json:"migrated_keys"`
+}
+
+// CreateKeyRequest is a request to create a key. AllowedOperations narrow operations supported by the key type.
+// NotBefore and NotAfter limit the time window in which the key can be used.
 type CreateKeyRequest struct {
-	KeyType kms.KeyType `json:"key_type"`
+	KeyType           kms.KeyType       `json:"key_type"`
+	Label             string            `json:"label,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	AllowedOperations []string          `json:"allowed_operations,omitempty"`
+	NotBefore         *time.Time        `json:"not_before,omitempty"`
+	NotAfter          *time.Time        `json:"not_after,omitempty"`
+	RotationPolicy    *RotationPolicy   `json:"rotation_policy,omitempty"`
+	Publish           bool              `json:"publish,omitempty"`
+	Exportable        bool              `json:"exportable,omitempty"`
+}
+
+// Validate validates CreateKey request.
+func (r *CreateKeyRequest) Validate() error {
+	if err := validateKeyAttributes(r.Label, r.Description, r.Tags); err != nil {
+		return err
+	}
+
+	if err := validateAllowedOperations(r.KeyType, r.AllowedOperations); err != nil {
+		return err
+	}
+
+	if err := validateKeyValidity(r.NotBefore, r.NotAfter); err != nil {
+		return err
+	}
+
+	if r.Publish {
+		if err := validatePublish(r.KeyType); err != nil {
+			return err
+		}
+	}
+
+	return r.RotationPolicy.Validate()
+}
+
+// RotationPolicy is a policy of scheduled key rotation. The key is rotated every IntervalDays days to a key of KeyType
+// (current key type if empty). Zero interval disables scheduled rotation.
+type RotationPolicy struct {
+	IntervalDays int         `json:"interval_days"`
+	KeyType      kms.KeyType `json:"key_type,omitempty"`
+}
+
+// Validate validates rotation policy.
+func (p *RotationPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if p.IntervalDays < 0 || p.IntervalDays > maxRotationIntervalDays {
+		return fmt.Errorf("%w: rotation interval must be between 0 and %d days", errors.ErrValidation,
+			maxRotationIntervalDays)
+	}
+
+	return nil
 }
 
 // CreateKeyResponse is a response for CreateKey request.
@@ -69,9 +255,47 @@ type CreateKeyResponse struct {
 
 // ImportKeyRequest is a request to import a key.
 type ImportKeyRequest struct {
-	Key     []byte      `json:"key"`
-	KeyType kms.KeyType `json:"key_type"`
-	KeyID   string      `json:"key_id,omitempty"`
+	Key               []byte            `json:"key"`
+	KeyType           kms.KeyType       `json:"key_type"`
+	KeyID             string            `json:"key_id,omitempty"`
+	Label             string            `json:"label,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	AllowedOperations []string          `json:"allowed_operations,omitempty"`
+	NotBefore         *time.Time        `json:"not_before,omitempty"`
+	NotAfter          *time.Time        `json:"not_after,omitempty"`
+	RotationPolicy    *RotationPolicy   `json:"rotation_policy,omitempty"`
+	Public            bool              `json:"public,omitempty"`
+	Publish           bool              `json:"publish,omitempty"`
+}
+
+// Validate validates ImportKey request.
+func (r *ImportKeyRequest) Validate() error {
+	if err := validateKeyAttributes(r.Label, r.Description, r.Tags); err != nil {
+		return err
+	}
+
+	if r.Public {
+		if err := validatePublicKeyImport(r.KeyType, r.AllowedOperations, r.RotationPolicy); err != nil {
+			return err
+		}
+	}
+
+	if err := validateAllowedOperations(r.KeyType, r.AllowedOperations); err != nil {
+		return err
+	}
+
+	if err := validateKeyValidity(r.NotBefore, r.NotAfter); err != nil {
+		return err
+	}
+
+	if r.Publish {
+		if err := validatePublish(r.KeyType); err != nil {
+			return err
+		}
+	}
+
+	return r.RotationPolicy.Validate()
 }
 
 // ImportKeyResponse is a response for ImportKey request.
@@ -79,6 +303,78 @@ type ImportKeyResponse struct {
 	KeyURL string `json:"key_url"`
 }
 
+// UpdateKeyRequest is a request to update label, description and tags of a key. Fields that are not set are left
+// unchanged, tags are replaced as a whole.
+type UpdateKeyRequest struct {
+	Label          *string           `json:"label,omitempty"`
+	Description    *string           `json:"description,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	RotationPolicy *RotationPolicy   `json:"rotation_policy,omitempty"`
+	Publish        *bool             `json:"publish,omitempty"`
+}
+
+// Validate validates UpdateKey request.
+func (r *UpdateKeyRequest) Validate() error {
+	if r.Label == nil && r.Description == nil && r.Tags == nil && r.RotationPolicy == nil && r.Publish == nil {
+		return fmt.Errorf("%w: label, description, tags, rotation policy or publish must be set", errors.ErrValidation)
+	}
+
+	if err := r.RotationPolicy.Validate(); err != nil {
+		return err
+	}
+
+	var label, description string
+
+	if r.Label != nil {
+		label = *r.Label
+	}
+
+	if r.Description != nil {
+		description = *r.Description
+	}
+
+	return validateKeyAttributes(label, description, r.Tags)
+}
+
+// SetAliasRequest is a request to create or update an alias of a key.
+type SetAliasRequest struct {
+	Name  string `json:"name"`
+	KeyID string `json:"key_id"`
+}
+
+// Validate validates SetAlias request.
+func (r *SetAliasRequest) Validate() error {
+	if r.Name == "" || len(r.Name) > maxAliasNameLength || !aliasNameRegexp.MatchString(r.Name) {
+		return fmt.Errorf("%w: alias name must be 1 to %d characters long and contain only %s", errors.ErrValidation,
+			maxAliasNameLength, aliasNameCharacters)
+	}
+
+	if r.KeyID == "" {
+		return fmt.Errorf("%w: key id must be non-empty", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// AliasRequest is a request to get or delete an alias.
+type AliasRequest struct {
+	Name string `json:"name"`
+}
+
+// AliasInfo contains information about an alias of a key.
+type AliasInfo struct {
+	Name      string    `json:"name"`
+	KeyID     string    `json:"key_id"`
+	KeyURL    string    `json:"key_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListAliasesResponse is a response for ListAliases request.
+type ListAliasesResponse struct {
+	Aliases []AliasInfo `json:"aliases"`
+}
+
 // RotateKeyRequest is a request to rotate a key.
 type RotateKeyRequest struct {
 	KeyType kms.KeyType `json:"key_type"`
@@ -89,10 +385,204 @@ type RotateKeyResponse struct {
 	KeyURL string `json:"key_url"`
 }
 
+// ExportKeyRequest is a request to export a public key.
+type ExportKeyRequest struct {
+	Format string `json:"format,omitempty"`
+}
+
+// Validate validates ExportKey request.
+func (r *ExportKeyRequest) Validate() error {
+	if r.Format != "" && !containsString(exportFormats, r.Format) {
+		return fmt.Errorf("%w: format must be one of %s", errors.ErrValidation, strings.Join(exportFormats, ", "))
+	}
+
+	return nil
+}
+
 // ExportKeyResponse is a response for ExportKey request.
 type ExportKeyResponse struct {
-	PublicKey []byte `json:"public_key"`
-	KeyType   string `json:"key_type"`
+	PublicKey         []byte            `json:"public_key"`
+	KeyType           string            `json:"key_type"`
+	Format            string            `json:"format,omitempty"`
+	JWK               *jwk.JWK          `json:"jwk,omitempty"`
+	PEM               string            `json:"pem,omitempty"`
+	Multibase         string            `json:"multibase,omitempty"`
+	DIDKey            string            `json:"did_key,omitempty"`
+	Label             string            `json:"label,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	AllowedOperations []string          `json:"allowed_operations,omitempty"`
+	NotBefore         *time.Time        `json:"not_before,omitempty"`
+	NotAfter          *time.Time        `json:"not_after,omitempty"`
+	RotationPolicy    *RotationPolicy   `json:"rotation_policy,omitempty"`
+	LastRotatedAt     *time.Time        `json:"last_rotated_at,omitempty"`
+	NextRotationAt    *time.Time        `json:"next_rotation_at,omitempty"`
+	Published         bool              `json:"published,omitempty"`
+	Exportable        bool              `json:"exportable,omitempty"`
+}
+
+// ExportPrivateKeyRequest is a request to export the private keyset of a key encrypted to a transport key.
+type ExportPrivateKeyRequest struct {
+	TransportKey *crypto.PublicKey `json:"transport_key"`
+}
+
+// Validate validates ExportPrivateKey request.
+func (r *ExportPrivateKeyRequest) Validate() error {
+	if r.TransportKey == nil {
+		return fmt.Errorf("%w: transport key must be set", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// ExportPrivateKeyResponse is a response for ExportPrivateKey request.
+type ExportPrivateKeyResponse struct {
+	JWE string `json:"jwe"` // compact serialized JWE with the private keyset in JSON
+}
+
+// JWKSResponse is a JWK Set of public keys published in the key store.
+type JWKSResponse struct {
+	Keys []json.RawMessage `json:"keys"`
+}
+
+// ListKeysRequest is a request to list keys in the key store.
+type ListKeysRequest struct {
+	Limit  int    `json:"limit,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	Tag    string `json:"tag,omitempty"` // "name" or "name=value"
+}
+
+// Validate validates ListKeys request.
+func (r *ListKeysRequest) Validate() error {
+	if r.Limit < 0 || r.Limit > maxListKeysLimit {
+		return fmt.Errorf("%w: limit must be between 0 and %d", errors.ErrValidation, maxListKeysLimit)
+	}
+
+	if r.Offset < 0 {
+		return fmt.Errorf("%w: offset must be non-negative", errors.ErrValidation)
+	}
+
+	if r.Tag != "" {
+		name, value, _ := strings.Cut(r.Tag, "=")
+
+		if err := validateTag(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListKeysResponse is a response for ListKeys request.
+type ListKeysResponse struct {
+	Keys  []KeyInfo `json:"keys"`
+	Total int       `json:"total"`
+}
+
+// KeyInfo contains information about a key in the key store.
+type KeyInfo struct {
+	KeyID             string            `json:"key_id"`
+	KeyURL            string            `json:"key_url"`
+	KeyType           kms.KeyType       `json:"key_type"`
+	Status            string            `json:"status"`
+	DeletionTime      *time.Time        `json:"deletion_time,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	PublicKey         []byte            `json:"public_key,omitempty"`
+	Label             string            `json:"label,omitempty"`
+	Description       string            `json:"description,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	AllowedOperations []string          `json:"allowed_operations,omitempty"`
+	NotBefore         *time.Time        `json:"not_before,omitempty"`
+	NotAfter          *time.Time        `json:"not_after,omitempty"`
+	RotationPolicy    *RotationPolicy   `json:"rotation_policy,omitempty"`
+	LastRotatedAt     *time.Time        `json:"last_rotated_at,omitempty"`
+	NextRotationAt    *time.Time        `json:"next_rotation_at,omitempty"`
+	Published         bool              `json:"published,omitempty"`
+	Exportable        bool              `json:"exportable,omitempty"`
+}
+
+// ListKeyVersionsResponse is a response for ListKeyVersions request.
+type ListKeyVersionsResponse struct {
+	Versions []KeyVersionInfo `json:"versions"`
+}
+
+// KeyVersionInfo contains information about a version of a key.
+type KeyVersionInfo struct {
+	Version   int        `json:"version"`
+	KeyID     string     `json:"key_id,omitempty"`
+	Status    string     `json:"status"`
+	Primary   bool       `json:"primary"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// DeleteKeyRequest is a request to delete a key.
+type DeleteKeyRequest struct {
+	PendingWindowDays int `json:"pending_window_days,omitempty"`
+}
+
+// Validate validates DeleteKey request.
+func (r *DeleteKeyRequest) Validate() error {
+	if r.PendingWindowDays < 0 || r.PendingWindowDays > maxPendingWindowDays {
+		return fmt.Errorf("%w: pending window must be between 0 and %d days", errors.ErrValidation,
+			maxPendingWindowDays)
+	}
+
+	return nil
+}
+
+// DeleteKeyResponse is a response for DeleteKey request.
+type DeleteKeyResponse struct {
+	Status       string     `json:"status"`
+	DeletionTime *time.Time `json:"deletion_time,omitempty"`
+}
+
+// BatchRequest is a request to execute crypto operations with keys of one key store.
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+	Parallel   bool             `json:"parallel,omitempty"` // execute operations concurrently
+}
+
+// BatchOperation is a crypto operation in the batch. Operation is one of sign, verify, encrypt, decrypt, computeMAC
+// or signMulti. Request is the operation's request, e.g. SignRequest for sign. KeyID may be an alias.
+type BatchOperation struct {
+	Operation string          `json:"operation"`
+	KeyID     string          `json:"key_id"`
+	Request   json.RawMessage `json:"request"`
+}
+
+// Validate validates Batch request.
+func (r *BatchRequest) Validate() error {
+	if len(r.Operations) == 0 {
+		return fmt.Errorf("%w: operations must be non-empty", errors.ErrValidation)
+	}
+
+	if len(r.Operations) > maxBatchOperations {
+		return fmt.Errorf("%w: too many operations, max %d", errors.ErrValidation, maxBatchOperations)
+	}
+
+	for i, op := range r.Operations {
+		if _, ok := batchOperations[op.Operation]; !ok {
+			return fmt.Errorf("%w: operation %d: unsupported operation %q", errors.ErrValidation, i, op.Operation)
+		}
+
+		if op.KeyID == "" {
+			return fmt.Errorf("%w: operation %d: key id must be non-empty", errors.ErrValidation, i)
+		}
+	}
+
+	return nil
+}
+
+// BatchResponse is a response for Batch request. Results are in the order of operations in the request.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult is a result of the batch operation. Result is the operation's response, e.g. SignResponse for sign, and
+// is empty for verify operations. Error is set if the operation failed.
+type BatchResult struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
 }
 
 // SignRequest is a request to sign a message.
@@ -111,6 +601,145 @@ type VerifyRequest struct {
 	Message   []byte `json:"message"`
 }
 
+// SignJWSRequest is a request to create a JWS of the payload. Headers are additional protected headers; alg is set
+// from the key type and kid defaults to the key ID. Serialization is compact (default) or json.
+type SignJWSRequest struct {
+	Payload       []byte                 `json:"payload"`
+	Headers       map[string]interface{} `json:"headers,omitempty"`
+	Serialization string                 `json:"serialization,omitempty"`
+}
+
+// Validate validates SignJWS request.
+func (r *SignJWSRequest) Validate() error {
+	if r.Serialization != "" && r.Serialization != JWSCompact && r.Serialization != JWSJSON {
+		return fmt.Errorf("%w: unsupported serialization %q", errors.ErrValidation, r.Serialization)
+	}
+
+	if _, ok := r.Headers[jwsHeaderAlgorithm]; ok {
+		return fmt.Errorf("%w: alg header is set from the key type", errors.ErrValidation)
+	}
+
+	if b64, ok := r.Headers[jwsHeaderB64]; ok && b64 != true {
+		return fmt.Errorf("%w: unencoded payload is not supported", errors.ErrValidation)
+	}
+
+	if _, ok := r.Headers[jwsHeaderCritical]; ok {
+		return fmt.Errorf("%w: crit header is not supported", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// SignJWSResponse is a response for SignJWS request. JWS is a JSON string in compact serialization or a JSON object
+// in general JSON serialization.
+type SignJWSResponse struct {
+	JWS json.RawMessage `json:"jws"`
+}
+
+// VerifyJWSRequest is a request to verify a JWS. JWS is a JSON string in compact serialization or a JSON object in
+// general or flattened JSON serialization.
+type VerifyJWSRequest struct {
+	JWS json.RawMessage `json:"jws"`
+}
+
+// Validate validates VerifyJWS request.
+func (r *VerifyJWSRequest) Validate() error {
+	if len(r.JWS) == 0 || string(r.JWS) == "null" {
+		return fmt.Errorf("%w: jws must be non-empty", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// VerifyJWSResponse is a response for VerifyJWS request.
+type VerifyJWSResponse struct {
+	Payload []byte                 `json:"payload"`
+	Headers map[string]interface{} `json:"headers"`
+}
+
+// SignJWTRequest is a request to issue a JWT with the claim set. ExpiresIn is the JWT lifetime in seconds.
+type SignJWTRequest struct {
+	Claims    map[string]interface{} `json:"claims"`
+	ExpiresIn int64                  `json:"expires_in,omitempty"`
+}
+
+// Validate validates SignJWT request.
+func (r *SignJWTRequest) Validate() error {
+	for _, claim := range jwtServerClaims {
+		if _, ok := r.Claims[claim]; ok {
+			return fmt.Errorf("%w: %s claim is set by the server", errors.ErrValidation, claim)
+		}
+	}
+
+	if r.ExpiresIn < 0 {
+		return fmt.Errorf("%w: expires in must be non-negative", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// SignJWTResponse is a response for SignJWT request.
+type SignJWTResponse struct {
+	JWT string `json:"jwt"`
+}
+
+// VerifyJWTRequest is a request to validate a JWT. Audience and Issuer are expected values of the claims, not checked
+// if empty.
+type VerifyJWTRequest struct {
+	JWT      string `json:"jwt"`
+	Audience string `json:"audience,omitempty"`
+	Issuer   string `json:"issuer,omitempty"`
+}
+
+// Validate validates VerifyJWT request.
+func (r *VerifyJWTRequest) Validate() error {
+	if r.JWT == "" {
+		return fmt.Errorf("%w: jwt must be non-empty", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// VerifyJWTResponse is a response for VerifyJWT request.
+type VerifyJWTResponse struct {
+	Claims map[string]interface{} `json:"claims"`
+}
+
+// SignLDProofRequest is a request to add a linked data proof to a verifiable credential or presentation. Signature
+// type is one of Ed25519Signature2018, Ed25519Signature2020, JsonWebSignature2020 or BbsBlsSignature2020 and must
+// match the key type. Created defaults to the current time.
+type SignLDProofRequest struct {
+	Document           json.RawMessage `json:"document"`
+	SignatureType      string          `json:"signature_type"`
+	VerificationMethod string          `json:"verification_method"`
+	ProofPurpose       string          `json:"proof_purpose,omitempty"`
+	Created            *time.Time      `json:"created,omitempty"`
+	Domain             string          `json:"domain,omitempty"`
+	Challenge          string          `json:"challenge,omitempty"`
+}
+
+// Validate validates SignLDProof request.
+func (r *SignLDProofRequest) Validate() error {
+	if len(r.Document) == 0 || string(r.Document) == "null" {
+		return fmt.Errorf("%w: document must be non-empty", errors.ErrValidation)
+	}
+
+	if _, ok := ldProofSuites[r.SignatureType]; !ok {
+		return fmt.Errorf("%w: unsupported signature type %q", errors.ErrValidation, r.SignatureType)
+	}
+
+	if r.VerificationMethod == "" {
+		return fmt.Errorf("%w: verification method must be non-empty", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// SignLDProofResponse is a response for SignLDProof request.
+type SignLDProofResponse struct {
+	Document json.RawMessage `json:"document"`
+}
+
 // EncryptRequest is a request to encrypt a message with associated data.
 type EncryptRequest struct {
 	Message        []byte `json:"message"`
@@ -229,6 +858,7 @@ type WrapKeyRequest struct {
 	APU             []byte            `json:"apu"`
 	APV             []byte            `json:"apv"`
 	RecipientPubKey *crypto.PublicKey `json:"recipient_pub_key"`
+	RecipientKeyID  string            `json:"recipient_key_id,omitempty"` // imported public key to use as recipient
 	Tag             []byte            `json:"tag,omitempty"`
 }
 
@@ -237,6 +867,77 @@ type WrapKeyResponse struct {
 	crypto.RecipientWrappedKey
 }
 
+// EncryptJWERequest is a request to encrypt a plaintext to recipients as JWE. Recipients are EC or X25519 public keys;
+// RecipientKeyIDs are imported public keys of the key store. ContentEncryption is A256GCM (default) or XC20P.
+// Serialization is compact (default) or json.
+type EncryptJWERequest struct {
+	Plaintext         []byte              `json:"plaintext"`
+	AAD               []byte              `json:"aad,omitempty"`
+	Recipients        []*crypto.PublicKey `json:"recipients,omitempty"`
+	RecipientKeyIDs   []string            `json:"recipient_key_ids,omitempty"`
+	ContentEncryption string              `json:"content_encryption,omitempty"`
+	ContentType       string              `json:"content_type,omitempty"`
+	Serialization     string              `json:"serialization,omitempty"`
+}
+
+// Validate validates EncryptJWE request.
+func (r *EncryptJWERequest) Validate() error {
+	n := len(r.Recipients) + len(r.RecipientKeyIDs)
+
+	if n == 0 {
+		return fmt.Errorf("%w: recipients must be non-empty", errors.ErrValidation)
+	}
+
+	for i, k := range r.Recipients {
+		if k == nil {
+			return fmt.Errorf("%w: recipient %d must be non-empty", errors.ErrValidation, i)
+		}
+	}
+
+	if r.ContentEncryption != "" && !containsString(jweContentEncryptions, r.ContentEncryption) {
+		return fmt.Errorf("%w: unsupported content encryption %q", errors.ErrValidation, r.ContentEncryption)
+	}
+
+	switch r.Serialization {
+	case "", JWECompact:
+		if n > 1 || len(r.AAD) > 0 {
+			return fmt.Errorf("%w: compact serialization supports one recipient and no aad", errors.ErrValidation)
+		}
+	case JWEJSON:
+	default:
+		return fmt.Errorf("%w: unsupported serialization %q", errors.ErrValidation, r.Serialization)
+	}
+
+	return nil
+}
+
+// EncryptJWEResponse is a response for EncryptJWE request. JWE is a JSON string in compact serialization or a JSON
+// object in general JSON serialization.
+type EncryptJWEResponse struct {
+	JWE json.RawMessage `json:"jwe"`
+}
+
+// DecryptJWERequest is a request to decrypt a JWE. JWE is a JSON string in compact serialization or a JSON object in
+// JSON serialization. SenderPubKey is needed for authcrypt JWE only.
+type DecryptJWERequest struct {
+	JWE          json.RawMessage   `json:"jwe"`
+	SenderPubKey *crypto.PublicKey `json:"sender_pub_key,omitempty"`
+}
+
+// Validate validates DecryptJWE request.
+func (r *DecryptJWERequest) Validate() error {
+	if len(r.JWE) == 0 || string(r.JWE) == "null" {
+		return fmt.Errorf("%w: jwe must be non-empty", errors.ErrValidation)
+	}
+
+	return nil
+}
+
+// DecryptJWEResponse is a response for DecryptJWE request.
+type DecryptJWEResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
 // UnwrapKeyRequest is a request to unwrap a wrapped key.
 type UnwrapKeyRequest struct {
 	WrappedKey   crypto.RecipientWrappedKey `json:"wrapped_key"`
@@ -248,3 +949,39 @@ type UnwrapKeyRequest struct {
 type UnwrapKeyResponse struct {
 	Key []byte `json:"key"`
 }
+
+func validateKeyAttributes(label, description string, tags map[string]string) error {
+	if len(label) > maxKeyLabelLength {
+		return fmt.Errorf("%w: label must be at most %d characters", errors.ErrValidation, maxKeyLabelLength)
+	}
+
+	if len(description) > maxKeyDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", errors.ErrValidation,
+			maxKeyDescriptionLength)
+	}
+
+	if len(tags) > maxKeyTags {
+		return fmt.Errorf("%w: number of tags must be at most %d", errors.ErrValidation, maxKeyTags)
+	}
+
+	for name, value := range tags {
+		if err := validateTag(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateTag checks that tag can be used in storage query expression ("name:value").
+func validateTag(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("%w: tag name must be non-empty", errors.ErrValidation)
+	}
+
+	if strings.ContainsAny(name, tagReservedChars) || strings.ContainsAny(value, tagReservedChars) {
+		return fmt.Errorf("%w: tag %q must not contain %q characters", errors.ErrValidation, name, tagReservedChars)
+	}
+
+	return nil
+}
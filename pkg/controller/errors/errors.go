@@ -16,6 +16,8 @@ var (
 	ErrValidation = NewBadRequestError(New("validation failed"))
 	ErrBadRequest = NewBadRequestError(New("bad request"))
 	ErrNotFound   = NewNotFoundError(New("not found"))
+	ErrForbidden  = NewForbiddenError(New("forbidden"))
+	ErrConflict   = NewConflictError(New("conflict"))
 	ErrInternal   = NewStatusInternalServerError(New("internal error"))
 )
 
@@ -55,6 +57,16 @@ func NewNotFoundError(err error) *StatusErr {
 	return &StatusErr{error: err, status: http.StatusNotFound}
 }
 
+// NewForbiddenError represents Forbidden error.
+func NewForbiddenError(err error) *StatusErr {
+	return &StatusErr{error: err, status: http.StatusForbidden}
+}
+
+// NewConflictError represents Conflict error.
+func NewConflictError(err error) *StatusErr {
+	return &StatusErr{error: err, status: http.StatusConflict}
+}
+
 // StatusCodeFromError returns status code if an error implements an interface.
 func StatusCodeFromError(e error) int {
 	if err, ok := e.(interface{ StatusCode() int }); ok { // nolint: errorlint
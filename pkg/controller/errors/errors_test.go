@@ -24,6 +24,8 @@ func TestStatusCodeFromError(t *testing.T) {
 	require.Equal(t, StatusCodeFromError(NewStatusInternalServerError(New(errMsg))), http.StatusInternalServerError)
 	require.Equal(t, StatusCodeFromError(NewBadRequestError(New(errMsg))), http.StatusBadRequest)
 	require.Equal(t, StatusCodeFromError(NewNotFoundError(New(errMsg))), http.StatusNotFound)
+	require.Equal(t, StatusCodeFromError(NewForbiddenError(New(errMsg))), http.StatusForbidden)
+	require.Equal(t, StatusCodeFromError(NewConflictError(New(errMsg))), http.StatusConflict)
 
 	// by default error has status InternalServerError
 	require.Equal(t, StatusCodeFromError(New(errMsg)), http.StatusInternalServerError)
@@ -41,6 +43,14 @@ func TestStatusCodeFromError(t *testing.T) {
 	require.True(t, errors.Is(fmt.Errorf("wrapped: %w", ErrNotFound), ErrNotFound))
 	require.Equal(t, errors.Unwrap(NewBadRequestError(fmt.Errorf("wrapped: %w", ErrNotFound))), ErrNotFound)
 
+	require.Equal(t, StatusCodeFromError(fmt.Errorf("wrapped: %w", ErrForbidden)), http.StatusForbidden)
+	require.True(t, errors.Is(fmt.Errorf("wrapped: %w", ErrForbidden), ErrForbidden))
+	require.Equal(t, errors.Unwrap(NewBadRequestError(fmt.Errorf("wrapped: %w", ErrForbidden))), ErrForbidden)
+
+	require.Equal(t, StatusCodeFromError(fmt.Errorf("wrapped: %w", ErrConflict)), http.StatusConflict)
+	require.True(t, errors.Is(fmt.Errorf("wrapped: %w", ErrConflict), ErrConflict))
+	require.Equal(t, errors.Unwrap(NewBadRequestError(fmt.Errorf("wrapped: %w", ErrConflict))), ErrConflict)
+
 	require.Equal(t, StatusCodeFromError(fmt.Errorf("wrapped: %w", ErrInternal)), http.StatusInternalServerError)
 	require.True(t, errors.Is(fmt.Errorf("wrapped: %w", ErrInternal), ErrInternal))
 	require.Equal(t, errors.Unwrap(NewBadRequestError(fmt.Errorf("wrapped: %w", ErrInternal))), ErrInternal)
@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+//go:generate mockgen -destination gomocks_test.go -package adminmw_test . HTTPHandler
+
+package adminmw
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// AdminTokenHeader is a header with the admin token.
+const AdminTokenHeader = "Admin-Token"
+
+// Middleware is an auth middleware for administrative endpoints.
+type Middleware struct {
+	Token string // admin token configured for the server, requests are denied if empty
+}
+
+// HTTPHandler is an alias for http.Handler (used by GoMock to generate a mock).
+type HTTPHandler = http.Handler
+
+// Accept accepts requests with Admin-Token header.
+func (mw *Middleware) Accept(req *http.Request) bool {
+	return req.Header.Get(AdminTokenHeader) != ""
+}
+
+// Middleware returns middleware func.
+func (mw *Middleware) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return &adminHandler{
+			token: mw.Token,
+			next:  next,
+		}
+	}
+}
+
+type adminHandler struct {
+	token string
+	next  http.Handler
+}
+
+// ServeHTTP calls the next handler if the admin token of the request matches the configured one.
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	token := req.Header.Get(AdminTokenHeader)
+
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	h.next.ServeHTTP(w, req)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adminmw_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/kms/pkg/controller/mw/authmw/adminmw"
+)
+
+func TestAccept(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		accepted bool
+	}{
+		{
+			"no admin token header",
+			map[string]string{},
+			false,
+		},
+		{
+			"bearer token",
+			map[string]string{"Authorization": "Bearer token"},
+			false,
+		},
+		{
+			"admin token",
+			map[string]string{adminmw.AdminTokenHeader: "token"},
+			true,
+		},
+	}
+
+	mw := adminmw.Middleware{Token: "token"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), "", "", http.NoBody)
+			require.NoError(t, err)
+
+			for k, v := range tt.headers {
+				req.Header.Add(k, v)
+			}
+
+			require.Equal(t, tt.accepted, mw.Accept(req))
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("should call next handler", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		next := NewMockHTTPHandler(ctrl)
+		next.EXPECT().ServeHTTP(gomock.Any(), gomock.Any()).Times(1)
+
+		req, err := http.NewRequestWithContext(context.Background(), "", "", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Set(adminmw.AdminTokenHeader, "token")
+
+		rr := httptest.NewRecorder()
+
+		mw := adminmw.Middleware{Token: "token"}
+		mw.Middleware()(next).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should reject invalid token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		next := NewMockHTTPHandler(ctrl)
+		next.EXPECT().ServeHTTP(gomock.Any(), gomock.Any()).Times(0)
+
+		req, err := http.NewRequestWithContext(context.Background(), "", "", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Set(adminmw.AdminTokenHeader, "invalid")
+
+		rr := httptest.NewRecorder()
+
+		mw := adminmw.Middleware{Token: "token"}
+		mw.Middleware()(next).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should reject requests if admin token is not configured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		next := NewMockHTTPHandler(ctrl)
+		next.EXPECT().ServeHTTP(gomock.Any(), gomock.Any()).Times(0)
+
+		req, err := http.NewRequestWithContext(context.Background(), "", "", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Set(adminmw.AdminTokenHeader, "token")
+
+		rr := httptest.NewRecorder()
+
+		mw := adminmw.Middleware{}
+		mw.Middleware()(next).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
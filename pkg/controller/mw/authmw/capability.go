@@ -0,0 +1,28 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package authmw
+
+import (
+	"context"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+type capabilityContextKey struct{}
+
+// WithCapability returns a copy of the context with the capability the request was authorized with.
+func WithCapability(ctx context.Context, capability *zcapld.Capability) context.Context {
+	return context.WithValue(ctx, capabilityContextKey{}, capability)
+}
+
+// CapabilityFromContext returns the capability the request was authorized with or nil if the request was not
+// authorized with ZCAP.
+func CapabilityFromContext(ctx context.Context) *zcapld.Capability {
+	capability, _ := ctx.Value(capabilityContextKey{}).(*zcapld.Capability)
+
+	return capability
+}
@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package authmw_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+
+	"github.com/trustbloc/kms/pkg/controller/mw/authmw"
+)
+
+func TestCapabilityFromContext(t *testing.T) {
+	t.Run("should return capability set in context", func(t *testing.T) {
+		capability := &zcapld.Capability{ID: "urn:zcap:test", AllowedAction: []string{"sign"}}
+
+		ctx := authmw.WithCapability(context.Background(), capability)
+
+		require.Equal(t, capability, authmw.CapabilityFromContext(ctx))
+	})
+
+	t.Run("should return nil if capability is not set", func(t *testing.T) {
+		require.Nil(t, authmw.CapabilityFromContext(context.Background()))
+	})
+}
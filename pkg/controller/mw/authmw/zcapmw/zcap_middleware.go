@@ -10,7 +10,10 @@ package zcapmw
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -24,6 +27,7 @@ import (
 	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/edge-core/pkg/zcapld"
 
+	"github.com/trustbloc/kms/pkg/controller/mw/authmw"
 	"github.com/trustbloc/kms/pkg/metrics"
 )
 
@@ -145,13 +149,86 @@ func (h *mwHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		expectations,
 		func(w http.ResponseWriter, r *http.Request) {
 			metrics.Get().ZCAPLDTime(time.Since(getStartTime))
-			h.next.ServeHTTP(w, r)
+
+			capability, err := invokedCapability(r)
+			if err != nil {
+				h.logger.Errorf("failed to parse invoked capability: %s", err.Error())
+				http.Error(w, "bad request", http.StatusBadRequest)
+
+				return
+			}
+
+			if err = h.checkRootCapability(capability, resource); err != nil {
+				h.logError(err)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+				return
+			}
+
+			h.next.ServeHTTP(w, r.WithContext(authmw.WithCapability(r.Context(), capability)))
 		},
 	).ServeHTTP(w, r)
 
 	h.logger.Debugf("finished handling request: %s", r.URL.String())
 }
 
+// invokedCapability returns the capability from the capability invocation header verified by the zcapld auth handler.
+// Commands use it to check what the invoked capability allows beyond the route's action.
+func invokedCapability(r *http.Request) (*zcapld.Capability, error) {
+	const scheme = "zcap "
+
+	value := strings.TrimSpace(strings.Join(r.Header.Values(zcapld.CapabilityInvocationHTTPHeader), ", "))
+
+	if len(value) < len(scheme) {
+		return nil, errors.New("invalid capability invocation header")
+	}
+
+	for _, param := range strings.Split(value[len(scheme):], ",") {
+		k, v, ok := strings.Cut(param, "=")
+		if ok && k == "capability" {
+			return zcapld.DecompressZCAP(strings.Trim(v, `"`))
+		}
+	}
+
+	return nil, errors.New("capability is missing in capability invocation header")
+}
+
+// checkRootCapability rejects capabilities of a previous controller of the resource. The root capability is reissued
+// under the resource ID when the controller changes, and the zcapld verifier resolves the root by ID only, so the
+// presented root must have the invoker of the stored one and a capability delegated from the root must be signed by
+// that invoker. Deeper delegations resolve their parents from storage, which are revoked together with the root.
+func (h *mwHandler) checkRootCapability(capability *zcapld.Capability, rootID string) error {
+	if capability.Parent != "" && capability.Parent != rootID {
+		return nil
+	}
+
+	root, err := h.zcaps.Resolve(rootID)
+	if err != nil {
+		return fmt.Errorf("resolve root capability: %w", err)
+	}
+
+	if capability.Parent == "" {
+		if capability.Invoker != root.Invoker {
+			return errors.New("root capability is issued to another controller")
+		}
+
+		return nil
+	}
+
+	if len(capability.Proof) == 0 {
+		return errors.New("capability has no proof")
+	}
+
+	verificationMethod, _ := capability.Proof[0]["verificationMethod"].(string)
+
+	if delegator, _, _ := strings.Cut(verificationMethod, "#"); delegator != root.Invoker &&
+		verificationMethod != root.Invoker {
+		return errors.New("capability is delegated by another controller")
+	}
+
+	return nil
+}
+
 func (h *mwHandler) logError(err error) {
 	h.logger.Errorf("unauthorized capability invocation: %s", err.Error())
 }
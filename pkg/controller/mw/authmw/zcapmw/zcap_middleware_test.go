@@ -8,12 +8,15 @@ package zcapmw //nolint:testpackage // mocking internal implementation details
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	arieskms "github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/edge-core/pkg/log/mocklogger"
@@ -87,6 +90,100 @@ func TestMiddleware(t *testing.T) {
 	})
 }
 
+func TestInvokedCapability(t *testing.T) {
+	t.Run("should return capability from invocation header", func(t *testing.T) {
+		capability := &zcapld.Capability{ID: "urn:zcap:test", Parent: "urn:zcap:root", AllowedAction: []string{"sign"}}
+
+		compressed, err := zcapld.CompressZCAP(capability)
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(context.Background(), "", "", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Add(zcapld.CapabilityInvocationHTTPHeader,
+			fmt.Sprintf(`zcap capability=%q,action="sign"`, compressed))
+
+		invoked, err := invokedCapability(req)
+		require.NoError(t, err)
+		require.Equal(t, capability.ID, invoked.ID)
+		require.Equal(t, capability.Parent, invoked.Parent)
+		require.Equal(t, capability.AllowedAction, invoked.AllowedAction)
+	})
+
+	t.Run("should fail if header is invalid", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), "", "", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Add(zcapld.CapabilityInvocationHTTPHeader, "zcap")
+
+		_, err = invokedCapability(req)
+		require.EqualError(t, err, "invalid capability invocation header")
+	})
+
+	t.Run("should fail if capability is missing", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), "", "", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Add(zcapld.CapabilityInvocationHTTPHeader, `zcap action="sign"`)
+
+		_, err = invokedCapability(req)
+		require.EqualError(t, err, "capability is missing in capability invocation header")
+	})
+}
+
+func TestCheckRootCapability(t *testing.T) {
+	const (
+		resource      = "https://kms.example.com/v1/keystores/key_store_id"
+		oldController = "did:key:z6MkOldController"
+		newController = "did:key:z6MkNewController"
+	)
+
+	// root capability reissued under the resource ID when the controller is changed to the new one
+	h := &mwHandler{zcaps: &mockAuthService{resolveVal: &zcapld.Capability{ID: resource, Invoker: newController}}}
+
+	delegated := func(delegator string) *zcapld.Capability {
+		return &zcapld.Capability{
+			ID:      "urn:zcap:delegated",
+			Parent:  resource,
+			Invoker: "did:key:z6MkDelegate",
+			Proof:   []verifiable.Proof{{"verificationMethod": delegator + "#" + delegator[len("did:key:"):]}},
+		}
+	}
+
+	t.Run("accepts capabilities of the current controller", func(t *testing.T) {
+		require.NoError(t, h.checkRootCapability(&zcapld.Capability{ID: resource, Invoker: newController}, resource))
+		require.NoError(t, h.checkRootCapability(delegated(newController), resource))
+	})
+
+	t.Run("rejects root capability of the previous controller", func(t *testing.T) {
+		err := h.checkRootCapability(&zcapld.Capability{ID: resource, Invoker: oldController}, resource)
+		require.EqualError(t, err, "root capability is issued to another controller")
+	})
+
+	t.Run("rejects capability delegated by the previous controller", func(t *testing.T) {
+		err := h.checkRootCapability(delegated(oldController), resource)
+		require.EqualError(t, err, "capability is delegated by another controller")
+	})
+
+	t.Run("rejects delegated capability without proof", func(t *testing.T) {
+		err := h.checkRootCapability(&zcapld.Capability{ID: "urn:zcap:delegated", Parent: resource}, resource)
+		require.EqualError(t, err, "capability has no proof")
+	})
+
+	t.Run("skips capabilities delegated from stored intermediates", func(t *testing.T) {
+		capability := &zcapld.Capability{ID: "urn:zcap:test", Parent: "urn:zcap:delegated"}
+
+		require.NoError(t, (&mwHandler{}).checkRootCapability(capability, resource))
+	})
+
+	t.Run("fails to resolve root capability", func(t *testing.T) {
+		h := &mwHandler{zcaps: &mockAuthService{resolveErr: errors.New("not found")}}
+
+		err := h.checkRootCapability(&zcapld.Capability{ID: resource, Invoker: newController}, resource)
+		require.EqualError(t, err, "resolve root capability: not found")
+	})
+}
+
 func TestZCAPMetrics(t *testing.T) {
 	t.Run("CapabilityResolver", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
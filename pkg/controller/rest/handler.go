@@ -36,6 +36,8 @@ const (
 	AuthZCAP
 	// AuthGNAP defines GNAP as a supported auth method for the handler.
 	AuthGNAP
+	// AuthAdmin defines that the handler is authorized with the admin token of the server.
+	AuthAdmin
 )
 
 // HasFlag checks if the given auth method is set.
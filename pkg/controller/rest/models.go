@@ -70,6 +70,278 @@ type createKeyStoreResp struct { //nolint:unused,deadcode
 	}
 }
 
+// getKeyStoreReq model
+//
+// swagger:parameters getKeyStoreReq
+type getKeyStoreReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+}
+
+// getKeyStoreResp model
+//
+// swagger:response getKeyStoreResp
+type getKeyStoreResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Key store ID.
+		ID string `json:"id"`
+
+		// Controller of the key store.
+		Controller string `json:"controller"`
+
+		// ID of the server's key used for the key store secret lock.
+		MainKeyID string `json:"main_key_id,omitempty"`
+
+		// EDV parameters if the key store uses EDV for storing keys.
+		EDV struct {
+			// EDV vault URL.
+			VaultURL string `json:"vault_url"`
+
+			// ID of the server's key used as EDV recipient key.
+			RecipientKeyID string `json:"recipient_key_id"`
+
+			// ID of the server's key used as EDV MAC key.
+			MACKeyID string `json:"mac_key_id"`
+		} `json:"edv,omitempty"`
+
+		// Status of the key store (active or deactivated).
+		Status string `json:"status"`
+
+		// Creation time of the key store.
+		CreatedAt time.Time `json:"created_at"`
+	}
+}
+
+// updateKeyStoreReq model
+//
+// swagger:parameters updateKeyStoreReq
+type updateKeyStoreReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// in: body
+	Body struct {
+		// New controller of the key store.
+		Controller string `json:"controller,omitempty"`
+
+		// EDV parameters of the key store. Vault URL can't be changed, use key store migration instead.
+		EDV struct {
+			// Current EDV vault URL.
+			// required: true
+			VaultURL string `json:"vault_url"`
+		} `json:"edv,omitempty"`
+	}
+}
+
+// updateKeyStoreResp model
+//
+// swagger:response updateKeyStoreResp
+type updateKeyStoreResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Base64-encoded root ZCAPs for the new controller of the key store.
+		Capability string `json:"capability,omitempty"`
+	}
+}
+
+// deleteKeyStoreReq model
+//
+// swagger:parameters deleteKeyStoreReq
+type deleteKeyStoreReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+}
+
+// deleteKeyStoreResp model
+//
+// swagger:response deleteKeyStoreResp
+type deleteKeyStoreResp struct{} //nolint:unused,deadcode
+
+// deactivateKeyStoreReq model
+//
+// swagger:parameters deactivateKeyStoreReq
+type deactivateKeyStoreReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+}
+
+// deactivateKeyStoreResp model
+//
+// swagger:response deactivateKeyStoreResp
+type deactivateKeyStoreResp struct{} //nolint:unused,deadcode
+
+// activateKeyStoreReq model
+//
+// swagger:parameters activateKeyStoreReq
+type activateKeyStoreReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+}
+
+// activateKeyStoreResp model
+//
+// swagger:response activateKeyStoreResp
+type activateKeyStoreResp struct{} //nolint:unused,deadcode
+
+// backupKeyStoreReq model
+//
+// swagger:parameters backupKeyStoreReq
+type backupKeyStoreReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+}
+
+// backupKeyStoreResp model
+//
+// swagger:response backupKeyStoreResp
+type backupKeyStoreResp struct { //nolint:unused,deadcode
+	// in: body
+	Body keyStoreBackup
+}
+
+// restoreKeyStoreReq model
+//
+// swagger:parameters restoreKeyStoreReq
+type restoreKeyStoreReq struct { //nolint:unused,deadcode
+	// The header with the admin token of the server.
+	//
+	// Admin-Token header
+	AdminToken string `json:"Admin-Token"`
+
+	// in: body
+	Body struct {
+		// Key store backup.
+		// required: true
+		Backup keyStoreBackup `json:"backup"`
+	}
+}
+
+// restoreKeyStoreResp model
+//
+// swagger:response restoreKeyStoreResp
+type restoreKeyStoreResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Key store URL.
+		KeyStoreURL string `json:"key_store_url"`
+
+		// Base64-encoded root ZCAPs for key store.
+		Capability string `json:"capability"`
+	}
+}
+
+// migrateKeyStoreReq model
+//
+// swagger:parameters migrateKeyStoreReq
+type migrateKeyStoreReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The header with a user (subject) to use for fetching secret share from Auth server.
+	//
+	// Auth-User header
+	AuthUser string `json:"Auth-User"`
+
+	// The header with a secret share for Shamir secret lock.
+	//
+	// Secret-Share header
+	SecretShare string `json:"Secret-Share"`
+
+	// in: body
+	Body struct {
+		// Options for EDV vault to move keys to. If empty, keys are moved to server's storage.
+		EDV struct {
+			// Vault URL on EDV server.
+			VaultURL string `json:"vault_url"`
+
+			// Base64-encoded EDV ZCAPs.
+			Capability string `json:"capability"`
+		} `json:"edv"`
+
+		// IDs of keys created before key metadata was introduced. Their metadata is saved before migration, as
+		// keys without metadata are not migrated. EDV keys of the old vault of such key stores are kept.
+		KeyIDs []string `json:"key_ids"`
+	}
+}
+
+// migrateKeyStoreResp model
+//
+// swagger:response migrateKeyStoreResp
+type migrateKeyStoreResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Number of migrated keys.
+		MigratedKeys int `json:"migrated_keys"`
+	}
+}
+
+// updateEDVCapabilityReq model
+//
+// swagger:parameters updateEDVCapabilityReq
+type updateEDVCapabilityReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// in: body
+	Body struct {
+		// Base64-encoded EDV capability with the vault as invocation target.
+		// required: true
+		Capability string `json:"capability"`
+	}
+}
+
+// updateEDVCapabilityResp model
+//
+// swagger:response updateEDVCapabilityResp
+type updateEDVCapabilityResp struct{} //nolint:unused,deadcode
+
+// reissueRootCapabilityReq model
+//
+// swagger:parameters reissueRootCapabilityReq
+type reissueRootCapabilityReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+}
+
+// reissueRootCapabilityResp model
+//
+// swagger:response reissueRootCapabilityResp
+type reissueRootCapabilityResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Base64-encoded root ZCAP with all actions supported by the server.
+		Capability string `json:"capability"`
+	}
+}
+
 // createKeyReq model
 //
 // swagger:parameters createKeyReq
@@ -80,68 +352,794 @@ type createKeyReq struct { //nolint:unused,deadcode
 	// required: true
 	KeyStoreID string `json:"key_store_id"`
 
-	// in: body
-	Body struct {
-		// A type of key to create. Check https://github.com/hyperledger/aries-framework-go/blob/main/pkg/kms/api.go
-		// for supported key types.
-		KeyType string `json:"key_type"`
-	}
-}
+	// in: body
+	Body struct {
+		// A type of key to create. Check https://github.com/hyperledger/aries-framework-go/blob/main/pkg/kms/api.go
+		// for supported key types.
+		KeyType string `json:"key_type"`
+
+		// A human-readable label of the key.
+		Label string `json:"label,omitempty"`
+
+		// A description of the key.
+		Description string `json:"description,omitempty"`
+
+		// Free-form tags of the key, e.g. {"purpose": "vc-issuance"}. Tag names and values must not contain ':' or '&'.
+		Tags map[string]string `json:"tags,omitempty"`
+
+		// Operations allowed for the key, e.g. ["verify"]. Must be supported by the key type. Defaults to all
+		// operations supported by the key type.
+		AllowedOperations []string `json:"allowed_operations,omitempty"`
+
+		// Time before which the key can't be used.
+		NotBefore *time.Time `json:"not_before,omitempty"`
+
+		// Time after which the key can't be used. Verify, decrypt and unwrap are allowed for a grace period
+		// configured on the server.
+		NotAfter *time.Time `json:"not_after,omitempty"`
+
+		// Policy of scheduled rotation of the key.
+		RotationPolicy *rotationPolicy `json:"rotation_policy,omitempty"`
+
+		// Publish the public key in the JWK Set of the key store served at /v1/keystores/{key_store_id}/jwks.json.
+		Publish bool `json:"publish,omitempty"`
+
+		// Allow the private keyset to be exported encrypted to a transport key. Can't be changed after creation.
+		Exportable bool `json:"exportable,omitempty"`
+	}
+}
+
+// createKeyResp model
+//
+// swagger:response createKeyResp
+type createKeyResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// URL to created key.
+		KeyURL string `json:"key_url"`
+
+		// A base64-encoded public key. It is empty if key is symmetric.
+		PublicKey string `json:"public_key"`
+	}
+}
+
+// importKeyReq model
+//
+// swagger:parameters importKeyReq
+type importKeyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// in: body
+	Body struct {
+		// A base64-encoded key to import. JWK is accepted for all key types. Otherwise the key is a PKCS#8 private
+		// key for ED25519, ECDSA and NISTPxxxECDHKW types, a raw private key for X25519ECDHKW and BLS12381G2 types
+		// or raw key material for AES-GCM and HMAC types.
+		// required: true
+		Key string `json:"key"`
+
+		// A type of key to be imported.
+		// required: true
+		KeyType string `json:"key_type"`
+
+		// An optional key ID to associate imported key with.
+		KeyID string `json:"key_id,omitempty"`
+
+		// A human-readable label of the key.
+		Label string `json:"label,omitempty"`
+
+		// A description of the key.
+		Description string `json:"description,omitempty"`
+
+		// Free-form tags of the key, e.g. {"purpose": "vc-issuance"}. Tag names and values must not contain ':' or '&'.
+		Tags map[string]string `json:"tags,omitempty"`
+
+		// Operations allowed for the key, e.g. ["verify"]. Must be supported by the key type. Defaults to all
+		// operations supported by the key type.
+		AllowedOperations []string `json:"allowed_operations,omitempty"`
+
+		// Time before which the key can't be used.
+		NotBefore *time.Time `json:"not_before,omitempty"`
+
+		// Time after which the key can't be used. Verify, decrypt and unwrap are allowed for a grace period
+		// configured on the server.
+		NotAfter *time.Time `json:"not_after,omitempty"`
+
+		// Policy of scheduled rotation of the key.
+		RotationPolicy *rotationPolicy `json:"rotation_policy,omitempty"`
+
+		// Import a public key only. The key is a JWK, a PEM or DER encoded SPKI or raw public key bytes (uncompressed
+		// point for ECDSA and NISTPxxxECDHKW types). Public keys can be used for verification and key wrapping only.
+		Public bool `json:"public,omitempty"`
+
+		// Publish the public key in the JWK Set of the key store served at /v1/keystores/{key_store_id}/jwks.json.
+		Publish bool `json:"publish,omitempty"`
+	}
+}
+
+// importKeyResp model
+//
+// swagger:response importKeyResp
+type importKeyResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// URL of imported key.
+		KeyURL string `json:"key_url"`
+	}
+}
+
+// listKeysReq model
+//
+// swagger:parameters listKeysReq
+type listKeysReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The maximum number of keys to return. Defaults to 100.
+	//
+	// in: query
+	Limit int `json:"limit"`
+
+	// The number of keys to skip.
+	//
+	// in: query
+	Offset int `json:"offset"`
+
+	// Return only keys with the tag. Format is "name" (any value) or "name=value".
+	//
+	// in: query
+	Tag string `json:"tag"`
+}
+
+type rotationPolicy struct { //nolint:unused
+	// Interval of scheduled rotation in days, e.g. 90. Zero disables scheduled rotation.
+	IntervalDays int `json:"interval_days"`
+
+	// A type of the rotated key. Defaults to the current type of the key.
+	KeyType string `json:"key_type,omitempty"`
+}
+
+type keyInfo struct { //nolint:unused
+	// Key ID.
+	KeyID string `json:"key_id"`
+
+	// Key URL.
+	KeyURL string `json:"key_url"`
+
+	// Key type.
+	KeyType string `json:"key_type"`
+
+	// Key status: "enabled" or "pending_deletion".
+	Status string `json:"status"`
+
+	// Time when the key will be destroyed. It is set only if the key is pending deletion.
+	DeletionTime *time.Time `json:"deletion_time,omitempty"`
+
+	// Time when the key was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// A base64-encoded public key. It is empty if key is symmetric.
+	PublicKey string `json:"public_key,omitempty"`
+
+	// A human-readable label of the key.
+	Label string `json:"label,omitempty"`
+
+	// A description of the key.
+	Description string `json:"description,omitempty"`
+
+	// Free-form tags of the key.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Operations allowed for the key: sign, verify, encrypt, decrypt, computeMAC, verifyMAC, signMulti, verifyMulti,
+	// deriveProof, verifyProof, wrap or unwrap.
+	AllowedOperations []string `json:"allowed_operations,omitempty"`
+
+	// Time before which the key can't be used.
+	NotBefore *time.Time `json:"not_before,omitempty"`
+
+	// Time after which the key expires.
+	NotAfter *time.Time `json:"not_after,omitempty"`
+
+	// Rotation policy of the key.
+	RotationPolicy *rotationPolicy `json:"rotation_policy,omitempty"`
+
+	// Time when the key was last rotated.
+	LastRotatedAt *time.Time `json:"last_rotated_at,omitempty"`
+
+	// Time of the next scheduled rotation of the key.
+	NextRotationAt *time.Time `json:"next_rotation_at,omitempty"`
+
+	// Whether the public key is published in the JWK Set of the key store.
+	Published bool `json:"published,omitempty"`
+
+	// Whether the private keyset can be exported.
+	Exportable bool `json:"exportable,omitempty"`
+}
+
+// listKeysResp model
+//
+// swagger:response listKeysResp
+type listKeysResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Keys in the key store.
+		Keys []keyInfo `json:"keys"`
+
+		// Total number of keys in the key store.
+		Total int `json:"total"`
+	}
+}
+
+// updateKeyReq model
+//
+// swagger:parameters updateKeyReq
+type updateKeyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
+	// in: body
+	Body struct {
+		// A new label of the key.
+		Label *string `json:"label,omitempty"`
+
+		// A new description of the key.
+		Description *string `json:"description,omitempty"`
+
+		// New tags of the key. Replace existing tags as a whole.
+		Tags map[string]string `json:"tags,omitempty"`
+
+		// A new rotation policy of the key. Policy with zero interval disables scheduled rotation.
+		RotationPolicy *rotationPolicy `json:"rotation_policy,omitempty"`
+
+		// Publish or unpublish the public key in the JWK Set of the key store.
+		Publish *bool `json:"publish,omitempty"`
+	}
+}
+
+// updateKeyResp model
+//
+// swagger:response updateKeyResp
+type updateKeyResp struct{} //nolint:unused,deadcode
+
+// listKeyVersionsReq model
+//
+// swagger:parameters listKeyVersionsReq
+type listKeyVersionsReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+}
+
+// keyVersionInfo model
+type keyVersionInfo struct { //nolint:unused,deadcode
+	// A version number, starting from 1.
+	Version int `json:"version"`
+
+	// The key ID the version was created with.
+	KeyID string `json:"key_id,omitempty"`
+
+	// A status of the version: enabled, disabled or destroyed.
+	Status string `json:"status"`
+
+	// Whether the version is primary, i.e. used for signing and encryption.
+	Primary bool `json:"primary"`
+
+	// A time the version was created.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// listKeyVersionsResp model
+//
+// swagger:response listKeyVersionsResp
+type listKeyVersionsResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Versions of the key from the oldest to the newest.
+		Versions []keyVersionInfo `json:"versions"`
+	}
+}
+
+// exportKeyReq model
+//
+// swagger:parameters exportKeyReq
+type exportKeyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
+	// Format of the exported public key: raw, jwk, pem, multibase or didkey. Defaults to raw.
+	//
+	// in: query
+	Format string `json:"format"`
+}
+
+// exportKeyResp model
+//
+// swagger:response exportKeyResp
+type exportKeyResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// A base64-encoded public key.
+		PublicKey string `json:"public_key"`
+
+		// A type of the key.
+		KeyType string `json:"key_type"`
+
+		// Format of the exported public key if requested.
+		Format string `json:"format,omitempty"`
+
+		// Public key as JWK with kid, alg and crv set. Returned for jwk format.
+		JWK map[string]interface{} `json:"jwk,omitempty"`
+
+		// PEM encoded SubjectPublicKeyInfo. Returned for pem format.
+		PEM string `json:"pem,omitempty"`
+
+		// Base58-btc multibase encoded multicodec public key. Returned for multibase format.
+		Multibase string `json:"multibase,omitempty"`
+
+		// A did:key identifier of the public key. Returned for didkey format.
+		DIDKey string `json:"did_key,omitempty"`
+
+		// A human-readable label of the key.
+		Label string `json:"label,omitempty"`
+
+		// A description of the key.
+		Description string `json:"description,omitempty"`
+
+		// Free-form tags of the key, e.g. {"purpose": "vc-issuance"}. Tag names and values must not contain ':' or '&'.
+		Tags map[string]string `json:"tags,omitempty"`
+
+		// Operations allowed for the key: sign, verify, encrypt, decrypt, computeMAC, verifyMAC, signMulti, verifyMulti,
+		// deriveProof, verifyProof, wrap or unwrap.
+		AllowedOperations []string `json:"allowed_operations,omitempty"`
+
+		// Time before which the key can't be used.
+		NotBefore *time.Time `json:"not_before,omitempty"`
+
+		// Time after which the key expires.
+		NotAfter *time.Time `json:"not_after,omitempty"`
+
+		// Rotation policy of the key.
+		RotationPolicy *rotationPolicy `json:"rotation_policy,omitempty"`
+
+		// Time when the key was last rotated.
+		LastRotatedAt *time.Time `json:"last_rotated_at,omitempty"`
+
+		// Time of the next scheduled rotation of the key.
+		NextRotationAt *time.Time `json:"next_rotation_at,omitempty"`
+
+		// Whether the public key is published in the JWK Set of the key store.
+		Published bool `json:"published,omitempty"`
+
+		// Whether the private keyset can be exported.
+		Exportable bool `json:"exportable,omitempty"`
+	}
+}
+
+// exportPrivateKeyReq model
+//
+// swagger:parameters exportPrivateKeyReq
+type exportPrivateKeyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
+	// in: body
+	Body struct {
+		// Transport public key (NIST P or X25519) to encrypt the private keyset to.
+		// required: true
+		TransportKey publicKey `json:"transport_key"`
+	}
+}
+
+// exportPrivateKeyResp model
+//
+// swagger:response exportPrivateKeyResp
+type exportPrivateKeyResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Compact serialized ECDH-ES JWE with the private keyset in tink JSON format.
+		JWE string `json:"jwe"`
+	}
+}
+
+// deleteKeyReq model
+//
+// swagger:parameters deleteKeyReq
+type deleteKeyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
+	// The number of days (up to 30) before the key is destroyed. The key is disabled immediately and can be restored
+	// during this period. If not set, the key is destroyed immediately.
+	//
+	// in: query
+	PendingWindowDays int `json:"pending_window_days"`
+}
+
+// deleteKeyResp model
+//
+// swagger:response deleteKeyResp
+type deleteKeyResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Key status: "deleted" or "pending_deletion".
+		Status string `json:"status"`
+
+		// Time when the key will be destroyed.
+		DeletionTime *time.Time `json:"deletion_time,omitempty"`
+	}
+}
+
+// cancelDeletionReq model
+//
+// swagger:parameters cancelDeletionReq
+type cancelDeletionReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+}
+
+// cancelDeletionResp model
+//
+// swagger:response cancelDeletionResp
+type cancelDeletionResp struct{} //nolint:unused,deadcode
+
+// enableKeyReq model
+//
+// swagger:parameters enableKeyReq
+type enableKeyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+}
+
+// enableKeyResp model
+//
+// swagger:response enableKeyResp
+type enableKeyResp struct{} //nolint:unused,deadcode
+
+// disableKeyReq model
+//
+// swagger:parameters disableKeyReq
+type disableKeyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+}
+
+// disableKeyResp model
+//
+// swagger:response disableKeyResp
+type disableKeyResp struct{} //nolint:unused,deadcode
+
+// rotateKeyReq model
+//
+// swagger:parameters rotateKeyReq
+type rotateKeyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
+	// in: body
+	Body struct {
+
+		// A type on new key.
+		// required: true
+		KeyType string `json:"key_type"`
+	}
+}
+
+// rotateKeyResp model
+//
+// swagger:response rotateKeyResp
+type rotateKeyResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// URL of rotated key.
+		KeyURL string `json:"key_url"`
+	}
+}
+
+// setAliasReq model
+//
+// swagger:parameters setAliasReq
+type setAliasReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The alias name.
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+
+	// in: body
+	Body struct {
+		// ID of the key the alias points to.
+		// required: true
+		KeyID string `json:"key_id"`
+	}
+}
+
+// getAliasReq model
+//
+// swagger:parameters getAliasReq
+type getAliasReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The alias name.
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+}
+
+type aliasInfo struct { //nolint:unused
+	// Alias name.
+	Name string `json:"name"`
+
+	// ID of the key the alias points to.
+	KeyID string `json:"key_id"`
+
+	// URL of the key the alias points to.
+	KeyURL string `json:"key_url"`
+
+	// Time when the alias was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Time when the alias was last updated.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// aliasResp model
+//
+// swagger:response aliasResp
+type aliasResp struct { //nolint:unused,deadcode
+	// in: body
+	Body aliasInfo
+}
+
+// listAliasesReq model
+//
+// swagger:parameters listAliasesReq
+type listAliasesReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+}
+
+// listAliasesResp model
+//
+// swagger:response listAliasesResp
+type listAliasesResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Aliases in the key store.
+		Aliases []aliasInfo `json:"aliases"`
+	}
+}
+
+// deleteAliasReq model
+//
+// swagger:parameters deleteAliasReq
+type deleteAliasReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The alias name.
+	//
+	// in: path
+	// required: true
+	Alias string `json:"alias"`
+}
+
+// deleteAliasResp model
+//
+// swagger:response deleteAliasResp
+type deleteAliasResp struct{} //nolint:unused,deadcode
+
+// signReq model
+//
+// swagger:parameters signReq
+type signReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
+	// in: body
+	Body struct {
+		// A base64-encoded message to sign.
+		Message string `json:"message"`
+	}
+}
+
+// signResp model
+//
+// swagger:response signResp
+type signResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// A base64-encoded signature.
+		Signature string `json:"signature"`
+	}
+}
+
+// verifyReq model
+//
+// swagger:parameters verifyReq
+type verifyReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
 
-// createKeyResp model
-//
-// swagger:response createKeyResp
-type createKeyResp struct { //nolint:unused,deadcode
 	// in: body
 	Body struct {
-		// URL to created key.
-		KeyURL string `json:"key_url"`
+		// A base64-encoded signature.
+		Signature string `json:"signature"`
 
-		// A base64-encoded public key. It is empty if key is symmetric.
-		PublicKey string `json:"public_key"`
+		// A base64-encoded message.
+		Message string `json:"message"`
 	}
 }
 
-// importKeyReq model
+// verifyResp model
 //
-// swagger:parameters importKeyReq
-type importKeyReq struct { //nolint:unused,deadcode
+// swagger:response verifyResp
+type verifyResp struct{} //nolint:unused,deadcode
+
+// signJWSReq model
+//
+// swagger:parameters signJWSReq
+type signJWSReq struct { //nolint:unused,deadcode
 	// The key store's ID.
 	//
 	// in: path
 	// required: true
 	KeyStoreID string `json:"key_store_id"`
 
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
 	// in: body
 	Body struct {
-		// A base64-encoded key to import.
-		// required: true
-		Key string `json:"key"`
+		// A base64-encoded payload.
+		Payload string `json:"payload"`
 
-		// A type of key to be imported.
-		// required: true
-		KeyType string `json:"key_type"`
+		// Additional protected headers. Alg is set from the key type, kid defaults to the key's ID.
+		Headers map[string]interface{} `json:"headers,omitempty"`
 
-		// An optional key ID to associate imported key with.
-		KeyID string `json:"key_id,omitempty"`
+		// JWS serialization: compact (default) or json.
+		Serialization string `json:"serialization,omitempty"`
 	}
 }
 
-// importKeyResp model
+// signJWSResp model
 //
-// swagger:response importKeyResp
-type importKeyResp struct { //nolint:unused,deadcode
+// swagger:response signJWSResp
+type signJWSResp struct { //nolint:unused,deadcode
 	// in: body
 	Body struct {
-		// URL of imported key.
-		KeyURL string `json:"key_url"`
+		// A string in compact serialization or an object in general JSON serialization.
+		JWS interface{} `json:"jws"`
 	}
 }
 
-// exportKeyReq model
+// verifyJWSReq model
 //
-// swagger:parameters exportKeyReq
-type exportKeyReq struct { //nolint:unused,deadcode
+// swagger:parameters verifyJWSReq
+type verifyJWSReq struct { //nolint:unused,deadcode
 	// The key store's ID.
 	//
 	// in: path
@@ -153,23 +1151,32 @@ type exportKeyReq struct { //nolint:unused,deadcode
 	// in: path
 	// required: true
 	KeyID string `json:"key_id"`
+
+	// in: body
+	Body struct {
+		// A string in compact serialization or an object in general or flattened JSON serialization.
+		JWS interface{} `json:"jws"`
+	}
 }
 
-// exportKeyResp model
+// verifyJWSResp model
 //
-// swagger:response exportKeyResp
-type exportKeyResp struct { //nolint:unused,deadcode
+// swagger:response verifyJWSResp
+type verifyJWSResp struct { //nolint:unused,deadcode
 	// in: body
 	Body struct {
-		// A base64-encoded public key.
-		PublicKey string `json:"public_key"`
+		// A base64-encoded payload.
+		Payload string `json:"payload"`
+
+		// Protected headers of the verified signature.
+		Headers map[string]interface{} `json:"headers"`
 	}
 }
 
-// rotateKeyReq model
+// signJWTReq model
 //
-// swagger:parameters rotateKeyReq
-type rotateKeyReq struct { //nolint:unused,deadcode
+// swagger:parameters signJWTReq
+type signJWTReq struct { //nolint:unused,deadcode
 	// The key store's ID.
 	//
 	// in: path
@@ -184,28 +1191,30 @@ type rotateKeyReq struct { //nolint:unused,deadcode
 
 	// in: body
 	Body struct {
+		// JWT claim set. Claims iat, exp and jti are set by the server.
+		Claims map[string]interface{} `json:"claims"`
 
-		// A type on new key.
-		// required: true
-		KeyType string `json:"key_type"`
+		// JWT lifetime in seconds. Defaults to the JWT lifetime of the server (3600 unless configured), at most the max
+		// JWT lifetime of the server (86400 unless configured).
+		ExpiresIn int64 `json:"expires_in,omitempty"`
 	}
 }
 
-// rotateKeyResp model
+// signJWTResp model
 //
-// swagger:response rotateKeyResp
-type rotateKeyResp struct { //nolint:unused,deadcode
+// swagger:response signJWTResp
+type signJWTResp struct { //nolint:unused,deadcode
 	// in: body
 	Body struct {
-		// URL of rotated key.
-		KeyURL string `json:"key_url"`
+		// JWT in compact serialization.
+		JWT string `json:"jwt"`
 	}
 }
 
-// signReq model
+// verifyJWTReq model
 //
-// swagger:parameters signReq
-type signReq struct { //nolint:unused,deadcode
+// swagger:parameters verifyJWTReq
+type verifyJWTReq struct { //nolint:unused,deadcode
 	// The key store's ID.
 	//
 	// in: path
@@ -220,26 +1229,32 @@ type signReq struct { //nolint:unused,deadcode
 
 	// in: body
 	Body struct {
-		// A base64-encoded message to sign.
-		Message string `json:"message"`
+		// JWT in compact serialization.
+		JWT string `json:"jwt"`
+
+		// Expected audience. Not checked if empty.
+		Audience string `json:"audience,omitempty"`
+
+		// Expected issuer. Not checked if empty.
+		Issuer string `json:"issuer,omitempty"`
 	}
 }
 
-// signResp model
+// verifyJWTResp model
 //
-// swagger:response signResp
-type signResp struct { //nolint:unused,deadcode
+// swagger:response verifyJWTResp
+type verifyJWTResp struct { //nolint:unused,deadcode
 	// in: body
 	Body struct {
-		// A base64-encoded signature.
-		Signature string `json:"signature"`
+		// Claims of the valid JWT.
+		Claims map[string]interface{} `json:"claims"`
 	}
 }
 
-// verifyReq model
+// signLDProofReq model
 //
-// swagger:parameters verifyReq
-type verifyReq struct { //nolint:unused,deadcode
+// swagger:parameters signLDProofReq
+type signLDProofReq struct { //nolint:unused,deadcode
 	// The key store's ID.
 	//
 	// in: path
@@ -254,18 +1269,42 @@ type verifyReq struct { //nolint:unused,deadcode
 
 	// in: body
 	Body struct {
-		// A base64-encoded signature.
-		Signature string `json:"signature"`
+		// Unsigned verifiable credential or presentation. Its context must define terms of the signature suite.
+		// required: true
+		Document interface{} `json:"document"`
 
-		// A base64-encoded message.
-		Message string `json:"message"`
+		// Signature type: Ed25519Signature2018, Ed25519Signature2020, JsonWebSignature2020 or BbsBlsSignature2020.
+		// required: true
+		SignatureType string `json:"signature_type"`
+
+		// Verification method of the proof, e.g. a DID URL of the key.
+		// required: true
+		VerificationMethod string `json:"verification_method"`
+
+		// Proof purpose. Defaults to assertionMethod for credentials and authentication for presentations.
+		ProofPurpose string `json:"proof_purpose,omitempty"`
+
+		// Proof creation time. Defaults to the current time.
+		Created *time.Time `json:"created,omitempty"`
+
+		// Domain of the proof.
+		Domain string `json:"domain,omitempty"`
+
+		// Challenge of the proof.
+		Challenge string `json:"challenge,omitempty"`
 	}
 }
 
-// verifyResp model
+// signLDProofResp model
 //
-// swagger:response verifyResp
-type verifyResp struct{} //nolint:unused,deadcode
+// swagger:response signLDProofResp
+type signLDProofResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Verifiable credential or presentation with the proof.
+		Document interface{} `json:"document"`
+	}
+}
 
 // encryptReq model
 //
@@ -569,6 +1608,48 @@ type verifyProofReq struct { //nolint:unused,deadcode
 // swagger:response verifyProofResp
 type verifyProofResp struct{} //nolint:unused,deadcode
 
+// batchReq model
+//
+// swagger:parameters batchReq
+type batchReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The header with a user (subject) to use for fetching secret share from Auth server.
+	//
+	// Auth-User header
+	AuthUser string `json:"Auth-User"`
+
+	// The header with a secret share for Shamir secret lock.
+	//
+	// Secret-Share header
+	SecretShare string `json:"Secret-Share"`
+
+	// in: body
+	Body struct {
+		// Operations to execute.
+		// required: true
+		Operations []batchOperation `json:"operations"`
+
+		// Execute operations concurrently.
+		Parallel bool `json:"parallel"`
+	}
+}
+
+// batchResp model
+//
+// swagger:response batchResp
+type batchResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Results in the order of operations in the request.
+		Results []batchResult `json:"results"`
+	}
+}
+
 // easyReq model
 //
 // swagger:parameters easyReq
@@ -758,9 +1839,11 @@ type wrapKeyReq struct { //nolint:unused,deadcode
 		// required: true
 		APV string `json:"apv"`
 
-		// Recipient public key.
-		// required: true
+		// Recipient public key. Not required if recipient_key_id is set.
 		RecipientPubKey publicKey `json:"recipient_pub_key"`
+
+		// ID of an imported public key in the key store to use as recipient public key.
+		RecipientKeyID string `json:"recipient_key_id,omitempty"`
 	}
 }
 
@@ -857,6 +1940,155 @@ type unwrapKeyResp struct { //nolint:unused,deadcode
 	}
 }
 
+// encryptJWEReq model
+//
+// swagger:parameters encryptJWEReq
+type encryptJWEReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// in: body
+	Body struct {
+		// A base64-encoded plaintext.
+		// required: true
+		Plaintext string `json:"plaintext"`
+
+		// A base64-encoded additional authenticated data. Not supported with compact serialization.
+		AAD string `json:"aad,omitempty"`
+
+		// Recipient public keys.
+		Recipients []publicKey `json:"recipients,omitempty"`
+
+		// IDs of imported public keys in the key store to use as recipient public keys.
+		RecipientKeyIDs []string `json:"recipient_key_ids,omitempty"`
+
+		// Content encryption algorithm: "A256GCM" (default) or "XC20P".
+		ContentEncryption string `json:"content_encryption,omitempty"`
+
+		// Content type ("cty") header of the JWE.
+		ContentType string `json:"content_type,omitempty"`
+
+		// JWE serialization: "compact" (default, one recipient only) or "json".
+		Serialization string `json:"serialization,omitempty"`
+	}
+}
+
+// encryptJWEAEReq model
+//
+// swagger:parameters encryptJWEAEReq
+type encryptJWEAEReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The sender key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
+	// in: body
+	Body struct {
+		// A base64-encoded plaintext.
+		// required: true
+		Plaintext string `json:"plaintext"`
+
+		// A base64-encoded additional authenticated data. Not supported with compact serialization.
+		AAD string `json:"aad,omitempty"`
+
+		// Recipient public keys.
+		Recipients []publicKey `json:"recipients,omitempty"`
+
+		// IDs of imported public keys in the key store to use as recipient public keys.
+		RecipientKeyIDs []string `json:"recipient_key_ids,omitempty"`
+
+		// Content encryption algorithm: "A256GCM" (default) or "XC20P".
+		ContentEncryption string `json:"content_encryption,omitempty"`
+
+		// Content type ("cty") header of the JWE.
+		ContentType string `json:"content_type,omitempty"`
+
+		// JWE serialization: "compact" (default, one recipient only) or "json".
+		Serialization string `json:"serialization,omitempty"`
+	}
+}
+
+// encryptJWEResp model
+//
+// swagger:response encryptJWEResp
+type encryptJWEResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// JWE as a compact serialization string or a JSON serialization object.
+		JWE interface{} `json:"jwe"`
+	}
+}
+
+// decryptJWEReq model
+//
+// swagger:parameters decryptJWEReq
+type decryptJWEReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+
+	// The key's ID.
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"key_id"`
+
+	// in: body
+	Body struct {
+		// JWE as a compact serialization string or a JSON serialization object.
+		// required: true
+		JWE interface{} `json:"jwe"`
+
+		// Sender's public key. Required to decrypt Authcrypt JWE.
+		SenderPubKey *publicKey `json:"sender_pub_key,omitempty"`
+	}
+}
+
+// decryptJWEResp model
+//
+// swagger:response decryptJWEResp
+type decryptJWEResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// A base64-encoded plaintext.
+		Plaintext string `json:"plaintext"`
+	}
+}
+
+// jwksReq model
+//
+// swagger:parameters jwksReq
+type jwksReq struct { //nolint:unused,deadcode
+	// The key store's ID.
+	//
+	// in: path
+	// required: true
+	KeyStoreID string `json:"key_store_id"`
+}
+
+// jwksResp model
+//
+// swagger:response jwksResp
+type jwksResp struct { //nolint:unused,deadcode
+	// in: body
+	Body struct {
+		// Published public keys as JWKs with kid, alg and use set.
+		Keys []map[string]interface{} `json:"keys"`
+	}
+}
+
 // healthCheckReq model
 //
 // swagger:parameters healthCheckRequest
@@ -882,3 +2114,36 @@ type errorResp struct { //nolint:unused,deadcode
 	// in: body
 	Body ErrorResponse
 }
+
+type keyStoreBackup struct { //nolint:unused
+	// A base64-encoded versioned backup contents.
+	// required: true
+	Payload string `json:"payload"`
+
+	// did:key of the backup signing key of the server the payload is signed with.
+	// required: true
+	Signer string `json:"signer"`
+
+	// A base64-encoded signature on the payload.
+	// required: true
+	Signature string `json:"signature"`
+}
+
+type batchOperation struct { //nolint:unused
+	// Operation: sign, verify, encrypt, decrypt, computeMAC or signMulti.
+	Operation string `json:"operation"`
+
+	// The key's ID or alias (prefixed with "alias:").
+	KeyID string `json:"key_id"`
+
+	// Request of the operation, e.g. {"message": "..."} for sign.
+	Request interface{} `json:"request"`
+}
+
+type batchResult struct { //nolint:unused
+	// Response of the operation, e.g. {"signature": "..."} for sign. Empty for verify.
+	Result interface{} `json:"result,omitempty"`
+
+	// Error message if the operation failed.
+	Error string `json:"error,omitempty"`
+}
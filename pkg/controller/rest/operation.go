@@ -8,11 +8,13 @@ package rest
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -20,39 +22,77 @@ import (
 
 	"github.com/trustbloc/kms/pkg/controller/command"
 	"github.com/trustbloc/kms/pkg/controller/errors"
+	"github.com/trustbloc/kms/pkg/controller/mw/authmw"
 )
 
 // API endpoints.
 const (
-	KeyStoreVarName = "keystore"
-	keyVarName      = "key"
-	BaseV1Path      = "/v1"
-	KeyStorePath    = BaseV1Path + "/keystores"
-	DIDPath         = KeyStorePath + "/did"
-	KeyPath         = KeyStorePath + "/{" + KeyStoreVarName + "}/keys"
-	ExportKeyPath   = KeyPath + "/{" + keyVarName + "}/export"
-	RotateKeyPath   = KeyPath + "/{" + keyVarName + "}/rotate"
-	SignPath        = KeyPath + "/{" + keyVarName + "}/sign"
-	VerifyPath      = KeyPath + "/{" + keyVarName + "}/verify"
-	EncryptPath     = KeyPath + "/{" + keyVarName + "}/encrypt"
-	DecryptPath     = KeyPath + "/{" + keyVarName + "}/decrypt"
-	ComputeMACPath  = KeyPath + "/{" + keyVarName + "}/computemac"
-	VerifyMACPath   = KeyPath + "/{" + keyVarName + "}/verifymac"
-	SignMultiPath   = KeyPath + "/{" + keyVarName + "}/signmulti"
-	VerifyMultiPath = KeyPath + "/{" + keyVarName + "}/verifymulti"
-	DeriveProofPath = KeyPath + "/{" + keyVarName + "}/deriveproof"
-	VerifyProofPath = KeyPath + "/{" + keyVarName + "}/verifyproof"
-	WrapKeyPath     = KeyStorePath + "/{" + KeyStoreVarName + "}/wrap"
-	WrapKeyAEPath   = KeyPath + "/{" + keyVarName + "}/wrap"
-	UnwrapKeyPath   = KeyPath + "/{" + keyVarName + "}/unwrap"
-	HealthCheckPath = "/healthcheck"
+	KeyStoreVarName        = "keystore"
+	keyVarName             = "key"
+	aliasVarName           = "alias"
+	BaseV1Path             = "/v1"
+	KeyStorePath           = BaseV1Path + "/keystores"
+	KeyStoreIDPath         = KeyStorePath + "/{" + KeyStoreVarName + "}"
+	DeactivateKeyStorePath = KeyStoreIDPath + "/deactivate"
+	ActivateKeyStorePath   = KeyStoreIDPath + "/activate"
+	BackupKeyStorePath     = KeyStoreIDPath + "/backup"
+	RestoreKeyStorePath    = KeyStorePath + "/restore"
+	MigrateKeyStorePath    = KeyStoreIDPath + "/migrate"
+	EDVCapabilityPath      = KeyStoreIDPath + "/capability"
+	RootCapabilityPath     = KeyStoreIDPath + "/capability/root"
+	JWKSPath               = KeyStoreIDPath + "/jwks.json"
+	AliasesPath            = KeyStoreIDPath + "/aliases"
+	AliasPath              = AliasesPath + "/{" + aliasVarName + "}"
+	DIDPath                = KeyStorePath + "/did"
+	KeyPath                = KeyStorePath + "/{" + KeyStoreVarName + "}/keys"
+	KeyIDPath              = KeyPath + "/{" + keyVarName + "}"
+	ExportKeyPath          = KeyPath + "/{" + keyVarName + "}/export"
+	ExportPrivateKeyPath   = KeyPath + "/{" + keyVarName + "}/exportprivate"
+	RotateKeyPath          = KeyPath + "/{" + keyVarName + "}/rotate"
+	KeyVersionsPath        = KeyPath + "/{" + keyVarName + "}/versions"
+	SignPath               = KeyPath + "/{" + keyVarName + "}/sign"
+	VerifyPath             = KeyPath + "/{" + keyVarName + "}/verify"
+	SignJWSPath            = KeyPath + "/{" + keyVarName + "}/jws"
+	VerifyJWSPath          = KeyPath + "/{" + keyVarName + "}/jws/verify"
+	SignJWTPath            = KeyPath + "/{" + keyVarName + "}/jwt"
+	VerifyJWTPath          = KeyPath + "/{" + keyVarName + "}/jwt/verify"
+	SignLDProofPath        = KeyPath + "/{" + keyVarName + "}/ldproof"
+	EncryptPath            = KeyPath + "/{" + keyVarName + "}/encrypt"
+	DecryptPath            = KeyPath + "/{" + keyVarName + "}/decrypt"
+	ComputeMACPath         = KeyPath + "/{" + keyVarName + "}/computemac"
+	VerifyMACPath          = KeyPath + "/{" + keyVarName + "}/verifymac"
+	SignMultiPath          = KeyPath + "/{" + keyVarName + "}/signmulti"
+	VerifyMultiPath        = KeyPath + "/{" + keyVarName + "}/verifymulti"
+	DeriveProofPath        = KeyPath + "/{" + keyVarName + "}/deriveproof"
+	VerifyProofPath        = KeyPath + "/{" + keyVarName + "}/verifyproof"
+	BatchPath              = KeyStoreIDPath + "/batch"
+	WrapKeyPath            = KeyStorePath + "/{" + KeyStoreVarName + "}/wrap"
+	WrapKeyAEPath          = KeyPath + "/{" + keyVarName + "}/wrap"
+	UnwrapKeyPath          = KeyPath + "/{" + keyVarName + "}/unwrap"
+	EncryptJWEPath         = KeyStoreIDPath + "/jwe/encrypt"
+	EncryptJWEAEPath       = KeyPath + "/{" + keyVarName + "}/jwe/encrypt"
+	DecryptJWEPath         = KeyPath + "/{" + keyVarName + "}/jwe/decrypt"
+	CancelDeletionPath     = KeyPath + "/{" + keyVarName + "}/canceldeletion"
+	EnableKeyPath          = KeyPath + "/{" + keyVarName + "}/enable"
+	DisableKeyPath         = KeyPath + "/{" + keyVarName + "}/disable"
+	HealthCheckPath        = "/healthcheck"
 )
 
 const (
-	contentType       = "Content-Type"
-	applicationJSON   = "application/json"
-	authUserHeader    = "Auth-User"
-	secretShareHeader = "Secret-Share"
+	contentType                 = "Content-Type"
+	applicationJSON             = "application/json"
+	applicationJWKSetJSON       = "application/jwk-set+json"
+	cacheControlHeader          = "Cache-Control"
+	etagHeader                  = "ETag"
+	ifNoneMatchHeader           = "If-None-Match"
+	jwksMaxAge                  = command.JWKSMaxAge
+	authUserHeader              = "Auth-User"
+	secretShareHeader           = "Secret-Share"
+	formatQueryParam            = "format"
+	limitQueryParam             = "limit"
+	offsetQueryParam            = "offset"
+	pendingWindowDaysQueryParam = "pending_window_days"
+	tagQueryParam               = "tag"
 )
 
 var logger = log.New("controller/rest")
@@ -61,12 +101,39 @@ var logger = log.New("controller/rest")
 type Cmd interface {
 	CreateDID(w io.Writer, r io.Reader) error
 	CreateKeyStore(w io.Writer, r io.Reader) error
+	GetKeyStore(w io.Writer, r io.Reader) error
+	UpdateKeyStore(w io.Writer, r io.Reader) error
+	DeleteKeyStore(w io.Writer, r io.Reader) error
+	DeactivateKeyStore(w io.Writer, r io.Reader) error
+	ActivateKeyStore(w io.Writer, r io.Reader) error
+	BackupKeyStore(w io.Writer, r io.Reader) error
+	RestoreKeyStore(w io.Writer, r io.Reader) error
+	MigrateKeyStore(w io.Writer, r io.Reader) error
+	UpdateEDVCapability(w io.Writer, r io.Reader) error
+	ReissueRootCapability(w io.Writer, r io.Reader) error
 	CreateKey(w io.Writer, r io.Reader) error
+	ListKeys(w io.Writer, r io.Reader) error
 	ExportKey(w io.Writer, r io.Reader) error
+	ExportPrivateKey(w io.Writer, r io.Reader) error
 	RotateKey(w io.Writer, r io.Reader) error
+	UpdateKey(w io.Writer, r io.Reader) error
+	ListKeyVersions(w io.Writer, r io.Reader) error
+	EnableKey(w io.Writer, r io.Reader) error
+	DisableKey(w io.Writer, r io.Reader) error
+	SetAlias(w io.Writer, r io.Reader) error
+	GetAlias(w io.Writer, r io.Reader) error
+	ListAliases(w io.Writer, r io.Reader) error
+	DeleteAlias(w io.Writer, r io.Reader) error
+	DeleteKey(w io.Writer, r io.Reader) error
+	CancelKeyDeletion(w io.Writer, r io.Reader) error
 	ImportKey(w io.Writer, r io.Reader) error
 	Sign(w io.Writer, r io.Reader) error
 	Verify(w io.Writer, r io.Reader) error
+	SignJWS(w io.Writer, r io.Reader) error
+	VerifyJWS(w io.Writer, r io.Reader) error
+	SignJWT(w io.Writer, r io.Reader) error
+	VerifyJWT(w io.Writer, r io.Reader) error
+	SignLDProof(w io.Writer, r io.Reader) error
 	Encrypt(w io.Writer, r io.Reader) error
 	Decrypt(w io.Writer, r io.Reader) error
 	ComputeMAC(w io.Writer, r io.Reader) error
@@ -75,8 +142,12 @@ type Cmd interface {
 	VerifyMulti(w io.Writer, r io.Reader) error
 	DeriveProof(w io.Writer, r io.Reader) error
 	VerifyProof(w io.Writer, r io.Reader) error
+	Batch(w io.Writer, r io.Reader) error
 	WrapKey(w io.Writer, r io.Reader) error
 	UnwrapKey(w io.Writer, r io.Reader) error
+	EncryptJWE(w io.Writer, r io.Reader) error
+	DecryptJWE(w io.Writer, r io.Reader) error
+	JWKS(w io.Writer, r io.Reader) error
 }
 
 // Operation represents REST API controller.
@@ -94,12 +165,39 @@ func (o *Operation) GetRESTHandlers() []Handler {
 	return []Handler{
 		NewHTTPHandler(DIDPath, http.MethodPost, o.CreateDID, command.ActionCreateDID, AuthOAuth2),
 		NewHTTPHandler(KeyStorePath, http.MethodPost, o.CreateKeyStore, command.ActionCreateKeyStore, AuthOAuth2|AuthGNAP), //nolint:lll
+		NewHTTPHandler(RestoreKeyStorePath, http.MethodPost, o.RestoreKeyStore, command.ActionRestoreKeyStore, AuthAdmin),
+		NewHTTPHandler(KeyStoreIDPath, http.MethodGet, o.GetKeyStore, command.ActionGetKeyStore, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(KeyStoreIDPath, http.MethodPatch, o.UpdateKeyStore, command.ActionUpdateKeyStore, AuthZCAP|AuthGNAP),                //nolint:lll
+		NewHTTPHandler(KeyStoreIDPath, http.MethodDelete, o.DeleteKeyStore, command.ActionDeleteKeyStore, AuthZCAP|AuthGNAP),               //nolint:lll
+		NewHTTPHandler(DeactivateKeyStorePath, http.MethodPost, o.DeactivateKeyStore, command.ActionDeactivateKeyStore, AuthZCAP|AuthGNAP), //nolint:lll
+		NewHTTPHandler(ActivateKeyStorePath, http.MethodPost, o.ActivateKeyStore, command.ActionActivateKeyStore, AuthZCAP|AuthGNAP),       //nolint:lll
+		NewHTTPHandler(BackupKeyStorePath, http.MethodGet, o.BackupKeyStore, command.ActionBackupKeyStore, AuthZCAP|AuthGNAP),              //nolint:lll
+		NewHTTPHandler(MigrateKeyStorePath, http.MethodPost, o.MigrateKeyStore, command.ActionMigrateKeyStore, AuthZCAP|AuthGNAP),          //nolint:lll
+		NewHTTPHandler(EDVCapabilityPath, http.MethodPost, o.UpdateEDVCapability, command.ActionStoreCapability, AuthZCAP|AuthGNAP),        //nolint:lll
+		NewHTTPHandler(RootCapabilityPath, http.MethodPost, o.ReissueRootCapability, command.ActionStoreCapability, AuthZCAP|AuthGNAP),     //nolint:lll
 		NewHTTPHandler(KeyPath, http.MethodPost, o.CreateKey, command.ActionCreateKey, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(KeyPath, http.MethodPut, o.ImportKey, command.ActionImportKey, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(KeyPath, http.MethodGet, o.ListKeys, command.ActionListKeys, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(ExportKeyPath, http.MethodGet, o.ExportKey, command.ActionExportKey, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(ExportPrivateKeyPath, http.MethodPost, o.ExportPrivateKey, command.ActionExportPrivateKey, AuthZCAP|AuthGNAP), //nolint:lll
 		NewHTTPHandler(RotateKeyPath, http.MethodPost, o.RotateKey, command.ActionRotateKey, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(KeyIDPath, http.MethodPatch, o.UpdateKey, command.ActionUpdateKey, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(KeyVersionsPath, http.MethodGet, o.ListKeyVersions, command.ActionListKeyVersions, AuthZCAP|AuthGNAP), //nolint:lll
+		NewHTTPHandler(KeyIDPath, http.MethodDelete, o.DeleteKey, command.ActionDeleteKey, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(CancelDeletionPath, http.MethodPost, o.CancelKeyDeletion, command.ActionDeleteKey, AuthZCAP|AuthGNAP), //nolint:lll
+		NewHTTPHandler(EnableKeyPath, http.MethodPost, o.EnableKey, command.ActionEnableKey, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(DisableKeyPath, http.MethodPost, o.DisableKey, command.ActionDisableKey, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(AliasPath, http.MethodPut, o.SetAlias, command.ActionSetAlias, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(AliasPath, http.MethodGet, o.GetAlias, command.ActionGetAlias, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(AliasesPath, http.MethodGet, o.ListAliases, command.ActionListAliases, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(AliasPath, http.MethodDelete, o.DeleteAlias, command.ActionDeleteAlias, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(SignPath, http.MethodPost, o.Sign, command.ActionSign, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(VerifyPath, http.MethodPost, o.Verify, command.ActionVerify, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(SignJWSPath, http.MethodPost, o.SignJWS, command.ActionSignJWS, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(VerifyJWSPath, http.MethodPost, o.VerifyJWS, command.ActionVerifyJWS, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(SignJWTPath, http.MethodPost, o.SignJWT, command.ActionSignJWT, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(VerifyJWTPath, http.MethodPost, o.VerifyJWT, command.ActionVerifyJWT, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(SignLDProofPath, http.MethodPost, o.SignLDProof, command.ActionSignLDProof, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(EncryptPath, http.MethodPost, o.Encrypt, command.ActionEncrypt, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(DecryptPath, http.MethodPost, o.Decrypt, command.ActionDecrypt, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(ComputeMACPath, http.MethodPost, o.ComputeMAC, command.ActionComputeMac, AuthZCAP|AuthGNAP),
@@ -108,9 +206,14 @@ func (o *Operation) GetRESTHandlers() []Handler {
 		NewHTTPHandler(VerifyMultiPath, http.MethodPost, o.VerifyMulti, command.ActionVerifyMulti, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(DeriveProofPath, http.MethodPost, o.DeriveProof, command.ActionDeriveProof, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(VerifyProofPath, http.MethodPost, o.VerifyProof, command.ActionVerifyProof, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(BatchPath, http.MethodPost, o.Batch, command.ActionBatch, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(WrapKeyPath, http.MethodPost, o.WrapKey, command.ActionWrap, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(WrapKeyAEPath, http.MethodPost, o.WrapKeyAE, command.ActionWrap, AuthZCAP|AuthGNAP),
 		NewHTTPHandler(UnwrapKeyPath, http.MethodPost, o.UnwrapKey, command.ActionUnwrap, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(EncryptJWEPath, http.MethodPost, o.EncryptJWE, command.ActionEncryptJWE, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(EncryptJWEAEPath, http.MethodPost, o.EncryptJWEAE, command.ActionEncryptJWE, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(DecryptJWEPath, http.MethodPost, o.DecryptJWE, command.ActionDecryptJWE, AuthZCAP|AuthGNAP),
+		NewHTTPHandler(JWKSPath, http.MethodGet, o.JWKS, "", AuthNone),
 		NewHTTPHandler(HealthCheckPath, http.MethodGet, o.HealthCheck, "", AuthNone),
 	}
 }
@@ -137,6 +240,118 @@ func (o *Operation) CreateKeyStore(rw http.ResponseWriter, req *http.Request) {
 	execute(o.cmd.CreateKeyStore, rw, req)
 }
 
+// GetKeyStore swagger:route GET /v1/keystores/{key_store_id} kms getKeyStoreReq
+//
+// Gets metadata of the key store.
+//
+// Responses:
+//        200: getKeyStoreResp
+//    default: errorResp
+func (o *Operation) GetKeyStore(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.GetKeyStore, rw, req)
+}
+
+// UpdateKeyStore swagger:route PATCH /v1/keystores/{key_store_id} kms updateKeyStoreReq
+//
+// Updates controller of the key store and revokes capabilities of the previous controller. EDV vault URL can't be
+// changed, use key store migration instead.
+//
+// Responses:
+//        200: updateKeyStoreResp
+//    default: errorResp
+func (o *Operation) UpdateKeyStore(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.UpdateKeyStore, rw, req)
+}
+
+// DeleteKeyStore swagger:route DELETE /v1/keystores/{key_store_id} kms deleteKeyStoreReq
+//
+// Deletes the key store with all its keys and revokes the root capability.
+//
+// Responses:
+//        200: deleteKeyStoreResp
+//    default: errorResp
+func (o *Operation) DeleteKeyStore(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.DeleteKeyStore, rw, req)
+}
+
+// DeactivateKeyStore swagger:route POST /v1/keystores/{key_store_id}/deactivate kms deactivateKeyStoreReq
+//
+// Deactivates the key store. Keys of the deactivated key store can't be used for crypto operations.
+//
+// Responses:
+//        200: deactivateKeyStoreResp
+//    default: errorResp
+func (o *Operation) DeactivateKeyStore(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.DeactivateKeyStore, rw, req)
+}
+
+// ActivateKeyStore swagger:route POST /v1/keystores/{key_store_id}/activate kms activateKeyStoreReq
+//
+// Activates previously deactivated key store.
+//
+// Responses:
+//        200: activateKeyStoreResp
+//    default: errorResp
+func (o *Operation) ActivateKeyStore(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.ActivateKeyStore, rw, req)
+}
+
+// UpdateEDVCapability swagger:route POST /v1/keystores/{key_store_id}/capability kms updateEDVCapabilityReq
+//
+// Updates the capability used by the key store for accessing EDV vault.
+//
+// Responses:
+//        200: updateEDVCapabilityResp
+//    default: errorResp
+func (o *Operation) UpdateEDVCapability(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.UpdateEDVCapability, rw, req)
+}
+
+// ReissueRootCapability swagger:route POST /v1/keystores/{key_store_id}/capability/root kms reissueRootCapabilityReq
+//
+// Reissues the root capability of the key store with all actions supported by the server.
+//
+// Responses:
+//        200: reissueRootCapabilityResp
+//    default: errorResp
+func (o *Operation) ReissueRootCapability(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.ReissueRootCapability, rw, req)
+}
+
+// BackupKeyStore swagger:route GET /v1/keystores/{key_store_id}/backup kms backupKeyStoreReq
+//
+// Creates a signed archive of the key store with its keys, aliases and root ZCAP. Keysets stay encrypted as stored.
+//
+// Responses:
+//        200: backupKeyStoreResp
+//    default: errorResp
+func (o *Operation) BackupKeyStore(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.BackupKeyStore, rw, req)
+}
+
+// RestoreKeyStore swagger:route POST /v1/keystores/restore kms restoreKeyStoreReq
+//
+// Restores the key store from the archive created by backup. Requires the admin token of the server in Admin-Token
+// header. Archive must be signed by one of trusted backup signers of the server.
+//
+// Responses:
+//        200: restoreKeyStoreResp
+//    default: errorResp
+func (o *Operation) RestoreKeyStore(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.RestoreKeyStore, rw, req)
+}
+
+// MigrateKeyStore swagger:route POST /v1/keystores/{key_store_id}/migrate kms migrateKeyStoreReq
+//
+// Moves keys of the key store between server's storage and EDV. Old copies are deleted after the keys are verified.
+//
+// Responses:
+//        200: migrateKeyStoreResp
+//    default: errorResp
+func (o *Operation) MigrateKeyStore(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.MigrateKeyStore, rw, req)
+}
+
 // CreateKey swagger:route POST /v1/keystores/{key_store_id}/keys kms createKeyReq
 //
 // Creates a new key.
@@ -159,17 +374,79 @@ func (o *Operation) ImportKey(rw http.ResponseWriter, req *http.Request) {
 	execute(o.cmd.ImportKey, rw, req)
 }
 
-// ExportKey swagger:route GET /v1/keystores/{key_store_id}/keys/{key_id} kms exportKeyReq
+// ListKeys swagger:route GET /v1/keystores/{key_store_id}/keys kms listKeysReq
 //
-// Exports a public key.
+// Lists keys in the key store.
+//
+// Responses:
+//        200: listKeysResp
+//    default: errorResp
+func (o *Operation) ListKeys(rw http.ResponseWriter, req *http.Request) {
+	var (
+		r   command.ListKeysRequest
+		err error
+	)
+
+	query := req.URL.Query()
+
+	if v := query.Get(limitQueryParam); v != "" {
+		if r.Limit, err = strconv.Atoi(v); err != nil {
+			sendError(rw, fmt.Errorf("%w: parse %s query param", errors.ErrBadRequest, limitQueryParam))
+
+			return
+		}
+	}
+
+	if v := query.Get(offsetQueryParam); v != "" {
+		if r.Offset, err = strconv.Atoi(v); err != nil {
+			sendError(rw, fmt.Errorf("%w: parse %s query param", errors.ErrBadRequest, offsetQueryParam))
+
+			return
+		}
+	}
+
+	r.Tag = query.Get(tagQueryParam)
+
+	if err = setRequestBody(req, r); err != nil {
+		sendError(rw, err)
+
+		return
+	}
+
+	execute(o.cmd.ListKeys, rw, req)
+}
+
+// ExportKey swagger:route GET /v1/keystores/{key_store_id}/keys/{key_id}/export kms exportKeyReq
+//
+// Exports a public key. Format query parameter selects the format of the exported key: raw (default), jwk, pem,
+// multibase or didkey.
 //
 // Responses:
 //        200: exportKeyResp
 //    default: errorResp
 func (o *Operation) ExportKey(rw http.ResponseWriter, req *http.Request) {
+	r := command.ExportKeyRequest{Format: req.URL.Query().Get(formatQueryParam)}
+
+	if err := setRequestBody(req, r); err != nil {
+		sendError(rw, err)
+
+		return
+	}
+
 	execute(o.cmd.ExportKey, rw, req)
 }
 
+// ExportPrivateKey swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/exportprivate kms exportPrivateKeyReq
+//
+// Exports the private keyset of an exportable key encrypted to the transport public key as ECDH-ES JWE.
+//
+// Responses:
+//        200: exportPrivateKeyResp
+//    default: errorResp
+func (o *Operation) ExportPrivateKey(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.ExportPrivateKey, rw, req)
+}
+
 // RotateKey swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/rotate kms rotateKeyReq
 //
 // Rotate the key.
@@ -181,6 +458,163 @@ func (o *Operation) RotateKey(rw http.ResponseWriter, req *http.Request) {
 	execute(o.cmd.RotateKey, rw, req)
 }
 
+// UpdateKey swagger:route PATCH /v1/keystores/{key_store_id}/keys/{key_id} kms updateKeyReq
+//
+// Updates label, description and tags of the key.
+//
+// Responses:
+//        200: updateKeyResp
+//    default: errorResp
+func (o *Operation) UpdateKey(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.UpdateKey, rw, req)
+}
+
+// ListKeyVersions swagger:route GET /v1/keystores/{key_store_id}/keys/{key_id}/versions kms listKeyVersionsReq
+//
+// Lists versions of the key. Rotation creates a new primary version, previous versions are used for verification.
+//
+// Responses:
+//        200: listKeyVersionsResp
+//    default: errorResp
+func (o *Operation) ListKeyVersions(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.ListKeyVersions, rw, req)
+}
+
+// DeleteKey swagger:route DELETE /v1/keystores/{key_store_id}/keys/{key_id} kms deleteKeyReq
+//
+// Deletes the key. If pending window is set, the key is disabled and destroyed after the window ends.
+//
+// Responses:
+//        200: deleteKeyResp
+//    default: errorResp
+func (o *Operation) DeleteKey(rw http.ResponseWriter, req *http.Request) {
+	var r command.DeleteKeyRequest
+
+	if v := req.URL.Query().Get(pendingWindowDaysQueryParam); v != "" {
+		var err error
+
+		if r.PendingWindowDays, err = strconv.Atoi(v); err != nil {
+			sendError(rw, fmt.Errorf("%w: parse %s query param", errors.ErrBadRequest, pendingWindowDaysQueryParam))
+
+			return
+		}
+	}
+
+	if err := setRequestBody(req, r); err != nil {
+		sendError(rw, err)
+
+		return
+	}
+
+	execute(o.cmd.DeleteKey, rw, req)
+}
+
+// CancelKeyDeletion swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/canceldeletion kms cancelDeletionReq
+//
+// Cancels scheduled deletion of the key.
+//
+// Responses:
+//        200: cancelDeletionResp
+//    default: errorResp
+func (o *Operation) CancelKeyDeletion(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.CancelKeyDeletion, rw, req)
+}
+
+// EnableKey swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/enable kms enableKeyReq
+//
+// Enables previously disabled key.
+//
+// Responses:
+//        200: enableKeyResp
+//    default: errorResp
+func (o *Operation) EnableKey(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.EnableKey, rw, req)
+}
+
+// DisableKey swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/disable kms disableKeyReq
+//
+// Disables the key. Disabled key can't be used for crypto operations until it is enabled again.
+//
+// Responses:
+//        200: disableKeyResp
+//    default: errorResp
+func (o *Operation) DisableKey(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.DisableKey, rw, req)
+}
+
+// SetAlias swagger:route PUT /v1/keystores/{key_store_id}/aliases/{alias} kms setAliasReq
+//
+// Creates an alias for the key or points existing alias to another key. The alias can be used instead of key ID
+// in the form "alias:{alias}". Aliases of the rotated key are pointed to the new key.
+//
+// Responses:
+//        200: aliasResp
+//    default: errorResp
+func (o *Operation) SetAlias(rw http.ResponseWriter, req *http.Request) {
+	var r command.SetAliasRequest
+
+	if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+		sendError(rw, fmt.Errorf("%w: decode request body", errors.ErrBadRequest))
+
+		return
+	}
+
+	r.Name = mux.Vars(req)[aliasVarName]
+
+	if err := setRequestBody(req, r); err != nil {
+		sendError(rw, err)
+
+		return
+	}
+
+	execute(o.cmd.SetAlias, rw, req)
+}
+
+// GetAlias swagger:route GET /v1/keystores/{key_store_id}/aliases/{alias} kms getAliasReq
+//
+// Gets the key the alias points to.
+//
+// Responses:
+//        200: aliasResp
+//    default: errorResp
+func (o *Operation) GetAlias(rw http.ResponseWriter, req *http.Request) {
+	if err := setRequestBody(req, command.AliasRequest{Name: mux.Vars(req)[aliasVarName]}); err != nil {
+		sendError(rw, err)
+
+		return
+	}
+
+	execute(o.cmd.GetAlias, rw, req)
+}
+
+// ListAliases swagger:route GET /v1/keystores/{key_store_id}/aliases kms listAliasesReq
+//
+// Lists aliases in the key store.
+//
+// Responses:
+//        200: listAliasesResp
+//    default: errorResp
+func (o *Operation) ListAliases(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.ListAliases, rw, req)
+}
+
+// DeleteAlias swagger:route DELETE /v1/keystores/{key_store_id}/aliases/{alias} kms deleteAliasReq
+//
+// Deletes the alias. The key the alias points to is not affected.
+//
+// Responses:
+//        200: deleteAliasResp
+//    default: errorResp
+func (o *Operation) DeleteAlias(rw http.ResponseWriter, req *http.Request) {
+	if err := setRequestBody(req, command.AliasRequest{Name: mux.Vars(req)[aliasVarName]}); err != nil {
+		sendError(rw, err)
+
+		return
+	}
+
+	execute(o.cmd.DeleteAlias, rw, req)
+}
+
 // Sign swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/sign crypto signReq
 //
 // Signs a message.
@@ -203,6 +637,61 @@ func (o *Operation) Verify(rw http.ResponseWriter, req *http.Request) {
 	execute(o.cmd.Verify, rw, req)
 }
 
+// SignJWS swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/jws crypto signJWSReq
+//
+// Creates a JWS of the payload in compact or general JSON serialization.
+//
+// Responses:
+//        200: signJWSResp
+//    default: errorResp
+func (o *Operation) SignJWS(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.SignJWS, rw, req)
+}
+
+// VerifyJWS swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/jws/verify crypto verifyJWSReq
+//
+// Verifies a JWS and returns its payload and protected headers.
+//
+// Responses:
+//        200: verifyJWSResp
+//    default: errorResp
+func (o *Operation) VerifyJWS(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.VerifyJWS, rw, req)
+}
+
+// SignJWT swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/jwt crypto signJWTReq
+//
+// Issues a JWT with the claim set. Issued at, expiry and JWT ID claims are set by the server.
+//
+// Responses:
+//        200: signJWTResp
+//    default: errorResp
+func (o *Operation) SignJWT(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.SignJWT, rw, req)
+}
+
+// VerifyJWT swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/jwt/verify crypto verifyJWTReq
+//
+// Validates a JWT signature, expiry and, if requested, audience and issuer. Returns claims of the valid JWT.
+//
+// Responses:
+//        200: verifyJWTResp
+//    default: errorResp
+func (o *Operation) VerifyJWT(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.VerifyJWT, rw, req)
+}
+
+// SignLDProof swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/ldproof crypto signLDProofReq
+//
+// Adds a linked data proof to a verifiable credential or presentation.
+//
+// Responses:
+//        200: signLDProofResp
+//    default: errorResp
+func (o *Operation) SignLDProof(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.SignLDProof, rw, req)
+}
+
 // Encrypt swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/encrypt crypto encryptReq
 //
 // Encrypts a message with associated authenticated data.
@@ -300,6 +789,18 @@ func (o *Operation) VerifyProof(rw http.ResponseWriter, req *http.Request) {
 	execute(o.cmd.VerifyProof, rw, req)
 }
 
+// Batch swagger:route POST /v1/keystores/{key_store_id}/batch crypto batchReq
+//
+// Executes crypto operations (sign, verify, encrypt, decrypt, computeMAC, signMulti) with keys of the key store.
+// Results and errors are returned per operation.
+//
+// Responses:
+//        200: batchResp
+//    default: errorResp
+func (o *Operation) Batch(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.Batch, rw, req)
+}
+
 // WrapKey swagger:route POST /v1/keystores/{key_store_id}/wrap crypto wrapKeyReq
 //
 // Wraps CEK using ECDH-ES key wrapping (Anoncrypt).
@@ -333,6 +834,84 @@ func (o *Operation) UnwrapKey(rw http.ResponseWriter, req *http.Request) {
 	execute(o.cmd.UnwrapKey, rw, req)
 }
 
+// EncryptJWE swagger:route POST /v1/keystores/{key_store_id}/jwe/encrypt crypto encryptJWEReq
+//
+// Encrypts a JWE to the recipients using ECDH-ES key agreement (Anoncrypt).
+//
+// Responses:
+//        200: encryptJWEResp
+//    default: errorResp
+func (o *Operation) EncryptJWE(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.EncryptJWE, rw, req)
+}
+
+// EncryptJWEAE swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/jwe/encrypt crypto encryptJWEAEReq
+//
+// Encrypts a JWE to the recipients using ECDH-1PU key agreement with the key as the sender (Authcrypt).
+//
+// Responses:
+//        200: encryptJWEResp
+//    default: errorResp
+func (o *Operation) EncryptJWEAE(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.EncryptJWE, rw, req)
+}
+
+// DecryptJWE swagger:route POST /v1/keystores/{key_store_id}/keys/{key_id}/jwe/decrypt crypto decryptJWEReq
+//
+// Decrypts a JWE with the key as the recipient.
+//
+// Responses:
+//        200: decryptJWEResp
+//    default: errorResp
+func (o *Operation) DecryptJWE(rw http.ResponseWriter, req *http.Request) {
+	execute(o.cmd.DecryptJWE, rw, req)
+}
+
+// JWKS swagger:route GET /v1/keystores/{key_store_id}/jwks.json kms jwksReq
+//
+// Returns a JWK Set of public keys published in the key store. No authorization is required. The response can be
+// cached, 304 Not Modified is returned if If-None-Match header matches ETag of the JWK Set. Previous versions of
+// rotated keys stay in the set for an hour longer than the response can be cached.
+//
+// Responses:
+//        200: jwksResp
+//    default: errorResp
+func (o *Operation) JWKS(rw http.ResponseWriter, req *http.Request) {
+	r, err := wrapRequest(req)
+	if err != nil {
+		rw.Header().Set(contentType, applicationJSON)
+		sendError(rw, fmt.Errorf("wrap request: %w", err))
+
+		return
+	}
+
+	var buf bytes.Buffer
+
+	if err = o.cmd.JWKS(&buf, bytes.NewBuffer(r)); err != nil {
+		rw.Header().Set(contentType, applicationJSON)
+		sendError(rw, fmt.Errorf("%s %s: %w", req.Method, req.RequestURI, err))
+
+		return
+	}
+
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(buf.Bytes()))
+
+	rw.Header().Set(cacheControlHeader, fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+	rw.Header().Set(etagHeader, etag)
+
+	if req.Header.Get(ifNoneMatchHeader) == etag {
+		rw.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	rw.Header().Set(contentType, applicationJWKSetJSON)
+
+	if _, err = rw.Write(buf.Bytes()); err != nil {
+		logger.Errorf("failed to write jwks response: %v", err)
+	}
+}
+
 // HealthCheck swagger:route GET /healthcheck server healthCheckReq
 //
 // Returns a health check status.
@@ -388,13 +967,35 @@ func wrapRequest(req *http.Request) ([]byte, error) {
 
 	vars := mux.Vars(req)
 
-	return json.Marshal(&command.WrappedRequest{
+	wr := &command.WrappedRequest{
 		KeyStoreID:  vars[KeyStoreVarName],
 		KeyID:       vars[keyVarName],
 		User:        req.Header.Get(authUserHeader),
 		SecretShare: secret,
 		Request:     buf.Bytes(),
-	})
+	}
+
+	if capability := authmw.CapabilityFromContext(req.Context()); capability != nil {
+		wr.Capability = &command.InvokedCapability{
+			Delegated:      capability.Parent != "",
+			AllowedActions: capability.AllowedAction,
+		}
+	}
+
+	return json.Marshal(wr)
+}
+
+// setRequestBody replaces the body of the request with JSON-encoded v. It is used for passing query parameters
+// of GET requests to commands.
+func setRequestBody(req *http.Request, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%w: marshal request body", errors.ErrInternal)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+
+	return nil
 }
 
 // ErrorResponse is an error response model.
@@ -24,8 +24,11 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/edge-core/pkg/zcapld"
 
 	"github.com/trustbloc/kms/pkg/controller/command"
+	controllererrors "github.com/trustbloc/kms/pkg/controller/errors"
+	"github.com/trustbloc/kms/pkg/controller/mw/authmw"
 	. "github.com/trustbloc/kms/pkg/controller/rest"
 )
 
@@ -83,6 +86,188 @@ func TestOperation_CreateKeyStore(t *testing.T) {
 	require.Equal(t, http.StatusOK, handleRequest(t, op, KeyStorePath, http.MethodPost, bytes.NewBufferString(body)))
 }
 
+func TestOperation_GetKeyStore(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().GetKeyStore(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		require.NoError(t, unwrapRequest(r, nil))
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK,
+		handleRequest(t, op, KeyStoreIDPath, http.MethodGet, bytes.NewReader(nil)))
+}
+
+func TestOperation_UpdateKeyStore(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().UpdateKeyStore(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.UpdateKeyStoreRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "did:example:new", req.Controller)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, KeyStoreIDPath, http.MethodPatch,
+		bytes.NewBufferString(`{"controller": "did:example:new"}`)))
+}
+
+func TestOperation_UpdateEDVCapability(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().UpdateEDVCapability(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.UpdateEDVCapabilityRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, []byte("capability"), req.Capability)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	body := fmt.Sprintf(`{"capability": "%s"}`, base64.StdEncoding.EncodeToString([]byte("capability")))
+
+	require.Equal(t, http.StatusOK,
+		handleRequest(t, op, EDVCapabilityPath, http.MethodPost, bytes.NewBufferString(body)))
+}
+
+func TestOperation_ReissueRootCapability(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := NewMockCmd(gomock.NewController(t))
+
+		cmd.EXPECT().ReissueRootCapability(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			var wr command.WrappedRequest
+			require.NoError(t, json.NewDecoder(r).Decode(&wr))
+
+			require.Nil(t, wr.Capability)
+		}).Return(nil).Times(1)
+
+		op := New(cmd)
+
+		require.Equal(t, http.StatusOK,
+			handleRequest(t, op, RootCapabilityPath, http.MethodPost, bytes.NewReader(nil)))
+	})
+
+	t.Run("Passes invoked capability to command", func(t *testing.T) {
+		cmd := NewMockCmd(gomock.NewController(t))
+
+		cmd.EXPECT().ReissueRootCapability(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			var wr command.WrappedRequest
+			require.NoError(t, json.NewDecoder(r).Decode(&wr))
+
+			require.Equal(t, &command.InvokedCapability{
+				Delegated:      true,
+				AllowedActions: []string{command.ActionStoreCapability},
+			}, wr.Capability)
+		}).Return(nil).Times(1)
+
+		op := New(cmd)
+
+		handler := handlerLookup(t, op, RootCapabilityPath, http.MethodPost)
+
+		ctx := authmw.WithCapability(context.Background(), &zcapld.Capability{
+			ID:            "urn:zcap:delegated",
+			Parent:        "urn:zcap:root",
+			AllowedAction: []string{command.ActionStoreCapability},
+		})
+
+		req, err := http.NewRequestWithContext(ctx, handler.Method(), handler.Path(), bytes.NewReader(nil))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		handler.Handler()(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestOperation_DeleteKeyStore(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().DeleteKeyStore(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		require.NoError(t, unwrapRequest(r, nil))
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK,
+		handleRequest(t, op, KeyStoreIDPath, http.MethodDelete, bytes.NewReader(nil)))
+}
+
+func TestOperation_DeactivateKeyStore(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().DeactivateKeyStore(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		require.NoError(t, unwrapRequest(r, nil))
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK,
+		handleRequest(t, op, DeactivateKeyStorePath, http.MethodPost, bytes.NewReader(nil)))
+}
+
+func TestOperation_ActivateKeyStore(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().ActivateKeyStore(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		require.NoError(t, unwrapRequest(r, nil))
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK,
+		handleRequest(t, op, ActivateKeyStorePath, http.MethodPost, bytes.NewReader(nil)))
+}
+
+func TestOperation_BackupKeyStore(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().BackupKeyStore(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		require.NoError(t, unwrapRequest(r, nil))
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK,
+		handleRequest(t, op, BackupKeyStorePath, http.MethodGet, bytes.NewReader(nil)))
+}
+
+func TestOperation_RestoreKeyStore(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().RestoreKeyStore(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.RestoreKeyStoreRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "did:key:signer", req.Backup.Signer)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, RestoreKeyStorePath, http.MethodPost,
+		bytes.NewBufferString(`{"backup": {"payload": "e30=", "signer": "did:key:signer", "signature": "c2ln"}}`)))
+}
+
+func TestOperation_MigrateKeyStore(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().MigrateKeyStore(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.MigrateKeyStoreRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "https://edv.example.com/encrypted-data-vaults/vault", req.EDV.VaultURL)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, MigrateKeyStorePath, http.MethodPost,
+		bytes.NewBufferString(`{"edv": {"vault_url": "https://edv.example.com/encrypted-data-vaults/vault"}}`)))
+}
+
 func TestOperation_CreateKey(t *testing.T) {
 	cmd := NewMockCmd(gomock.NewController(t))
 
@@ -123,16 +308,222 @@ func TestOperation_ImportKey(t *testing.T) {
 	require.Equal(t, http.StatusOK, handleRequest(t, op, KeyPath, http.MethodPut, bytes.NewBufferString(body)))
 }
 
+func TestOperation_ListKeys(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := NewMockCmd(gomock.NewController(t))
+
+		cmd.EXPECT().ListKeys(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			var req command.ListKeysRequest
+			require.NoError(t, unwrapRequest(r, &req))
+
+			require.Equal(t, 10, req.Limit)
+			require.Equal(t, 20, req.Offset)
+			require.Equal(t, "purpose=vc-issuance", req.Tag)
+		}).Return(nil).Times(1)
+
+		op := New(cmd)
+
+		require.Equal(t, http.StatusOK, handleRequestWithQuery(t, op, KeyPath, http.MethodGet,
+			"limit=10&offset=20&tag=purpose%3Dvc-issuance", bytes.NewReader(nil)))
+	})
+
+	t.Run("Fail to parse limit", func(t *testing.T) {
+		op := New(NewMockCmd(gomock.NewController(t)))
+
+		require.Equal(t, http.StatusBadRequest,
+			handleRequestWithQuery(t, op, KeyPath, http.MethodGet, "limit=invalid", bytes.NewReader(nil)))
+	})
+
+	t.Run("Fail to parse offset", func(t *testing.T) {
+		op := New(NewMockCmd(gomock.NewController(t)))
+
+		require.Equal(t, http.StatusBadRequest,
+			handleRequestWithQuery(t, op, KeyPath, http.MethodGet, "offset=invalid", bytes.NewReader(nil)))
+	})
+}
+
 func TestOperation_ExportKey(t *testing.T) {
 	cmd := NewMockCmd(gomock.NewController(t))
 
 	cmd.EXPECT().ExportKey(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.ExportKeyRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, command.FormatJWK, req.Format)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK,
+		handleRequestWithQuery(t, op, ExportKeyPath, http.MethodGet, "format=jwk", bytes.NewReader(nil)))
+}
+
+func TestOperation_ExportPrivateKey(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().ExportPrivateKey(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.ExportPrivateKeyRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "transport-kid", req.TransportKey.KID)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, ExportPrivateKeyPath, http.MethodPost,
+		bytes.NewBufferString(`{"transport_key": {"kid": "transport-kid", "type": "OKP", "curve": "X25519"}}`)))
+}
+
+func TestOperation_UpdateKey(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().UpdateKey(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.UpdateKeyRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "label", *req.Label)
+		require.Equal(t, map[string]string{"env": "prod"}, req.Tags)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, KeyIDPath, http.MethodPatch,
+		bytes.NewBufferString(`{"label": "label", "tags": {"env": "prod"}}`)))
+}
+
+func TestOperation_ListKeyVersions(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().ListKeyVersions(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, KeyVersionsPath, http.MethodGet, bytes.NewReader(nil)))
+}
+
+func TestOperation_DeleteKey(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := NewMockCmd(gomock.NewController(t))
+
+		cmd.EXPECT().DeleteKey(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			var req command.DeleteKeyRequest
+			require.NoError(t, unwrapRequest(r, &req))
+
+			require.Equal(t, 7, req.PendingWindowDays)
+		}).Return(nil).Times(1)
+
+		op := New(cmd)
+
+		require.Equal(t, http.StatusOK,
+			handleRequestWithQuery(t, op, KeyIDPath, http.MethodDelete, "pending_window_days=7", bytes.NewReader(nil)))
+	})
+
+	t.Run("Fail to parse pending window days", func(t *testing.T) {
+		op := New(NewMockCmd(gomock.NewController(t)))
+
+		require.Equal(t, http.StatusBadRequest,
+			handleRequestWithQuery(t, op, KeyIDPath, http.MethodDelete, "pending_window_days=invalid",
+				bytes.NewReader(nil)))
+	})
+}
+
+func TestOperation_CancelKeyDeletion(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().CancelKeyDeletion(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
 		require.NoError(t, unwrapRequest(r, nil))
 	}).Return(nil).Times(1)
 
 	op := New(cmd)
 
-	require.Equal(t, http.StatusOK, handleRequest(t, op, ExportKeyPath, http.MethodGet, bytes.NewReader(nil)))
+	require.Equal(t, http.StatusOK,
+		handleRequest(t, op, CancelDeletionPath, http.MethodPost, bytes.NewReader(nil)))
+}
+
+func TestOperation_SetAlias(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cmd := NewMockCmd(gomock.NewController(t))
+
+		cmd.EXPECT().SetAlias(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+			var req command.SetAliasRequest
+			require.NoError(t, unwrapRequest(r, &req))
+
+			require.Equal(t, "{alias}", req.Name)
+			require.Equal(t, "key_id", req.KeyID)
+		}).Return(nil).Times(1)
+
+		op := New(cmd)
+
+		require.Equal(t, http.StatusOK,
+			handleRequest(t, op, AliasPath, http.MethodPut, bytes.NewBufferString(`{"key_id": "key_id"}`)))
+	})
+
+	t.Run("Fail to decode request body", func(t *testing.T) {
+		op := New(NewMockCmd(gomock.NewController(t)))
+
+		require.Equal(t, http.StatusBadRequest,
+			handleRequest(t, op, AliasPath, http.MethodPut, bytes.NewBufferString("invalid")))
+	})
+}
+
+func TestOperation_GetAlias(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().GetAlias(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.AliasRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "{alias}", req.Name)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, AliasPath, http.MethodGet, bytes.NewReader(nil)))
+}
+
+func TestOperation_ListAliases(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().ListAliases(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, AliasesPath, http.MethodGet, bytes.NewReader(nil)))
+}
+
+func TestOperation_DeleteAlias(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().DeleteAlias(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.AliasRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "{alias}", req.Name)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, AliasPath, http.MethodDelete, bytes.NewReader(nil)))
+}
+
+func TestOperation_EnableKey(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().EnableKey(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, EnableKeyPath, http.MethodPost, bytes.NewReader(nil)))
+}
+
+func TestOperation_DisableKey(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().DisableKey(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, DisableKeyPath, http.MethodPost, bytes.NewReader(nil)))
 }
 
 func TestOperation_Sign(t *testing.T) {
@@ -176,6 +567,119 @@ func TestOperation_Verify(t *testing.T) {
 	require.Equal(t, http.StatusOK, handleRequest(t, op, VerifyPath, http.MethodPost, bytes.NewBufferString(body)))
 }
 
+func TestOperation_SignJWS(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().SignJWS(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.SignJWSRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, []byte("test payload"), req.Payload)
+		require.Equal(t, "JWT", req.Headers["typ"])
+		require.Equal(t, command.JWSJSON, req.Serialization)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	body := fmt.Sprintf(`{
+		"payload": "%s",
+		"headers": {"typ": "JWT"},
+		"serialization": "json"
+	}`, base64.StdEncoding.EncodeToString([]byte("test payload")))
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, SignJWSPath, http.MethodPost, bytes.NewBufferString(body)))
+}
+
+func TestOperation_VerifyJWS(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().VerifyJWS(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.VerifyJWSRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.JSONEq(t, `"header.payload.signature"`, string(req.JWS))
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	body := `{"jws": "header.payload.signature"}`
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, VerifyJWSPath, http.MethodPost, bytes.NewBufferString(body)))
+}
+
+func TestOperation_SignJWT(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().SignJWT(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.SignJWTRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "did:example:issuer", req.Claims["iss"])
+		require.Equal(t, int64(600), req.ExpiresIn)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	body := `{"claims": {"iss": "did:example:issuer"}, "expires_in": 600}`
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, SignJWTPath, http.MethodPost, bytes.NewBufferString(body)))
+}
+
+func TestOperation_VerifyJWT(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().VerifyJWT(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.VerifyJWTRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, "header.payload.signature", req.JWT)
+		require.Equal(t, "https://api.example.com", req.Audience)
+		require.Equal(t, "did:example:issuer", req.Issuer)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	body := `{
+		"jwt": "header.payload.signature",
+		"audience": "https://api.example.com",
+		"issuer": "did:example:issuer"
+	}`
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, VerifyJWTPath, http.MethodPost, bytes.NewBufferString(body)))
+}
+
+func TestOperation_SignLDProof(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().SignLDProof(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.SignLDProofRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.JSONEq(t, `{"type":"VerifiableCredential"}`, string(req.Document))
+		require.Equal(t, "Ed25519Signature2018", req.SignatureType)
+		require.Equal(t, "did:example:issuer#key1", req.VerificationMethod)
+		require.Equal(t, "assertionMethod", req.ProofPurpose)
+		require.Equal(t, "example.com", req.Domain)
+		require.Equal(t, "challenge", req.Challenge)
+		require.NotNil(t, req.Created)
+		require.Equal(t, "2022-01-02T03:04:05Z", req.Created.UTC().Format("2006-01-02T15:04:05Z"))
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	body := `{
+		"document": {"type": "VerifiableCredential"},
+		"signature_type": "Ed25519Signature2018",
+		"verification_method": "did:example:issuer#key1",
+		"proof_purpose": "assertionMethod",
+		"created": "2022-01-02T03:04:05Z",
+		"domain": "example.com",
+		"challenge": "challenge"
+	}`
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, SignLDProofPath, http.MethodPost, bytes.NewBufferString(body)))
+}
+
 func TestOperation_Encrypt(t *testing.T) {
 	cmd := NewMockCmd(gomock.NewController(t))
 
@@ -361,6 +865,24 @@ func TestOperation_VerifyProof(t *testing.T) {
 	require.Equal(t, http.StatusOK, handleRequest(t, op, VerifyProofPath, http.MethodPost, bytes.NewBufferString(body)))
 }
 
+func TestOperation_Batch(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().Batch(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.BatchRequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.True(t, req.Parallel)
+		require.Len(t, req.Operations, 1)
+		require.Equal(t, command.ActionSign, req.Operations[0].Operation)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, BatchPath, http.MethodPost, bytes.NewBufferString(
+		`{"operations": [{"operation": "sign", "key_id": "key_id", "request": {"message": "dGVzdA=="}}], "parallel": true}`)))
+}
+
 func TestOperation_Easy(t *testing.T) {
 	cmd := NewMockCmd(gomock.NewController(t))
 
@@ -589,6 +1111,151 @@ func TestOperation_UnwrapKey(t *testing.T) {
 	require.Equal(t, http.StatusOK, handleRequest(t, op, UnwrapKeyPath, http.MethodPost, bytes.NewBufferString(body)))
 }
 
+func TestOperation_EncryptJWE(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().EncryptJWE(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.EncryptJWERequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.Equal(t, []byte("test message"), req.Plaintext)
+		require.Equal(t, []byte("aad"), req.AAD)
+		require.Equal(t, []string{"recipient key id"}, req.RecipientKeyIDs)
+		require.Equal(t, "XC20P", req.ContentEncryption)
+		require.Equal(t, command.JWEJSON, req.Serialization)
+
+		require.Len(t, req.Recipients, 1)
+		require.Equal(t, "key id", req.Recipients[0].KID)
+		require.Equal(t, []byte("x"), req.Recipients[0].X)
+		require.Equal(t, []byte("y"), req.Recipients[0].Y)
+		require.Equal(t, "curve", req.Recipients[0].Curve)
+		require.Equal(t, "type", req.Recipients[0].Type)
+	}).Return(nil).Times(2)
+
+	op := New(cmd)
+
+	body := fmt.Sprintf(`{
+		"plaintext": "%s",
+		"aad": "%s",
+		"recipients": [{
+			"kid": "key id",
+			"x": "%s",
+			"y": "%s",
+			"curve": "curve",
+			"type": "type"
+		}],
+		"recipient_key_ids": ["recipient key id"],
+		"content_encryption": "XC20P",
+		"serialization": "json"
+	}`, base64.StdEncoding.EncodeToString([]byte("test message")),
+		base64.StdEncoding.EncodeToString([]byte("aad")),
+		base64.StdEncoding.EncodeToString([]byte("x")),
+		base64.StdEncoding.EncodeToString([]byte("y")))
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, EncryptJWEPath, http.MethodPost, bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusOK,
+		handleRequest(t, op, EncryptJWEAEPath, http.MethodPost, bytes.NewBufferString(body)))
+}
+
+func TestOperation_DecryptJWE(t *testing.T) {
+	cmd := NewMockCmd(gomock.NewController(t))
+
+	cmd.EXPECT().DecryptJWE(gomock.Any(), gomock.Any()).Do(func(_ io.Writer, r io.Reader) {
+		var req command.DecryptJWERequest
+		require.NoError(t, unwrapRequest(r, &req))
+
+		require.JSONEq(t, `"header.key.iv.ciphertext.tag"`, string(req.JWE))
+
+		require.NotNil(t, req.SenderPubKey)
+		require.Equal(t, "key id", req.SenderPubKey.KID)
+		require.Equal(t, []byte("x"), req.SenderPubKey.X)
+		require.Equal(t, []byte("y"), req.SenderPubKey.Y)
+		require.Equal(t, "curve", req.SenderPubKey.Curve)
+		require.Equal(t, "type", req.SenderPubKey.Type)
+	}).Return(nil).Times(1)
+
+	op := New(cmd)
+
+	body := fmt.Sprintf(`{
+		"jwe": "header.key.iv.ciphertext.tag",
+		"sender_pub_key": {
+			"kid": "key id",
+			"x": "%s",
+			"y": "%s",
+			"curve": "curve",
+			"type": "type"
+		}
+	}`, base64.StdEncoding.EncodeToString([]byte("x")),
+		base64.StdEncoding.EncodeToString([]byte("y")))
+
+	require.Equal(t, http.StatusOK, handleRequest(t, op, DecryptJWEPath, http.MethodPost, bytes.NewBufferString(body)))
+}
+
+func TestOperation_JWKS(t *testing.T) {
+	const jwks = `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"x","kid":"key_id"}]}`
+
+	serve := func(t *testing.T, op *Operation, etag string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		handler := handlerLookup(t, op, JWKSPath, http.MethodGet)
+		require.Equal(t, AuthNone, handler.Auth())
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+			"/v1/keystores/key_store_id/jwks.json", bytes.NewReader(nil))
+		require.NoError(t, err)
+
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		router := mux.NewRouter()
+		router.HandleFunc(handler.Path(), handler.Handler()).Methods(handler.Method())
+
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		cmd := NewMockCmd(gomock.NewController(t))
+
+		cmd.EXPECT().JWKS(gomock.Any(), gomock.Any()).DoAndReturn(func(w io.Writer, r io.Reader) error {
+			var wr command.WrappedRequest
+			require.NoError(t, json.NewDecoder(r).Decode(&wr))
+			require.Equal(t, "key_store_id", wr.KeyStoreID)
+
+			_, err := w.Write([]byte(jwks))
+
+			return err
+		}).Times(2)
+
+		op := New(cmd)
+
+		rr := serve(t, op, "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "application/jwk-set+json", rr.Header().Get("Content-Type"))
+		require.Equal(t, "public, max-age=300", rr.Header().Get("Cache-Control"))
+		require.NotEmpty(t, rr.Header().Get("ETag"))
+		require.Equal(t, jwks, rr.Body.String())
+
+		rr = serve(t, op, rr.Header().Get("ETag"))
+		require.Equal(t, http.StatusNotModified, rr.Code)
+		require.Empty(t, rr.Body.String())
+	})
+
+	t.Run("Fail to get JWK Set", func(t *testing.T) {
+		cmd := NewMockCmd(gomock.NewController(t))
+
+		cmd.EXPECT().JWKS(gomock.Any(), gomock.Any()).Return(fmt.Errorf("%w: key store not found", controllererrors.ErrNotFound))
+
+		rr := serve(t, New(cmd), "")
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Empty(t, rr.Header().Get("Cache-Control"))
+	})
+}
+
 func TestOperation_HealthCheck(t *testing.T) {
 	op := New(nil)
 
@@ -612,9 +1279,21 @@ func unwrapRequest(r io.Reader, req interface{}) error {
 func handleRequest(t *testing.T, op *Operation, path, method string, body io.Reader) int {
 	t.Helper()
 
+	return handleRequestWithQuery(t, op, path, method, "", body)
+}
+
+func handleRequestWithQuery(t *testing.T, op *Operation, path, method, query string, body io.Reader) int {
+	t.Helper()
+
 	handler := handlerLookup(t, op, path, method)
 
-	req, err := http.NewRequestWithContext(context.Background(), handler.Method(), handler.Path(), body)
+	target := handler.Path()
+
+	if query != "" {
+		target += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), handler.Method(), target, body)
 	require.NoError(t, err)
 
 	router := mux.NewRouter()
@@ -126,6 +126,12 @@ func (w *wrappedKMS) ImportPrivateKey(privateKey interface{}, kt arieskms.KeyTyp
 	return w.kms.ImportPrivateKey(privateKey, kt, opts...)
 }
 
+// Invalidate removes the cached key handle and public key bytes, e.g. when the key is deleted from the storage.
+func (w *wrappedKMS) Invalidate(keyID string) {
+	w.cache.Del(keyCacheItemID(keyID))
+	w.cache.Del(pubBytesCacheItemID(keyID))
+}
+
 func (w *wrappedKMS) addKeyHandleToCache(id string, kh interface{}) {
 	w.cache.SetWithTTL(keyCacheItemID(id), kh, cacheItemCost, w.ttl)
 }
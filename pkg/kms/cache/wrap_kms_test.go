@@ -302,3 +302,22 @@ func TestWrappedKMS_ImportKey(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestWrappedKMS_Invalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := NewMockCache(ctrl)
+	c.EXPECT().Del("kms_key_test_id").Times(1)
+	c.EXPECT().Del("kms_key_pub_bytes_test_id").Times(1)
+
+	cacheProvider := cache.Provider{Cache: c}
+
+	wk, err := cacheProvider.WrapKMS(NewMockKeyManager(ctrl), 10*time.Second)
+	require.NoError(t, err)
+
+	invalidator, ok := wk.(interface{ Invalidate(keyID string) })
+	require.True(t, ok)
+
+	invalidator.Invalidate("test_id")
+}
@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lease
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+)
+
+const leasesStoreName = "leases"
+
+// record is a lease saved in the underlying storage.
+type record struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Manager acquires named leases, so that only one of server instances sharing the storage runs a periodic job.
+type Manager struct {
+	store  storage.Store
+	holder string
+}
+
+// New returns a new instance of Manager. Holder identifies the server instance. Storage provider must not be wrapped
+// with cache, as leases are written by other instances.
+func New(provider storage.Provider, holder string) (*Manager, error) {
+	store, err := provider.OpenStore(leasesStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open leases db: %w", err)
+	}
+
+	return &Manager{
+		store:  store,
+		holder: holder,
+	}, nil
+}
+
+// Acquire acquires the lease or renews the lease already held by the instance. It returns false if the lease is held
+// by another instance and hasn't expired yet. Storage has no compare-and-swap, so the lease is read back after it is
+// written; of instances racing for an expired lease only the last writer gets it.
+func (m *Manager) Acquire(name string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	current, err := m.get(name)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return false, err
+	}
+
+	if current != nil && current.Holder != m.holder && current.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	b, err := json.Marshal(&record{Holder: m.holder, ExpiresAt: now.Add(ttl)})
+	if err != nil {
+		return false, fmt.Errorf("marshal lease: %w", err)
+	}
+
+	if err = m.store.Put(name, b); err != nil {
+		return false, fmt.Errorf("put lease: %w", err)
+	}
+
+	current, err = m.get(name)
+	if err != nil {
+		return false, err
+	}
+
+	return current.Holder == m.holder, nil
+}
+
+func (m *Manager) get(name string) (*record, error) {
+	b, err := m.store.Get(name)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("get lease: %w", err)
+	}
+
+	var r record
+
+	if err = json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("unmarshal lease: %w", err)
+	}
+
+	return &r, nil
+}
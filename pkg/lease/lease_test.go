@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lease_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/kms/pkg/lease"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		m, err := lease.New(mem.NewProvider(), "instance1")
+		require.NoError(t, err)
+		require.NotNil(t, m)
+	})
+
+	t.Run("Fail to open store", func(t *testing.T) {
+		m, err := lease.New(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")}, "instance1")
+		require.EqualError(t, err, "open leases db: open error")
+		require.Nil(t, m)
+	})
+}
+
+func TestManager_Acquire(t *testing.T) {
+	t.Run("Lease is held by one instance until it expires", func(t *testing.T) {
+		p := mem.NewProvider()
+
+		m1, err := lease.New(p, "instance1")
+		require.NoError(t, err)
+
+		m2, err := lease.New(p, "instance2")
+		require.NoError(t, err)
+
+		acquired, err := m1.Acquire("job", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = m2.Acquire("job", time.Hour)
+		require.NoError(t, err)
+		require.False(t, acquired)
+
+		acquired, err = m1.Acquire("job", time.Nanosecond)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		time.Sleep(time.Millisecond)
+
+		acquired, err = m2.Acquire("job", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = m1.Acquire("job", time.Hour)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+
+	t.Run("Leases are independent", func(t *testing.T) {
+		p := mem.NewProvider()
+
+		m1, err := lease.New(p, "instance1")
+		require.NoError(t, err)
+
+		m2, err := lease.New(p, "instance2")
+		require.NoError(t, err)
+
+		acquired, err := m1.Acquire("job1", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = m2.Acquire("job2", time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+
+	t.Run("Fail to get lease", func(t *testing.T) {
+		m, err := lease.New(&mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{
+			Store:  make(map[string]mockstorage.DBEntry),
+			ErrGet: errors.New("get error"),
+		}}, "instance1")
+		require.NoError(t, err)
+
+		acquired, err := m.Acquire("job", time.Hour)
+		require.EqualError(t, err, "get lease: get error")
+		require.False(t, acquired)
+	})
+
+	t.Run("Fail to put lease", func(t *testing.T) {
+		m, err := lease.New(&mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{
+			Store:  make(map[string]mockstorage.DBEntry),
+			ErrPut: errors.New("put error"),
+		}}, "instance1")
+		require.NoError(t, err)
+
+		acquired, err := m.Acquire("job", time.Hour)
+		require.EqualError(t, err, "put lease: put error")
+		require.False(t, acquired)
+	})
+
+	t.Run("Fail to unmarshal lease", func(t *testing.T) {
+		m, err := lease.New(&mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{
+			Store: map[string]mockstorage.DBEntry{"job": {Value: []byte("invalid")}},
+		}}, "instance1")
+		require.NoError(t, err)
+
+		acquired, err := m.Acquire("job", time.Hour)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal lease")
+		require.False(t, acquired)
+	})
+}
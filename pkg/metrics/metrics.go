@@ -39,6 +39,15 @@ const (
 	keySecretLockDecryptTimeMetric = "key_secret_lock_decrypt_seconds"
 	awsSecretLockEncryptTimeMetric = "aws_secret_lock_encrypt_seconds"
 	keySecretLockEncryptTimeMetric = "key_secret_lock_encrypt_seconds"
+	keyManagerCacheHitsMetric      = "key_manager_cache_hits_total"
+	keyManagerCacheMissesMetric    = "key_manager_cache_misses_total"
+
+	// Key rotation.
+	keyRotation               = "key_rotation"
+	keyRotationRotatedMetric  = "rotated_total"
+	keyRotationFailedMetric   = "failed_total"
+	keyRotationLastTimeMetric = "last_rotation_timestamp_seconds"
+	keyRotationNextTimeMetric = "next_rotation_timestamp_seconds"
 
 	// Middleware.
 	zcap                            = "zcap"
@@ -76,6 +85,14 @@ type Metrics struct {
 	awsSecretLockEncryptTime prometheus.Histogram
 	keySecretLockEncryptTime prometheus.Histogram
 
+	keyManagerCacheHits   prometheus.Counter
+	keyManagerCacheMisses prometheus.Counter
+
+	keysRotated         prometheus.Counter
+	keyRotationFailures prometheus.Counter
+	lastKeyRotationTime prometheus.Gauge
+	nextKeyRotationTime prometheus.Gauge
+
 	zcapldTime                  prometheus.Histogram
 	zcapldCapabilityResolveTime prometheus.Histogram
 	zcapldLoadDocumentTime      prometheus.Histogram
@@ -109,6 +126,12 @@ func newMetrics() *Metrics {
 		keySecretLockDecryptTime:    newKeySecretLockDecryptTime(),
 		awsSecretLockEncryptTime:    newAWSSecretLockEncryptTime(),
 		keySecretLockEncryptTime:    newKeySecretLockEncryptTime(),
+		keyManagerCacheHits:         newKeyManagerCacheHits(),
+		keyManagerCacheMisses:       newKeyManagerCacheMisses(),
+		keysRotated:                 newKeysRotated(),
+		keyRotationFailures:         newKeyRotationFailures(),
+		lastKeyRotationTime:         newLastKeyRotationTime(),
+		nextKeyRotationTime:         newNextKeyRotationTime(),
 		zcapldTime:                  newZCAPMiddlewareTime(),
 		zcapldCapabilityResolveTime: newZCAPCapabilityResolveTime(),
 		zcapldLoadDocumentTime:      newZCAPLoadDocumentTime(),
@@ -118,7 +141,8 @@ func newMetrics() *Metrics {
 	prometheus.MustRegister(
 		m.cryptoSignTime, m.keyStoreResolveTime, m.keyStoreGetKeyTime, m.awsSecretLockDecryptTime, m.keySecretLockDecryptTime,
 		m.awsSecretLockEncryptTime, m.keySecretLockEncryptTime, m.zcapldTime, m.zcapldCapabilityResolveTime,
-		m.zcapldLoadDocumentTime, m.zcapldVDRResolve,
+		m.zcapldLoadDocumentTime, m.zcapldVDRResolve, m.keysRotated, m.keyRotationFailures, m.lastKeyRotationTime,
+		m.nextKeyRotationTime, m.keyManagerCacheHits, m.keyManagerCacheMisses,
 	)
 
 	for _, c := range m.dbPutTimes {
@@ -250,6 +274,44 @@ func (m *Metrics) KeySecretLockEncryptTime(value time.Duration) {
 	logger.Debugf("KeySecretLockEncrypt time: %s", value)
 }
 
+// KeyManagerCacheHit records a user's key manager served from the cache.
+func (m *Metrics) KeyManagerCacheHit() {
+	m.keyManagerCacheHits.Inc()
+}
+
+// KeyManagerCacheMiss records a user's key manager created as it wasn't found in the cache.
+func (m *Metrics) KeyManagerCacheMiss() {
+	m.keyManagerCacheMisses.Inc()
+}
+
+// KeysRotated records the number of keys rotated by the rotation scheduler.
+func (m *Metrics) KeysRotated(count int) {
+	m.keysRotated.Add(float64(count))
+
+	logger.Debugf("Keys rotated: %d", count)
+}
+
+// KeyRotationFailures records the number of keys the rotation scheduler failed to rotate.
+func (m *Metrics) KeyRotationFailures(count int) {
+	m.keyRotationFailures.Add(float64(count))
+
+	logger.Debugf("Key rotation failures: %d", count)
+}
+
+// LastKeyRotationTime records the time of the last scheduled key rotation.
+func (m *Metrics) LastKeyRotationTime(value time.Time) {
+	m.lastKeyRotationTime.Set(float64(value.Unix()))
+
+	logger.Debugf("Last key rotation time: %s", value)
+}
+
+// NextKeyRotationTime records the time of the next scheduled key rotation.
+func (m *Metrics) NextKeyRotationTime(value time.Time) {
+	m.nextKeyRotationTime.Set(float64(value.Unix()))
+
+	logger.Debugf("Next key rotation time: %s", value)
+}
+
 // ZCAPLDTime records the time it takes to run zcapld middleware.
 func (m *Metrics) ZCAPLDTime(value time.Duration) {
 	m.zcapldTime.Observe(value.Seconds())
@@ -288,6 +350,26 @@ func newHistogram(subsystem, name, help string, labels prometheus.Labels) promet
 	})
 }
 
+func newCounter(subsystem, name, help string, labels prometheus.Labels) prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Subsystem:   subsystem,
+		Name:        name,
+		Help:        help,
+		ConstLabels: labels,
+	})
+}
+
+func newGauge(subsystem, name, help string, labels prometheus.Labels) prometheus.Gauge {
+	return prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Subsystem:   subsystem,
+		Name:        name,
+		Help:        help,
+		ConstLabels: labels,
+	})
+}
+
 func newCryptoSignTime() prometheus.Histogram {
 	return newHistogram(
 		crypto, cryptoSignTimeMetric,
@@ -442,6 +524,54 @@ func newKeySecretLockEncryptTime() prometheus.Histogram {
 	)
 }
 
+func newKeyManagerCacheHits() prometheus.Counter {
+	return newCounter(
+		keyStore, keyManagerCacheHitsMetric,
+		"The number of requests served with a cached key manager of the keystore.",
+		nil,
+	)
+}
+
+func newKeyManagerCacheMisses() prometheus.Counter {
+	return newCounter(
+		keyStore, keyManagerCacheMissesMetric,
+		"The number of requests that created a new key manager of the keystore.",
+		nil,
+	)
+}
+
+func newKeysRotated() prometheus.Counter {
+	return newCounter(
+		keyRotation, keyRotationRotatedMetric,
+		"The number of keys rotated by the rotation scheduler.",
+		nil,
+	)
+}
+
+func newKeyRotationFailures() prometheus.Counter {
+	return newCounter(
+		keyRotation, keyRotationFailedMetric,
+		"The number of keys the rotation scheduler failed to rotate.",
+		nil,
+	)
+}
+
+func newLastKeyRotationTime() prometheus.Gauge {
+	return newGauge(
+		keyRotation, keyRotationLastTimeMetric,
+		"The time (unix timestamp in seconds) of the last scheduled key rotation.",
+		nil,
+	)
+}
+
+func newNextKeyRotationTime() prometheus.Gauge {
+	return newGauge(
+		keyRotation, keyRotationNextTimeMetric,
+		"The time (unix timestamp in seconds) of the next scheduled key rotation.",
+		nil,
+	)
+}
+
 func newZCAPMiddlewareTime() prometheus.Histogram {
 	return newHistogram(
 		zcap, zcapMiddlewareTimeMetric,
@@ -35,6 +35,12 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.AWSSecretLockDecryptTime(time.Second) })
 		require.NotPanics(t, func() { m.KeySecretLockEncryptTime(time.Second) })
 		require.NotPanics(t, func() { m.KeySecretLockDecryptTime(time.Second) })
+		require.NotPanics(t, func() { m.KeyManagerCacheHit() })
+		require.NotPanics(t, func() { m.KeyManagerCacheMiss() })
+		require.NotPanics(t, func() { m.KeysRotated(1) })
+		require.NotPanics(t, func() { m.KeyRotationFailures(1) })
+		require.NotPanics(t, func() { m.LastKeyRotationTime(time.Now()) })
+		require.NotPanics(t, func() { m.NextKeyRotationTime(time.Now()) })
 		require.NotPanics(t, func() { m.ZCAPLDTime(time.Second) })
 		require.NotPanics(t, func() { m.ZCAPLDCapabilityResolveTime(time.Second) })
 		require.NotPanics(t, func() { m.ZCAPLDLoadDocumentTime(time.Second) })
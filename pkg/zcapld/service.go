@@ -30,6 +30,11 @@ import (
 
 const (
 	zcapsStoreName = "zcaps"
+	// rootTagName tags capabilities with the root capability of their delegation chain. Tag value is the
+	// base64url-encoded root ID, as capability IDs contain colons that can't be used in a query expression.
+	rootTagName = "root"
+	// maxDelegationDepth limits resolving of the delegation chain.
+	maxDelegationDepth = 16
 )
 
 // Service to provide zcapld functionality.
@@ -48,6 +53,12 @@ func New(keyManager kms.KeyManager, crypto cryptoapi.Crypto, sp storage.Provider
 		return nil, fmt.Errorf("failed to open store: %w", err)
 	}
 
+	// the store must be opened before its config is set
+	err = sp.SetStoreConfig(zcapsStoreName, storage.StoreConfiguration{TagNames: []string{rootTagName}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store config: %w", err)
+	}
+
 	return &Service{
 		keyManager:   keyManager,
 		crypto:       crypto,
@@ -120,14 +131,8 @@ func (s *Service) NewCapability(ctx context.Context, options ...zcapld.Capabilit
 		return nil, fmt.Errorf("failed to create zcap: %w", err)
 	}
 
-	raw, err := json.Marshal(zcap)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal zcap: %w", err)
-	}
-
-	err = s.store.Put(zcap.ID, raw)
-	if err != nil {
-		return nil, fmt.Errorf("failed to store zcap: %w", err)
+	if err = s.Save(zcap); err != nil {
+		return nil, err
 	}
 
 	return zcap, nil
@@ -148,6 +153,85 @@ func (s *Service) Resolve(uri string) (*zcapld.Capability, error) {
 	return capability, nil
 }
 
+// Save puts the capability in storage, e.g. when the capability is restored from a backup. Delegated capabilities
+// are tagged with the root of their chain, so they are revoked together with the root capability.
+func (s *Service) Save(capability *zcapld.Capability) error {
+	raw, err := json.Marshal(capability)
+	if err != nil {
+		return fmt.Errorf("failed to marshal zcap: %w", err)
+	}
+
+	err = s.store.Put(capability.ID, raw, storage.Tag{Name: rootTagName, Value: rootTagValue(s.rootID(capability))})
+	if err != nil {
+		return fmt.Errorf("failed to store zcap: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke removes the capability and all stored capabilities delegated from it.
+func (s *Service) Revoke(uri string) error {
+	iter, err := s.store.Query(fmt.Sprintf("%s:%s", rootTagName, rootTagValue(uri)))
+	if err != nil {
+		return fmt.Errorf("failed to query delegated zcaps: %w", err)
+	}
+
+	defer iter.Close() //nolint:errcheck // ignore
+
+	more, err := iter.Next()
+	if err != nil {
+		return fmt.Errorf("failed to get next zcap: %w", err)
+	}
+
+	for more {
+		id, keyErr := iter.Key()
+		if keyErr != nil {
+			return fmt.Errorf("failed to get zcap id: %w", keyErr)
+		}
+
+		if id != uri {
+			if err = s.store.Delete(id); err != nil {
+				return fmt.Errorf("failed to delete delegated zcap from storage: %w", err)
+			}
+		}
+
+		more, err = iter.Next()
+		if err != nil {
+			return fmt.Errorf("failed to get next zcap: %w", err)
+		}
+	}
+
+	err = s.store.Delete(uri)
+	if err != nil {
+		return fmt.Errorf("failed to delete zcap from storage: %w", err)
+	}
+
+	return nil
+}
+
+// rootID returns the ID of the root capability of the delegation chain. Parents are resolved from storage; the
+// topmost stored parent is used when the chain can't be resolved up to the root.
+func (s *Service) rootID(capability *zcapld.Capability) string {
+	rootID := capability.ID
+	parentID := capability.Parent
+
+	for depth := 0; parentID != "" && depth < maxDelegationDepth; depth++ {
+		parent, err := s.Resolve(parentID)
+		if err != nil {
+			return parentID
+		}
+
+		rootID = parent.ID
+		parentID = parent.Parent
+	}
+
+	return rootID
+}
+
+func rootTagValue(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
 // KMS returns the kms.KeyManager.
 func (s *Service) KMS() kms.KeyManager {
 	return s.keyManager
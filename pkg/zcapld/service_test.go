@@ -38,6 +38,20 @@ func TestNew(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to open store")
 	})
+
+	t.Run("error if cannot set store config", func(t *testing.T) {
+		_, err := zcapld.New(
+			&mockkms.KeyManager{},
+			&mockcrypto.Crypto{},
+			&mockstorage.MockStoreProvider{
+				Store:             &mockstorage.MockStore{Store: make(map[string]mockstorage.DBEntry)},
+				ErrSetStoreConfig: errors.New("test"),
+			},
+			createTestDocumentLoader(t),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to set store config")
+	})
 }
 
 func TestService_CreateDIDKey(t *testing.T) {
@@ -242,6 +256,126 @@ func TestService_Resolve(t *testing.T) {
 	})
 }
 
+func TestService_Save(t *testing.T) {
+	t.Run("puts zcap in store", func(t *testing.T) {
+		store := &mockstorage.MockStore{
+			Store: make(map[string]mockstorage.DBEntry),
+		}
+		svc, err := zcapld.New(
+			&mockkms.KeyManager{},
+			&mockcrypto.Crypto{},
+			&mockstorage.MockStoreProvider{Store: store},
+			createTestDocumentLoader(t),
+		)
+		require.NoError(t, err)
+
+		err = svc.Save(&zcapld2.Capability{ID: "uri"})
+		require.NoError(t, err)
+		require.Contains(t, store.Store, "uri")
+
+		resolved, err := svc.Resolve("uri")
+		require.NoError(t, err)
+		require.Equal(t, "uri", resolved.ID)
+	})
+
+	t.Run("error if cannot put zcap in store", func(t *testing.T) {
+		svc, err := zcapld.New(
+			&mockkms.KeyManager{},
+			&mockcrypto.Crypto{},
+			&mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{
+				Store:  make(map[string]mockstorage.DBEntry),
+				ErrPut: errors.New("put error"),
+			}},
+			createTestDocumentLoader(t),
+		)
+		require.NoError(t, err)
+
+		err = svc.Save(&zcapld2.Capability{ID: "uri"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to store zcap: put error")
+	})
+}
+
+func TestService_Revoke(t *testing.T) {
+	t.Run("removes zcap from store", func(t *testing.T) {
+		store := &mockstorage.MockStore{
+			Store: map[string]mockstorage.DBEntry{
+				"uri": {Value: []byte("{}")},
+			},
+		}
+		svc, err := zcapld.New(
+			&mockkms.KeyManager{},
+			&mockcrypto.Crypto{},
+			&mockstorage.MockStoreProvider{Store: store},
+			createTestDocumentLoader(t),
+		)
+		require.NoError(t, err)
+
+		err = svc.Revoke("uri")
+		require.NoError(t, err)
+		require.NotContains(t, store.Store, "uri")
+	})
+
+	t.Run("removes delegated zcaps from store", func(t *testing.T) {
+		store := &mockstorage.MockStore{
+			Store: make(map[string]mockstorage.DBEntry),
+		}
+		svc, err := zcapld.New(
+			&mockkms.KeyManager{},
+			&mockcrypto.Crypto{},
+			&mockstorage.MockStoreProvider{Store: store},
+			createTestDocumentLoader(t),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, svc.Save(&zcapld2.Capability{ID: "urn:root"}))
+		require.NoError(t, svc.Save(&zcapld2.Capability{ID: "urn:delegated", Parent: "urn:root"}))
+		require.NoError(t, svc.Save(&zcapld2.Capability{ID: "urn:delegated:2", Parent: "urn:delegated"}))
+		require.NoError(t, svc.Save(&zcapld2.Capability{ID: "urn:other"}))
+
+		err = svc.Revoke("urn:root")
+		require.NoError(t, err)
+		require.NotContains(t, store.Store, "urn:root")
+		require.NotContains(t, store.Store, "urn:delegated")
+		require.NotContains(t, store.Store, "urn:delegated:2")
+		require.Contains(t, store.Store, "urn:other")
+	})
+
+	t.Run("error if cannot query delegated zcaps", func(t *testing.T) {
+		svc, err := zcapld.New(
+			&mockkms.KeyManager{},
+			&mockcrypto.Crypto{},
+			&mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{
+				Store:    make(map[string]mockstorage.DBEntry),
+				ErrQuery: errors.New("query error"),
+			}},
+			createTestDocumentLoader(t),
+		)
+		require.NoError(t, err)
+
+		err = svc.Revoke("uri")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to query delegated zcaps: query error")
+	})
+
+	t.Run("error if cannot delete zcap from store", func(t *testing.T) {
+		svc, err := zcapld.New(
+			&mockkms.KeyManager{},
+			&mockcrypto.Crypto{},
+			&mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{
+				Store:     make(map[string]mockstorage.DBEntry),
+				ErrDelete: errors.New("delete error"),
+			}},
+			createTestDocumentLoader(t),
+		)
+		require.NoError(t, err)
+
+		err = svc.Revoke("uri")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to delete zcap from storage: delete error")
+	})
+}
+
 func createTestDocumentLoader(t *testing.T) *ld.DocumentLoader {
 	t.Helper()
 